@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Case 单个评测用例
+type Case struct {
+	Name           string `yaml:"name"`            // 用例名称，为空时使用问题
+	Question       string `yaml:"question"`        // 提问内容
+	ExpectedSource string `yaml:"expected_source"` // 期望命中的来源文件（文件名或完整路径）
+	ExpectedAnswer string `yaml:"expected_answer"` // 参考答案，用于评分
+}
+
+// Suite 评测用例集
+type Suite struct {
+	// Documents 评测前需要入库的文档，已入库且未变化的文档会被跳过
+	Documents []string `yaml:"documents"`
+	Cases     []Case   `yaml:"cases"`
+}
+
+// loadSuite 从 YAML 文件加载评测用例集
+func loadSuite(path string) (*Suite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var s Suite
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("解析用例文件失败: %w", err)
+	}
+	for i, c := range s.Cases {
+		if c.Question == "" {
+			return nil, fmt.Errorf("第 %d 个用例缺少 question", i+1)
+		}
+		if c.Name == "" {
+			s.Cases[i].Name = c.Question
+		}
+	}
+	return &s, nil
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"BackEnd/internal/logic/chatinternal"
+	"BackEnd/internal/logic/chatinternal/toolx"
+	"BackEnd/internal/svc"
+	"BackEnd/pkg/langchain"
+	"BackEnd/pkg/langchain/outputparserx"
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/tmc/langchaingo/chains"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// 评分提示词中的标记，假模型据此识别评分请求并截取参考答案与待评回答
+const (
+	gradeMarker         = "【知识库回答评分】"
+	gradeReferenceLabel = "[参考答案]"
+	gradeAnswerLabel    = "[待评回答]"
+	gradeEndLabel       = "[评分要求]"
+
+	maxGradeScore = 5 // 评分满分
+)
+
+const gradePromptTemplate = gradeMarker + `
+你是企业知识库问答的评审员，请对照参考答案判断待评回答是否正确、完整地回答了问题。
+[问题]
+%s
+` + gradeReferenceLabel + `
+%s
+` + gradeAnswerLabel + `
+%s
+` + gradeEndLabel + `
+按 0-5 分打分：5 分表示与参考答案含义完全一致，3 分表示部分正确，0 分表示错误或未作答。只评价内容，不评价措辞和引用格式。
+%s`
+
+var gradeParser = outputparserx.NewStructured([]outputparserx.ResponseSchema{
+	{Name: "score", Description: "0-5 的整数评分", Type: "int", Require: true},
+	{Name: "reason", Description: "简要评分理由", Type: "string"},
+})
+
+// CaseResult 单个用例的评测结果
+type CaseResult struct {
+	Name       string   `json:"name"`
+	Question   string   `json:"question"`
+	Retrieved  []string `json:"retrieved"`  // 检索到的来源文件，按排名排列
+	Rank       int      `json:"rank"`       // 期望来源在检索结果中的名次，0 表示未命中
+	Hit        bool     `json:"hit"`        // 期望来源是否出现在前 K 个检索结果中
+	Answer     string   `json:"answer"`     // 知识库回答
+	Cited      []string `json:"cited"`      // 大模型在回答中引用的来源文件
+	CitationOK bool     `json:"citationOk"` // 回答是否引用了期望来源
+	Score      float64  `json:"score"`      // 归一化到 0-1 的回答评分，-1 表示未评分
+	Reason     string   `json:"reason"`     // 评分理由
+	Error      string   `json:"error,omitempty"`
+}
+
+// Report 评测汇总报告
+type Report struct {
+	Total            int          `json:"total"`
+	HitRate          float64      `json:"hitRate"`          // 检索命中率 hit@K
+	MRR              float64      `json:"mrr"`              // 期望来源的平均倒数排名
+	CitationAccuracy float64      `json:"citationAccuracy"` // 引用正确率
+	AnswerScore      float64      `json:"answerScore"`      // 平均回答评分（0-1）
+	Errors           int          `json:"errors"`
+	Cases            []CaseResult `json:"cases"`
+}
+
+// evaluator 知识库问答评测器
+type evaluator struct {
+	searcher *toolx.KnowledgeSearcher
+	handler  chains.Chain
+	grader   llms.Model
+	k        int
+}
+
+func newEvaluator(svcCtx *svc.ServiceContext, k int) *evaluator {
+	return &evaluator{
+		searcher: toolx.NewKnowledgeSearcher(svcCtx),
+		handler:  chatinternal.NewKnowledge(svcCtx).Chains(),
+		grader:   svcCtx.LLMs,
+		k:        k,
+	}
+}
+
+// Run 依次执行所有用例并汇总指标
+func (e *evaluator) Run(ctx context.Context, cases []Case) *Report {
+	results := make([]CaseResult, 0, len(cases))
+	for _, c := range cases {
+		results = append(results, e.runCase(ctx, c))
+	}
+	return summarize(cases, results)
+}
+
+// summarize 汇总用例结果：命中率、MRR、引用正确率只统计指定了期望来源的用例，平均分只统计已评分的用例
+func summarize(cases []Case, results []CaseResult) *Report {
+	report := &Report{Total: len(cases), Cases: results}
+
+	var sourceCases, gradedCases int
+	for i, c := range cases {
+		res := results[i]
+		if res.Error != "" {
+			report.Errors++
+		}
+
+		if c.ExpectedSource != "" {
+			sourceCases++
+			if res.Hit {
+				report.HitRate++
+			}
+			if res.Rank > 0 {
+				report.MRR += 1 / float64(res.Rank)
+			}
+			if res.CitationOK {
+				report.CitationAccuracy++
+			}
+		}
+		if res.Score >= 0 {
+			gradedCases++
+			report.AnswerScore += res.Score
+		}
+	}
+
+	if sourceCases > 0 {
+		report.HitRate /= float64(sourceCases)
+		report.MRR /= float64(sourceCases)
+		report.CitationAccuracy /= float64(sourceCases)
+	}
+	if gradedCases > 0 {
+		report.AnswerScore /= float64(gradedCases)
+	}
+	return report
+}
+
+// runCase 执行单个用例：检索评测、调用知识库处理器作答、评分
+func (e *evaluator) runCase(ctx context.Context, c Case) CaseResult {
+	res := CaseResult{Name: c.Name, Question: c.Question, Score: -1}
+
+	// 1. 检索命中
+	hits, err := e.searcher.Search(ctx, c.Question, e.k)
+	if err != nil {
+		res.Error = fmt.Sprintf("检索失败: %v", err)
+		return res
+	}
+	sources := make([]string, 0, len(hits))
+	for _, h := range hits {
+		sources = append(sources, h.Source)
+	}
+	res.Retrieved = uniqueNames(sources)
+	res.Rank = sourceRank(sources, c.ExpectedSource)
+	res.Hit = res.Rank > 0
+
+	// 2. 通过知识库处理器作答，与线上对话链路一致
+	out, err := chains.Call(ctx, e.handler, map[string]any{
+		langchain.Input: c.Question,
+	})
+	if err != nil {
+		res.Error = fmt.Sprintf("回答失败: %v", err)
+		return res
+	}
+	res.Answer = fmt.Sprintf("%v", out[langchain.Output])
+
+	// 3. 引用正确性：大模型在回答最后写出的来源是否包括期望来源
+	res.Cited = toolx.ParseKnowledgeSources(res.Answer)
+	res.CitationOK = cites(res.Cited, c.ExpectedSource)
+
+	// 4. 大模型评分
+	if c.ExpectedAnswer != "" {
+		score, reason, err := e.grade(ctx, c, res.Answer)
+		if err != nil {
+			res.Error = fmt.Sprintf("评分失败: %v", err)
+			return res
+		}
+		res.Score, res.Reason = score, reason
+	}
+	return res
+}
+
+// grade 由大模型对照参考答案给回答打分，返回归一化到 0-1 的分数
+func (e *evaluator) grade(ctx context.Context, c Case, answer string) (float64, string, error) {
+	// 评分只看回答内容，去掉引用来源行
+	if i := strings.LastIndex(answer, toolx.KnowledgeSourcesPrefix); i >= 0 {
+		answer = answer[:i]
+	}
+
+	prompt := fmt.Sprintf(gradePromptTemplate, c.Question, c.ExpectedAnswer, strings.TrimSpace(answer),
+		gradeParser.GetFormatInstructions())
+	text, err := llms.GenerateFromSinglePrompt(ctx, e.grader, prompt, llms.WithTemperature(0))
+	if err != nil {
+		return 0, "", err
+	}
+
+	return parseGrade(text)
+}
+
+// parseGrade 解析评分结果，分数限制在 0-5 之间并归一化到 0-1
+func parseGrade(text string) (float64, string, error) {
+	parsed, err := gradeParser.Parse(text)
+	if err != nil {
+		return 0, "", err
+	}
+	data := parsed.(map[string]any)
+
+	var score float64
+	switch v := data["score"].(type) {
+	case float64:
+		score = v
+	case string:
+		fmt.Sscanf(v, "%g", &score)
+	}
+	if score < 0 {
+		score = 0
+	}
+	if score > maxGradeScore {
+		score = maxGradeScore
+	}
+	reason, _ := data["reason"].(string)
+	return score / maxGradeScore, reason, nil
+}
+
+// uniqueNames 去重后的来源文件名，保持检索结果的排名顺序
+func uniqueNames(sources []string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, s := range sources {
+		name := filepath.Base(s)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+// sourceRank 期望来源在检索结果中的名次，从 1 开始，未命中时返回 0
+func sourceRank(sources []string, expected string) int {
+	for i, s := range sources {
+		if sameSource(expected, s) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// cites 回答引用的来源中是否包括期望来源
+func cites(cited []string, expected string) bool {
+	for _, s := range cited {
+		if sameSource(expected, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameSource 判断来源是否一致，用例中可以只写文件名
+func sameSource(expected, actual string) bool {
+	if expected == "" || actual == "" {
+		return false
+	}
+	return expected == actual || filepath.Base(expected) == filepath.Base(actual)
+}
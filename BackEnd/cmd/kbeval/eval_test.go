@@ -0,0 +1,207 @@
+package main
+
+import (
+	"BackEnd/internal/logic/chatinternal/toolx"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Test_summarize 测试命中率、MRR、引用正确率和平均分的计算
+func Test_summarize(t *testing.T) {
+	cases := []Case{
+		{Name: "a", ExpectedSource: "handbook.pdf"},
+		{Name: "b", ExpectedSource: "leave.pdf"},
+		{Name: "c", ExpectedSource: "trip.pdf"},
+		{Name: "d"}, // 没有期望来源，不计入检索指标
+	}
+	results := []CaseResult{
+		{Rank: 1, Hit: true, CitationOK: true, Score: 1},
+		{Rank: 2, Hit: true, Score: 0.6},
+		{Score: -1, Error: "回答失败"},
+		{Rank: 1, Hit: true, CitationOK: true, Score: 0.2},
+	}
+
+	r := summarize(cases, results)
+	if r.Total != 4 || r.Errors != 1 || len(r.Cases) != 4 {
+		t.Fatalf("summarize() total=%d errors=%d cases=%d, want 4 1 4", r.Total, r.Errors, len(r.Cases))
+	}
+	tests := []struct {
+		name      string
+		got, want float64
+	}{
+		{"HitRate", r.HitRate, 2.0 / 3},
+		{"MRR", r.MRR, (1 + 0.5) / 3},
+		{"CitationAccuracy", r.CitationAccuracy, 1.0 / 3},
+		{"AnswerScore", r.AnswerScore, (1 + 0.6 + 0.2) / 3},
+	}
+	for _, tt := range tests {
+		if math.Abs(tt.got-tt.want) > 1e-9 {
+			t.Fatalf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	if r := summarize(nil, nil); r.HitRate != 0 || r.MRR != 0 || r.AnswerScore != 0 {
+		t.Fatalf("summarize(nil) = %+v, want zero metrics", r)
+	}
+}
+
+// Test_sourceRank 测试期望来源的名次和检索结果去重
+func Test_sourceRank(t *testing.T) {
+	sources := []string{"uploads/handbook.pdf", "uploads/handbook.pdf", "docs/leave.pdf"}
+	tests := []struct {
+		expected string
+		want     int
+	}{
+		{"handbook.pdf", 1},
+		{"leave.pdf", 3},
+		{"docs/leave.pdf", 3},
+		{"trip.pdf", 0},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := sourceRank(sources, tt.expected); got != tt.want {
+			t.Fatalf("sourceRank(%q) = %d, want %d", tt.expected, got, tt.want)
+		}
+	}
+
+	if got, want := uniqueNames(sources), []string{"handbook.pdf", "leave.pdf"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("uniqueNames() = %v, want %v", got, want)
+	}
+}
+
+// Test_cites 测试从回答中解析引用来源并判断是否引用了期望来源
+func Test_cites(t *testing.T) {
+	tests := []struct {
+		answer   string
+		expected string
+		cited    []string
+		ok       bool
+	}{
+		{"陪产假为十五天。\n参考来源：handbook.pdf", "handbook.pdf", []string{"handbook.pdf"}, true},
+		{"见手册。\n参考来源：leave.pdf、 handbook.pdf \n", "uploads/handbook.pdf", []string{"leave.pdf", "handbook.pdf"}, true},
+		{"参考来源：旧.pdf\n更正后的回答\n参考来源：leave.pdf", "旧.pdf", []string{"leave.pdf"}, false},
+		{"陪产假为十五天。", "handbook.pdf", nil, false},
+		{"参考来源：", "handbook.pdf", nil, false},
+	}
+	for _, tt := range tests {
+		cited := toolx.ParseKnowledgeSources(tt.answer)
+		if !reflect.DeepEqual(cited, tt.cited) {
+			t.Fatalf("ParseKnowledgeSources(%q) = %v, want %v", tt.answer, cited, tt.cited)
+		}
+		if got := cites(cited, tt.expected); got != tt.ok {
+			t.Fatalf("cites(%v, %q) = %v, want %v", cited, tt.expected, got, tt.ok)
+		}
+	}
+}
+
+// Test_parseGrade 测试评分解析、范围限制和归一化
+func Test_parseGrade(t *testing.T) {
+	tests := []struct {
+		text   string
+		score  float64
+		reason string
+	}{
+		{"```json\n{\"score\": 4, \"reason\": \"基本正确\"}\n```", 0.8, "基本正确"},
+		{"```json\n{\"score\": \"3\"}\n```", 0.6, ""},
+		{"```json\n{\"score\": 9, \"reason\": \"超出范围\"}\n```", 1, "超出范围"},
+		{"```json\n{\"score\": -2}\n```", 0, ""},
+	}
+	for _, tt := range tests {
+		score, reason, err := parseGrade(tt.text)
+		if err != nil {
+			t.Fatalf("parseGrade(%q) error: %v", tt.text, err)
+		}
+		if math.Abs(score-tt.score) > 1e-9 || reason != tt.reason {
+			t.Fatalf("parseGrade(%q) = %v %q, want %v %q", tt.text, score, reason, tt.score, tt.reason)
+		}
+	}
+}
+
+// Test_fakeLLM_answer 测试假模型以第一段检索内容作答并引用其来源
+func Test_fakeLLM_answer(t *testing.T) {
+	prompt := "Use the following pieces of context to answer the question at the end.\n\n" +
+		toolx.KnowledgeSourceLabel + "handbook.pdf\n陪产假为十五天\n\n" +
+		toolx.KnowledgeSourceLabel + "leave.pdf\n年假按工龄计算\n\n" +
+		"Question: 陪产假有几天\nHelpful Answer:"
+
+	answer := newFakeLLM().answer(prompt)
+	if !strings.HasPrefix(answer, "陪产假为十五天") {
+		t.Fatalf("answer() = %q, want the first context", answer)
+	}
+	if cited := toolx.ParseKnowledgeSources(answer); !reflect.DeepEqual(cited, []string{"handbook.pdf"}) {
+		t.Fatalf("answer() cites %v, want [handbook.pdf]", cited)
+	}
+
+	empty := "Use the following pieces of context.\n\n\n\nQuestion: 陪产假有几天\nHelpful Answer:"
+	if got := newFakeLLM().answer(empty); got != "I don't know." {
+		t.Fatalf("answer() without context = %q, want I don't know.", got)
+	}
+}
+
+// Test_fakeLLM_grade 测试假模型按参考答案的词汇召回评分
+func Test_fakeLLM_grade(t *testing.T) {
+	tests := []struct {
+		reference string
+		answer    string
+		score     float64
+	}{
+		{"陪产假十五天", "陪产假十五天", 1},
+		{"陪产假十五天", "年假按工龄计算", 0},
+		{"陪产假十五天", "陪产假", 0.4},
+	}
+	for _, tt := range tests {
+		prompt := gradeMarker + "\n[问题]\n陪产假有几天\n" + gradeReferenceLabel + "\n" + tt.reference + "\n" +
+			gradeAnswerLabel + "\n" + tt.answer + "\n" + gradeEndLabel + "\n"
+		score, _, err := parseGrade(newFakeLLM().grade(prompt))
+		if err != nil {
+			t.Fatalf("grade(%q) error: %v", tt.answer, err)
+		}
+		if math.Abs(score-tt.score) > 1e-9 {
+			t.Fatalf("grade(%q, %q) = %v, want %v", tt.reference, tt.answer, score, tt.score)
+		}
+	}
+}
+
+// Test_loadSuite 测试加载用例集，用例名称默认为问题，缺少问题时报错
+func Test_loadSuite(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	suite, err := loadSuite(write("ok.yaml", `
+documents:
+  - ./uploads/handbook.pdf
+cases:
+  - name: 陪产假
+    question: 陪产假有几天
+    expected_source: handbook.pdf
+    expected_answer: 十五天
+  - question: 年假怎么算
+`))
+	if err != nil {
+		t.Fatalf("loadSuite() error: %v", err)
+	}
+	want := []Case{
+		{Name: "陪产假", Question: "陪产假有几天", ExpectedSource: "handbook.pdf", ExpectedAnswer: "十五天"},
+		{Name: "年假怎么算", Question: "年假怎么算"},
+	}
+	if !reflect.DeepEqual(suite.Cases, want) || !reflect.DeepEqual(suite.Documents, []string{"./uploads/handbook.pdf"}) {
+		t.Fatalf("loadSuite() = %+v, want cases %+v", suite, want)
+	}
+
+	if _, err := loadSuite(write("missing.yaml", "cases:\n  - name: 空问题\n")); err == nil {
+		t.Fatal("loadSuite() without question, want error")
+	}
+	if _, err := loadSuite(write("bad.yaml", "cases: [")); err == nil {
+		t.Fatal("loadSuite() with invalid yaml, want error")
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"BackEnd/internal/logic/chatinternal/toolx"
+	"BackEnd/pkg/search"
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// fakeEmbeddingDim 假模型向量维度
+const fakeEmbeddingDim = 256
+
+var toolNamesRe = regexp.MustCompile(`should be one of \[ (.+?) \]`)
+
+// fakeLLM 确定性的假模型，用于在没有真实大模型的 CI 环境中跑通评测
+// 它识别 agent、RetrievalQA 和评分三种提示词并给出可预期的输出，
+// 向量化使用分词后的哈希词袋，使相同词汇的文本具有较高的相似度
+type fakeLLM struct{}
+
+func newFakeLLM() *fakeLLM {
+	return &fakeLLM{}
+}
+
+// GenerateContent 根据提示词类型生成回答
+func (f *fakeLLM) GenerateContent(_ context.Context, messages []llms.MessageContent, _ ...llms.CallOption) (*llms.ContentResponse, error) {
+	var sb strings.Builder
+	for _, m := range messages {
+		for _, p := range m.Parts {
+			if t, ok := p.(llms.TextContent); ok {
+				sb.WriteString(t.Text)
+			}
+		}
+	}
+	prompt := sb.String()
+
+	var content string
+	switch {
+	case strings.Contains(prompt, gradeMarker):
+		content = f.grade(prompt)
+	case strings.Contains(prompt, "Helpful Answer:"):
+		content = f.answer(prompt)
+	case strings.Contains(prompt, "Action Input:"):
+		content = f.agent(prompt)
+	default:
+		return nil, errors.New("fake llm: unsupported prompt")
+	}
+
+	return &llms.ContentResponse{
+		Choices: []*llms.ContentChoice{{Content: content}},
+	}, nil
+}
+
+// Call 单轮调用
+func (f *fakeLLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, f, prompt, options...)
+}
+
+// CreateEmbedding 以分词哈希词袋生成归一化向量
+func (f *fakeLLM) CreateEmbedding(_ context.Context, texts []string) ([][]float32, error) {
+	res := make([][]float32, 0, len(texts))
+	for _, text := range texts {
+		vec := make([]float32, fakeEmbeddingDim)
+		for _, t := range search.Tokenize(text) {
+			h := fnv.New32a()
+			h.Write([]byte(t))
+			vec[h.Sum32()%fakeEmbeddingDim]++
+		}
+
+		var norm float64
+		for _, v := range vec {
+			norm += float64(v * v)
+		}
+		if norm == 0 {
+			vec[0], norm = 1, 1
+		}
+		norm = math.Sqrt(norm)
+		for i := range vec {
+			vec[i] = float32(float64(vec[i]) / norm)
+		}
+		res = append(res, vec)
+	}
+	return res, nil
+}
+
+// agent 第一轮调用检索工具，拿到工具结果后直接作为最终答案
+func (f *fakeLLM) agent(prompt string) string {
+	begin := prompt
+	if i := strings.LastIndex(prompt, "Begin!"); i >= 0 {
+		begin = prompt[i:]
+	}
+
+	if i := strings.LastIndex(begin, "Observation:"); i >= 0 {
+		observation := strings.TrimSpace(begin[i+len("Observation:"):])
+		observation = strings.TrimSuffix(observation, "Thought:")
+		return "Thought: I now know the final answer\nFinal Answer: " + strings.TrimSpace(observation)
+	}
+
+	question := ""
+	if i := strings.Index(begin, "Question:"); i >= 0 {
+		question = strings.TrimSpace(begin[i+len("Question:"):])
+		if end := strings.Index(question, "\n"); end >= 0 {
+			question = question[:end]
+		}
+	}
+
+	return fmt.Sprintf("Thought: 需要查询知识库\nAction: %s\nAction Input: %s", pickTool(prompt), question)
+}
+
+// pickTool 优先选择问答类工具
+func pickTool(prompt string) string {
+	m := toolNamesRe.FindStringSubmatch(prompt)
+	if len(m) < 2 {
+		return ""
+	}
+	names := strings.Split(m[1], ",")
+	for _, n := range names {
+		if strings.Contains(n, "qa") {
+			return strings.TrimSpace(n)
+		}
+	}
+	return strings.TrimSpace(names[0])
+}
+
+// answer 以第一段检索内容作为回答，并按提示词要求引用这段内容的来源
+func (f *fakeLLM) answer(prompt string) string {
+	text := prompt
+	if i := strings.Index(text, "\n\n"); i >= 0 {
+		text = text[i+2:]
+	}
+	if i := strings.LastIndex(text, "\n\nQuestion:"); i >= 0 {
+		text = text[:i]
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "I don't know."
+	}
+	if i := strings.Index(text, "\n\n"); i >= 0 {
+		text = text[:i]
+	}
+	if rest, ok := strings.CutPrefix(text, toolx.KnowledgeSourceLabel); ok {
+		source, content, _ := strings.Cut(rest, "\n")
+		return strings.TrimSpace(content) + "\n" + toolx.KnowledgeSourcesPrefix + source
+	}
+	return text
+}
+
+// grade 按参考答案词汇的召回比例给出 0-5 分
+func (f *fakeLLM) grade(prompt string) string {
+	reference := between(prompt, gradeReferenceLabel, gradeAnswerLabel)
+	answer := between(prompt, gradeAnswerLabel, gradeEndLabel)
+
+	score := int(math.Round(tokenRecall(reference, answer) * maxGradeScore))
+	return fmt.Sprintf("```json\n{\"score\": %d, \"reason\": \"fake grader: token recall\"}\n```", score)
+}
+
+// tokenRecall 参考文本的词汇在回答中出现的比例
+func tokenRecall(reference, answer string) float64 {
+	refTokens := search.Tokenize(reference)
+	if len(refTokens) == 0 {
+		return 0
+	}
+	answerTokens := make(map[string]bool)
+	for _, t := range search.Tokenize(answer) {
+		answerTokens[t] = true
+	}
+
+	hit := 0
+	for _, t := range refTokens {
+		if answerTokens[t] {
+			hit++
+		}
+	}
+	return float64(hit) / float64(len(refTokens))
+}
+
+// between 截取 start 与 end 标记之间的文本
+func between(s, start, end string) string {
+	i := strings.Index(s, start)
+	if i < 0 {
+		return ""
+	}
+	s = s[i+len(start):]
+	if j := strings.Index(s, end); j >= 0 {
+		s = s[:j]
+	}
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,150 @@
+// kbeval 知识库问答质量评测工具
+//
+// 读取 YAML 用例文件（问题 / 期望来源 / 参考答案），通过知识库处理器逐条作答，
+// 输出检索命中率、引用正确率和大模型评分，用于衡量提示词或切分参数调整的效果。
+//
+// 使用配置中的大模型：
+//
+//	go run ./cmd/kbeval -f etc/backend.yaml -cases etc/kbeval.yaml
+//
+// CI 中使用确定性的假模型（不依赖外部大模型接口），并设置指标下限：
+//
+//	go run ./cmd/kbeval -llm fake -min-hit-rate 0.8 -min-score 0.6
+//
+// 注意：假模型的向量维度与真实模型不同，应使用独立的 MySQL / Redis 环境。
+package main
+
+import (
+	"BackEnd/internal/config"
+	"BackEnd/internal/logic/chatinternal/toolx"
+	"BackEnd/internal/svc"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/viper"
+)
+
+var (
+	configFile  = flag.String("f", "etc/backend.yaml", "the config file")
+	casesFile   = flag.String("cases", "etc/kbeval.yaml", "the evaluation cases file")
+	llmMode     = flag.String("llm", "config", "llm to use: config | fake")
+	topK        = flag.Int("k", 0, "retrieval top k, defaults to Knowledge.TopK")
+	outputFile  = flag.String("o", "", "write the full report as JSON to this file")
+	skipIndex   = flag.Bool("skip-index", false, "do not index the documents listed in the cases file")
+	minHitRate  = flag.Float64("min-hit-rate", 0, "fail when retrieval hit rate is below this value")
+	minCitation = flag.Float64("min-citation", 0, "fail when citation accuracy is below this value")
+	minScore    = flag.Float64("min-score", 0, "fail when average answer score is below this value")
+)
+
+func main() {
+	flag.Parse()
+
+	// 加载配置
+	var c config.Config
+	viper.SetConfigFile(*configFile)
+	if err := viper.ReadInConfig(); err != nil {
+		panic(fmt.Errorf("fatal error config file: %w", err))
+	}
+	if err := viper.Unmarshal(&c); err != nil {
+		panic(fmt.Errorf("fatal error unmarshal config: %w", err))
+	}
+
+	suite, err := loadSuite(*casesFile)
+	if err != nil {
+		panic(err)
+	}
+
+	switch *llmMode {
+	case "config":
+	case "fake":
+		// 假模型不访问外部接口，占位 key 仅用于通过 openai 客户端的初始化校验
+		if c.AI.ApiKey == "" {
+			c.AI.ApiKey = "fake"
+		}
+	default:
+		panic(fmt.Errorf("unknown llm mode: %s", *llmMode))
+	}
+
+	svcCtx := svc.NewServiceContext(c)
+	if *llmMode == "fake" {
+		svcCtx.LLMs = newFakeLLM()
+	}
+
+	ctx := context.Background()
+
+	// 入库用例依赖的文档，相对路径以用例文件所在目录为准
+	if !*skipIndex {
+		indexer := toolx.NewKnowledgeIndexer(svcCtx)
+		for _, doc := range suite.Documents {
+			if !filepath.IsAbs(doc) {
+				doc = filepath.Join(filepath.Dir(*casesFile), doc)
+			}
+			res, err := indexer.IndexFile(ctx, doc, "", false)
+			if err != nil {
+				panic(fmt.Errorf("index %s: %w", doc, err))
+			}
+			fmt.Printf("indexed %s: %s (+%d -%d =%d)\n", doc, res.Status, res.Added, res.Removed, res.Kept)
+		}
+	}
+
+	k := *topK
+	if k <= 0 {
+		k = c.Knowledge.TopK
+	}
+	if k <= 0 {
+		k = 4
+	}
+
+	report := newEvaluator(svcCtx, k).Run(ctx, suite.Cases)
+	printReport(report, k)
+
+	if *outputFile != "" {
+		data, _ := json.MarshalIndent(report, "", "  ")
+		if err := os.WriteFile(*outputFile, data, 0644); err != nil {
+			panic(err)
+		}
+	}
+
+	// 指标低于下限时以非零状态码退出，便于 CI 判断
+	var failures []string
+	if report.HitRate < *minHitRate {
+		failures = append(failures, fmt.Sprintf("hit rate %.2f < %.2f", report.HitRate, *minHitRate))
+	}
+	if report.CitationAccuracy < *minCitation {
+		failures = append(failures, fmt.Sprintf("citation accuracy %.2f < %.2f", report.CitationAccuracy, *minCitation))
+	}
+	if report.AnswerScore < *minScore {
+		failures = append(failures, fmt.Sprintf("answer score %.2f < %.2f", report.AnswerScore, *minScore))
+	}
+	if len(failures) > 0 {
+		fmt.Println("FAIL: " + strings.Join(failures, "; "))
+		os.Exit(1)
+	}
+}
+
+// printReport 输出用例明细与汇总指标
+func printReport(r *Report, k int) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "CASE\tHIT\tRANK\tCITED\tSCORE\tERROR")
+	for _, c := range r.Cases {
+		score := "-"
+		if c.Score >= 0 {
+			score = fmt.Sprintf("%.2f", c.Score)
+		}
+		fmt.Fprintf(w, "%s\t%v\t%d\t%v\t%s\t%s\n", c.Name, c.Hit, c.Rank, c.CitationOK, score, c.Error)
+	}
+	w.Flush()
+
+	fmt.Println()
+	fmt.Printf("cases:             %d (errors: %d)\n", r.Total, r.Errors)
+	fmt.Printf("hit rate@%d:        %.2f\n", k, r.HitRate)
+	fmt.Printf("mrr:               %.2f\n", r.MRR)
+	fmt.Printf("citation accuracy: %.2f\n", r.CitationAccuracy)
+	fmt.Printf("answer score:      %.2f\n", r.AnswerScore)
+}
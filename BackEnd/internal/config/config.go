@@ -28,4 +28,67 @@ type Config struct {
 		Addr     string `mapstructure:"Addr"`
 		Password string `mapstructure:"Password"`
 	} `mapstructure:"Redis"`
+	Knowledge struct {
+		TopK int `mapstructure:"TopK"` // 混合检索返回的文档块数量
+		RRFK int `mapstructure:"RRFK"` // RRF 融合平滑常数
+		// 按文档类型配置切分参数，键为文档类型（如 pdf、handbook），未配置的类型使用 default
+		Chunking map[string]ChunkConfig `mapstructure:"Chunking"`
+	} `mapstructure:"Knowledge"`
+	// 用户默认时区（IANA 名称，如 Asia/Shanghai），用于解析自然语言时间，为空时使用服务器时区
+	TimeZone string `mapstructure:"TimeZone"`
+	// 法定节假日安排，用于解析“节前”“国庆期间”等时间范围，与工作日历中导入的节假日合并使用
+	Holidays []HolidayConfig `mapstructure:"Holidays"`
+	// 工作日历，法定节假日和调休上班通过 v1/calendar/import 导入
+	Calendar struct {
+		WorkHours []string `mapstructure:"WorkHours"` // 每天的工作时段，如 09:00-12:00，默认 09:00-12:00、13:00-18:00
+		Weekend   []int    `mapstructure:"Weekend"`   // 周末，0=周日 6=周六，默认周六、周日
+	} `mapstructure:"Calendar"`
+	Leave struct {
+		Rules []LeaveRuleConfig `mapstructure:"Rules"` // 需要余额控制的假期类型及年度额度，未配置的类型不限额
+	} `mapstructure:"Leave"`
+	// 审批超时提醒和升级，时长单位为小时，0 表示不启用
+	ApprovalSLA struct {
+		RemindHours    float64 `mapstructure:"RemindHours"`    // 待审批超过该时长提醒审批人
+		RemindInterval float64 `mapstructure:"RemindInterval"` // 重复提醒的间隔，0 表示只提醒一次
+		EscalateHours  float64 `mapstructure:"EscalateHours"`  // 待审批超过该时长自动升级
+		EscalateAction string  `mapstructure:"EscalateAction"` // 升级方式 superior=转交上级（默认） pass=自动通过 refuse=自动驳回
+		WorkHoursOnly  bool    `mapstructure:"WorkHoursOnly"`  // 只按工作日历中的工作时间计算时长
+	} `mapstructure:"ApprovalSLA"`
+	// 待办截止提醒和每日汇总
+	TodoReminder struct {
+		Offsets    []int `mapstructure:"Offsets"`    // 截止前多少分钟提醒执行人，为空时不提醒
+		DigestHour int   `mapstructure:"DigestHour"` // 每天几点发送今日到期和已逾期的待办汇总，0 表示不发送
+	} `mapstructure:"TodoReminder"`
+	// 审批单号格式，见 pkg/seqno
+	ApprovalNo struct {
+		Default string             `mapstructure:"Default"` // 未单独配置的审批类型使用的格式
+		Rules   []ApprovalNoConfig `mapstructure:"Rules"`   // 按审批类型配置的格式
+	} `mapstructure:"ApprovalNo"`
+}
+
+// ChunkConfig 知识库文档切分参数
+type ChunkConfig struct {
+	Size    int `mapstructure:"Size"`    // 块大小（字符数）
+	Overlap int `mapstructure:"Overlap"` // 相邻块重叠字符数
+}
+
+// HolidayConfig 节假日放假安排，日期格式为 2006-01-02
+type HolidayConfig struct {
+	Name  string `mapstructure:"Name"`  // 节日名称，如 国庆节
+	Start string `mapstructure:"Start"` // 放假开始日期（含）
+	End   string `mapstructure:"End"`   // 放假结束日期（含）
+}
+
+// ApprovalNoConfig 审批类型的单号格式
+type ApprovalNoConfig struct {
+	Type   int    `mapstructure:"Type"`   // 审批类型，见 model.ApprovalType
+	Format string `mapstructure:"Format"` // 如 QJ-{date}-{seq:4}
+}
+
+// LeaveRuleConfig 假期年度额度规则，每年额度 = Days + 工龄满整年数 * IncreasePerYear，不超过 MaxDays
+type LeaveRuleConfig struct {
+	Type            int     `mapstructure:"Type"`            // 假期类型，见 model.LeaveType
+	Days            float64 `mapstructure:"Days"`            // 基础额度（天）
+	IncreasePerYear float64 `mapstructure:"IncreasePerYear"` // 每满一年工龄增加的天数
+	MaxDays         float64 `mapstructure:"MaxDays"`         // 额度上限，0 表示不限
 }
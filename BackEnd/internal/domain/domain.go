@@ -132,16 +132,41 @@ type TodoRecord struct {
 }
 
 type Todo struct {
-	ID          string        `json:"id,omitempty"`
-	CreatorId   string        `json:"creatorId,omitempty"`
-	CreatorName string        `json:"creatorName,omitempty"`
-	Title       string        `json:"title,omitempty"`
-	DeadlineAt  int64         `json:"deadlineAt,omitempty"`
-	Desc        string        `json:"desc,omitempty"`
-	Status      int           `json:"status,omitempty"`
-	Records     []*TodoRecord `json:"records,omitempty"`
-	ExecuteIds  []string      `json:"executeIds,omitempty"` // 待办执行人
-	TodoStatus  int           `json:"todoStatus,omitempty"`
+	ID           string        `json:"id,omitempty"`
+	CreatorId    string        `json:"creatorId,omitempty"`
+	CreatorName  string        `json:"creatorName,omitempty"`
+	Title        string        `json:"title,omitempty"`
+	DeadlineAt   int64         `json:"deadlineAt,omitempty"`
+	Desc         string        `json:"desc,omitempty"`
+	Status       int           `json:"status,omitempty"`
+	Records      []*TodoRecord `json:"records,omitempty"`
+	ExecuteIds   []string      `json:"executeIds,omitempty"` // 待办执行人
+	TodoStatus   int           `json:"todoStatus,omitempty"`
+	Recurrence   string        `json:"recurrence,omitempty"`   // 重复规则，RRULE 格式，如每周五 FREQ=WEEKLY;BYDAY=FR
+	SeriesId     string        `json:"seriesId,omitempty"`     // 重复待办的首个实例ID
+	ParentId     string        `json:"parentId,omitempty"`     // 父待办ID，创建子待办时传入
+	AutoComplete bool          `json:"autoComplete,omitempty"` // 子待办全部完成时自动完成
+	Progress     *TodoProgress `json:"progress,omitempty"`     // 子待办完成进度，没有子待办时为空
+	Priority     int           `json:"priority,omitempty"`     // 优先级 0=无 1=低 2=中 3=高 4=紧急
+	Labels       []string      `json:"labels,omitempty"`       // 标签，修改时为空表示不修改，传空数组清空
+}
+
+// TodoProgress 子待办完成进度
+type TodoProgress struct {
+	Done    int `json:"done"`
+	Total   int `json:"total"`
+	Percent int `json:"percent"` // 0~100
+}
+
+// TodoNode 子待办树的节点
+type TodoNode struct {
+	ID         string        `json:"id"`
+	Title      string        `json:"title"`
+	DeadlineAt int64         `json:"deadlineAt,omitempty"`
+	TodoStatus int           `json:"todoStatus"`
+	Executors  []*UserTodo   `json:"executors,omitempty"`
+	Progress   *TodoProgress `json:"progress,omitempty"`
+	Children   []*TodoNode   `json:"children,omitempty"`
 }
 
 type UserTodo struct {
@@ -149,20 +174,43 @@ type UserTodo struct {
 	UserId     string `json:"userId,omitempty"`
 	UserName   string `json:"userName,omitempty"`
 	TodoId     string `json:"todoId,omitempty"`
-	TodoStatus int    `json:"todoStatus,omitempty"` // 待办事项的状态
+	TodoStatus int    `json:"todoStatus,omitempty"` // 待办事项的状态 0=待处理 1=进行中 2=已完成 3=已逾期
 }
 
 type TodoInfoResp struct {
-	ID          string        `json:"id,omitempty"`
-	CreatorId   string        `json:"creatorId,omitempty"`
-	CreatorName string        `json:"creatorName,omitempty"`
-	Title       string        `json:"title,omitempty"`
-	DeadlineAt  int64         `json:"deadlineAt,omitempty"`
-	Desc        string        `json:"desc,omitempty"`
-	Records     []*TodoRecord `json:"records,omitempty"`
-	ExecuteIds  []*UserTodo   `json:"executeIds,omitempty"`
-	Status      int           `json:"status,omitempty"`
-	TodoStatus  int           `json:"todoStatus,omitempty"`
+	ID           string         `json:"id,omitempty"`
+	CreatorId    string         `json:"creatorId,omitempty"`
+	CreatorName  string         `json:"creatorName,omitempty"`
+	Title        string         `json:"title,omitempty"`
+	DeadlineAt   int64          `json:"deadlineAt,omitempty"`
+	Desc         string         `json:"desc,omitempty"`
+	Records      []*TodoRecord  `json:"records,omitempty"`
+	ExecuteIds   []*UserTodo    `json:"executeIds,omitempty"`
+	Status       int            `json:"status,omitempty"`
+	TodoStatus   int            `json:"todoStatus,omitempty"`
+	Recurrence   string         `json:"recurrence,omitempty"`
+	RepeatText   string         `json:"repeatText,omitempty"` // 重复规则的描述，如 每周五
+	SeriesId     string         `json:"seriesId,omitempty"`
+	Occurrence   int            `json:"occurrence,omitempty"` // 重复待办的第几次
+	ParentId     string         `json:"parentId,omitempty"`
+	AutoComplete bool           `json:"autoComplete,omitempty"`
+	Progress     *TodoProgress  `json:"progress,omitempty"`
+	Children     []*TodoNode    `json:"children,omitempty"` // 子待办树
+	Priority     int            `json:"priority,omitempty"`
+	Labels       []string       `json:"labels,omitempty"`
+	History      []*TodoHistory `json:"history,omitempty"` // 变更记录，按时间顺序
+}
+
+// TodoHistory 待办的一条变更记录
+type TodoHistory struct {
+	UserId   string `json:"userId,omitempty"` // 操作人，系统操作为空
+	UserName string `json:"userName,omitempty"`
+	Action   string `json:"action"` // updated=修改 status=状态变化 assigned=修改执行人 reassigned=改派 handed_off=转交
+	Field    string `json:"field,omitempty"`
+	OldValue string `json:"oldValue,omitempty"`
+	NewValue string `json:"newValue,omitempty"`
+	Remark   string `json:"remark,omitempty"` // 说明，如转交原因
+	CreateAt int64  `json:"createAt"`
 }
 
 type FinishedTodoReq struct {
@@ -170,13 +218,58 @@ type FinishedTodoReq struct {
 	TodoId string `json:"todoId"`
 }
 
+// TodoStatusReq 执行人更新自己的完成状态
+type TodoStatusReq struct {
+	TodoId     string `json:"todoId"`
+	TodoStatus int    `json:"todoStatus"` // 0=待处理 1=进行中 2=已完成
+}
+
+// TodoReassignReq 创建人将某个执行人的待办改派给其他人
+type TodoReassignReq struct {
+	TodoId string `json:"todoId"`
+	FromId string `json:"fromId"` // 原执行人
+	ToId   string `json:"toId"`   // 新执行人
+}
+
+// TodoHandoffReq 执行人将自己的待办转交给其他人
+type TodoHandoffReq struct {
+	TodoId string `json:"todoId"`
+	ToId   string `json:"toId"`
+	Reason string `json:"reason,omitempty"`
+}
+
 type TodoListReq struct {
-	Id        string `json:"id,omitempty" form:"id,omitempty"`
-	UserId    string `json:"userId,omitempty" form:"userId,omitempty"`
-	Page      int    `json:"page,omitempty" form:"page,omitempty"`
-	Count     int    `json:"count,omitempty" form:"count,omitempty"`
-	StartTime int64  `json:"startTime,omitempty" form:"startTime,omitempty"`
-	EndTime   int64  `json:"endTime,omitempty" form:"endTime,omitempty"`
+	Id            string `json:"id,omitempty" form:"id,omitempty"`
+	UserId        string `json:"userId,omitempty" form:"userId,omitempty"`
+	Page          int    `json:"page,omitempty" form:"page,omitempty"`
+	Count         int    `json:"count,omitempty" form:"count,omitempty"`
+	StartTime     int64  `json:"startTime,omitempty" form:"startTime,omitempty"`
+	EndTime       int64  `json:"endTime,omitempty" form:"endTime,omitempty"`
+	DeadlineStart int64  `json:"deadlineStart,omitempty" form:"deadlineStart,omitempty"`
+	DeadlineEnd   int64  `json:"deadlineEnd,omitempty" form:"deadlineEnd,omitempty"`
+	Overdue       bool   `json:"overdue,omitempty" form:"overdue,omitempty"`       // 只查询已逾期未完成的
+	Role          string `json:"role,omitempty" form:"role,omitempty"`             // 为空时查询我创建的和分配给我的，created=我创建的 assigned=分配给我的
+	TodoStatus    []int  `json:"todoStatus,omitempty" form:"todoStatus,omitempty"` // 待办的整体状态，可传多个
+	MyStatus      []int  `json:"myStatus,omitempty" form:"myStatus,omitempty"`     // 我作为执行人的状态，可传多个
+	Label         string `json:"label,omitempty" form:"label,omitempty"`
+	Priority      []int  `json:"priority,omitempty" form:"priority,omitempty"`
+	Keyword       string `json:"keyword,omitempty" form:"keyword,omitempty"` // 匹配标题和描述
+	Sort          string `json:"sort,omitempty" form:"sort,omitempty"`       // created=创建时间（默认） deadline=截止时间 priority=优先级
+	Order         string `json:"order,omitempty" form:"order,omitempty"`     // asc 或 desc，默认创建时间和截止时间升序，优先级降序
+}
+
+const (
+	TodoRoleCreated  = "created"  // 我创建的
+	TodoRoleAssigned = "assigned" // 分配给我的
+)
+
+type TodoLabelCount struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+type TodoLabelListResp struct {
+	List []*TodoLabelCount `json:"data"`
 }
 
 type TodoListResp struct {
@@ -189,6 +282,11 @@ type Approver struct {
 	UserName string `json:"userName"`
 	Status   int    `json:"status"`
 	Reason   string `json:"reason,omitempty"` //请假原由
+	Step     int    `json:"step"`             //审批步骤，从0开始
+	Mode     string `json:"mode,omitempty"`   //同一步骤多人审批方式 and=会签 or=或签
+
+	OriginalUserId   string `json:"originalUserId,omitempty"`   //委托审批时的原审批人
+	OriginalUserName string `json:"originalUserName,omitempty"` //委托审批时的原审批人姓名
 }
 
 type MakeCard struct {
@@ -202,9 +300,9 @@ type Leave struct {
 	Type      int     `json:"type,omitempty" mapstructure:"type,omitempty"`           //请假类型
 	StartTime int64   `json:"startTime,omitempty" mapstructure:"startTime,omitempty"` //开始时间
 	EndTime   int64   `json:"endTime,omitempty" mapstructure:"endTime,omitempty"`     //结束时间
-	Duration  float32 `json:"duration,omitempty" mapstructure:"duration,omitempty"`   //时长
+	Duration  float32 `json:"duration,omitempty" mapstructure:"duration,omitempty"`   //时长，由服务端按工作日历计算
 	Reason    string  `json:"reason,omitempty" mapstructure:"reason,omitempty"`       //请假原由
-	TimeType  int     `json:"timeType,omitempty" mapstructure:"timeType,omitempty"`   //请假类型  1=小时 2=天
+	TimeType  int     `json:"timeType,omitempty" mapstructure:"timeType,omitempty"`   //时长单位 1=小时 2=天 3=半天 4=上半天 5=下半天
 }
 
 type GoOut struct {
@@ -214,48 +312,110 @@ type GoOut struct {
 	Reason    string  `json:"reason,omitempty" mapstructure:"omitempty"`    //请假原由
 }
 
+type Overtime struct {
+	StartTime    int64   `json:"startTime,omitempty"`
+	EndTime      int64   `json:"endTime,omitempty"`
+	Duration     float32 `json:"duration,omitempty"`     //时长（小时），由服务端计算，不足半小时的部分不计
+	Compensation int     `json:"compensation,omitempty"` //补偿方式 1=加班费 2=调休
+	Reason       string  `json:"reason,omitempty"`
+}
+
+type TripSegment struct {
+	Date      int64  `json:"date,omitempty"` //出发日期
+	From      string `json:"from,omitempty"`
+	To        string `json:"to,omitempty"`
+	Transport string `json:"transport,omitempty"` //交通方式，如飞机、火车
+}
+
+type BusinessTrip struct {
+	StartTime     int64          `json:"startTime,omitempty"`
+	EndTime       int64          `json:"endTime,omitempty"`
+	Duration      float32        `json:"duration,omitempty"`     //天数，由服务端按自然日计算
+	Destinations  []string       `json:"destinations,omitempty"` //目的地，为空时取行程中的到达地
+	Itinerary     []*TripSegment `json:"itinerary,omitempty"`
+	EstimatedCost float64        `json:"estimatedCost,omitempty"` //预估费用（元）
+	Reason        string         `json:"reason,omitempty"`
+}
+
 type Approval struct {
-	Id          string      `json:"id,omitempty"`
-	UserId      string      `json:"userId,omitempty"`
-	No          string      `json:"no,omitempty"`
-	Type        int         `json:"type,omitempty"`
-	Status      int         `json:"status,omitempty"`
-	Title       string      `json:"title,omitempty"`
-	Abstract    string      `json:"abstract,omitempty"`
-	Reason      string      `json:"reason,omitempty"`
-	FinishAt    int64       `json:"finishAt,omitempty"`
-	FinishDay   int64       `json:"finishDay,omitempty"`
-	FinishMonth int64       `json:"finishMonth,omitempty"`
-	FinishYeas  int64       `json:"finishYeas,omitempty"`
-	MakeCard    *MakeCard   `json:"makeCard,omitempty"`
-	Leave       *Leave      `json:"leave,omitempty"`
-	GoOut       *GoOut      `json:"goOut,omitempty"`
-	UpdateAt    int64       `json:"updateAt,omitempty"`
-	CreateAt    int64       `json:"createAt,omitempty"`
-	Approvers   []*Approver `json:"approvers,omitempty"`
+	Id           string         `json:"id,omitempty"`
+	UserId       string         `json:"userId,omitempty"`
+	No           string         `json:"no,omitempty"`
+	Type         int            `json:"type,omitempty"` //1=请假 2=外出 3=补卡 4=加班 5=出差 6=自定义表单
+	Status       int            `json:"status,omitempty"`
+	Title        string         `json:"title,omitempty"`
+	Abstract     string         `json:"abstract,omitempty"`
+	Reason       string         `json:"reason,omitempty"`
+	FinishAt     int64          `json:"finishAt,omitempty"`
+	FinishDay    int64          `json:"finishDay,omitempty"`
+	FinishMonth  int64          `json:"finishMonth,omitempty"`
+	FinishYeas   int64          `json:"finishYeas,omitempty"`
+	MakeCard     *MakeCard      `json:"makeCard,omitempty"`
+	Leave        *Leave         `json:"leave,omitempty"`
+	GoOut        *GoOut         `json:"goOut,omitempty"`
+	Overtime     *Overtime      `json:"overtime,omitempty"`
+	BusinessTrip *BusinessTrip  `json:"businessTrip,omitempty"`
+	FormId       string         `json:"formId,omitempty"`   //自定义表单ID，与 FormCode 二选一
+	FormCode     string         `json:"formCode,omitempty"` //自定义表单编码
+	Form         map[string]any `json:"form,omitempty"`     //自定义表单数据，key 为字段名
+	UpdateAt     int64          `json:"updateAt,omitempty"`
+	CreateAt     int64          `json:"createAt,omitempty"`
+	Approvers    []*Approver    `json:"approvers,omitempty"`
+	CopyPersons  []*Approver    `json:"copyPersons,omitempty"` //抄送人，与流程模板配置的抄送人合并
+	Attachments  []string       `json:"attachments,omitempty"` //附件地址，如病假证明
+	ParentId     string         `json:"parentId,omitempty"`    //重新提交时为原审批单ID
+	Revision     int            `json:"revision,omitempty"`    //修订版本，从1开始
 }
 
 type ApprovalInfoResp struct {
-	Id          string      `json:"id"`
-	User        *Approver   `json:"user"`
-	No          string      `json:"no"`
-	Type        int         `json:"type"`
-	Status      int         `json:"status"`
-	Title       string      `json:"title"`
-	Abstract    string      `json:"abstract"`
-	Reason      string      `json:"reason"`
-	Approver    *Approver   `json:"approver"`
-	Approvers   []*Approver `json:"approvers"`
-	CopyPersons []*Approver `json:"copyPersons"`
-	FinishAt    int64       `json:"finishAt"`
-	FinishDay   int64       `json:"finishDay"`
-	FinishMonth int64       `json:"finishMonth"`
-	FinishYeas  int64       `json:"finishYeas"`
-	MakeCard    *MakeCard   `json:"makeCard"`
-	Leave       *Leave      `json:"leave"`
-	GoOut       *GoOut      `json:"goOut"`
-	UpdateAt    int64       `json:"updateAt"`
-	CreateAt    int64       `json:"createAt"`
+	Id           string         `json:"id"`
+	User         *Approver      `json:"user"`
+	No           string         `json:"no"`
+	Type         int            `json:"type"`
+	Status       int            `json:"status"`
+	Title        string         `json:"title"`
+	Abstract     string         `json:"abstract"`
+	Reason       string         `json:"reason"`
+	Approver     *Approver      `json:"approver"`
+	Approvers    []*Approver    `json:"approvers"`
+	CopyPersons  []*Approver    `json:"copyPersons"`
+	FinishAt     int64          `json:"finishAt"`
+	FinishDay    int64          `json:"finishDay"`
+	FinishMonth  int64          `json:"finishMonth"`
+	FinishYeas   int64          `json:"finishYeas"`
+	MakeCard     *MakeCard      `json:"makeCard"`
+	Leave        *Leave         `json:"leave"`
+	GoOut        *GoOut         `json:"goOut"`
+	Overtime     *Overtime      `json:"overtime"`
+	BusinessTrip *BusinessTrip  `json:"businessTrip"`
+	FormId       string         `json:"formId,omitempty"`
+	FormName     string         `json:"formName,omitempty"`
+	FormFields   []*FormField   `json:"formFields,omitempty"` //提交时的表单定义
+	Form         map[string]any `json:"form,omitempty"`
+	UpdateAt     int64          `json:"updateAt"`
+	CreateAt     int64          `json:"createAt"`
+	Attachments  []string       `json:"attachments"`
+	ParentId     string         `json:"parentId,omitempty"`
+	Revision     int            `json:"revision"`
+	Timeline     []*ApprovalLog `json:"timeline"` // 审批时间线，按时间顺序
+}
+
+type ApprovalLog struct {
+	Id          string   `json:"id"`
+	Action      string   `json:"action"`           // created submitted viewed approved rejected commented withdrawn cancelled remind escalate auto_pass auto_refuse delegated reclaimed
+	UserId      string   `json:"userId,omitempty"` // 操作人，系统操作时为空
+	UserName    string   `json:"userName,omitempty"`
+	TargetId    string   `json:"targetId,omitempty"` // 操作对象，如被提醒或转交的审批人
+	TargetName  string   `json:"targetName,omitempty"`
+	Content     string   `json:"content"` // 说明、审批意见或评论内容
+	Attachments []string `json:"attachments,omitempty"`
+	CreateAt    int64    `json:"createAt"`
+}
+
+type ApprovalCommentReq struct {
+	ApprovalId  string   `json:"approvalId"`
+	Content     string   `json:"content"`
+	Attachments []string `json:"attachments,omitempty"` // 通过上传接口获得的文件路径
 }
 
 type DisposeReq struct {
@@ -264,21 +424,66 @@ type DisposeReq struct {
 	ApprovalId string `json:"approvalId" form:"approvalId"`
 }
 
+type BatchDisposeReq struct {
+	Status      int      `json:"status" form:"status"`
+	Reason      string   `json:"reason" form:"reason"`
+	ApprovalIds []string `json:"approvalIds" form:"approvalIds"`
+}
+
+type BatchDisposeResult struct {
+	ApprovalId string `json:"approvalId"`
+	Success    bool   `json:"success"`
+	Error      string `json:"error,omitempty"` // 失败原因
+}
+
+type BatchDisposeResp struct {
+	Succeeded int                   `json:"succeeded"`
+	Failed    int                   `json:"failed"`
+	Results   []*BatchDisposeResult `json:"results"`
+}
+
+type ApprovalActionReq struct {
+	ApprovalId string `json:"approvalId" form:"approvalId"`
+}
+
+type ApprovalSubmitReq struct {
+	ApprovalId  string      `json:"approvalId" form:"approvalId"`
+	Approvers   []*Approver `json:"approvers,omitempty"`   //指定审批人，匹配到流程模板时忽略，为空时按部门层级生成
+	CopyPersons []*Approver `json:"copyPersons,omitempty"` //抄送人，与流程模板配置的抄送人合并
+}
+
+// 审批列表范围
+const (
+	ApprovalScopeApplied = "applied" // 我发起的
+	ApprovalScopeTodo    = "todo"    // 当前步骤待我审批的
+	ApprovalScopeDone    = "done"    // 我已审批的
+	ApprovalScopeCopy    = "cc"      // 抄送给我的
+)
+
 type ApprovalListReq struct {
-	UserId string `json:"userId,omitempty" form:"userId,omitempty"`
-	Type   int    `json:"type,omitempty" form:"type,omitempty"`
-	Page   int    `json:"page,omitempty" form:"page,omitempty"`
-	Count  int    `json:"count,omitempty" form:"count,omitempty"`
+	Scope       string `json:"scope,omitempty" form:"scope,omitempty"` // 为空时查询我发起的和我审批的，applied=我发起的 todo=待我审批 done=我已审批 cc=抄送给我的
+	UserId      string `json:"userId,omitempty" form:"userId,omitempty"`
+	Status      []int  `json:"status,omitempty" form:"status,omitempty"`           // 审批状态，可传多个
+	ApplicantId string `json:"applicantId,omitempty" form:"applicantId,omitempty"` // 申请人
+	FormId      string `json:"formId,omitempty" form:"formId,omitempty"`           // 自定义表单
+	Type        int    `json:"type,omitempty" form:"type,omitempty"`
+	Page        int    `json:"page,omitempty" form:"page,omitempty"`
+	Count       int    `json:"count,omitempty" form:"count,omitempty"`
+	StartTime   int64  `json:"startTime,omitempty" form:"startTime,omitempty"` // 按提交时间筛选
+	EndTime     int64  `json:"endTime,omitempty" form:"endTime,omitempty"`
 }
 
 type ApprovalList struct {
 	Id              string `json:"id"`
+	No              string `json:"no"`
 	Type            int    `json:"type"`
 	Status          int    `json:"status"`
 	Title           string `json:"title"`
 	Abstract        string `json:"abstract"`
 	CreateId        string `json:"createId"`
-	ParticipatingId string `json:"participatingId"`
+	CreateName      string `json:"createName"`
+	ParticipatingId string `json:"participatingId"` // 当前步骤等待我处理的审批节点ID，不需要我处理时为空
+	CreateAt        int64  `json:"createAt"`
 }
 
 type ApprovalListResp struct {
@@ -286,6 +491,71 @@ type ApprovalListResp struct {
 	List  []*ApprovalList `json:"data"`
 }
 
+// ApprovalTabCounts 审批列表各页签的数量
+type ApprovalTabCounts struct {
+	Todo    int64 `json:"todo"`    // 待我审批
+	Applied int64 `json:"applied"` // 我发起的且审批中
+	Done    int64 `json:"done"`    // 我已审批
+	Cc      int64 `json:"cc"`      // 抄送给我的
+}
+
+type ApprovalInboxResp struct {
+	Count int64              `json:"count"`
+	List  []*ApprovalList    `json:"data"`
+	Tabs  *ApprovalTabCounts `json:"tabs"`
+}
+
+type WorkflowCondition struct {
+	Field  string    `json:"field"`            // leave.type leave.duration leave.timeType goOut.duration makeCard.checkType overtime.duration overtime.compensation businessTrip.duration businessTrip.estimatedCost
+	Op     string    `json:"op"`               // eq ne gt gte lt lte in
+	Value  float64   `json:"value,omitempty"`  // 比较值
+	Values []float64 `json:"values,omitempty"` // in 比较的取值列表
+}
+
+type WorkflowStep struct {
+	Name    string   `json:"name,omitempty"`
+	Mode    string   `json:"mode"`              // sequential=依次审批 and=会签 or=或签
+	UserIds []string `json:"userIds,omitempty"` // 指定审批人
+	Roles   []string `json:"roles,omitempty"`   // 审批角色 leader=直属负责人 superior=上级负责人，其他为自定义角色
+}
+
+type ApprovalWorkflow struct {
+	Id                string               `json:"id,omitempty"`
+	Name              string               `json:"name"`
+	Type              int                  `json:"type"`
+	FormId            string               `json:"formId,omitempty"` // 自定义表单，为空时适用于该类型的全部审批单
+	Priority          int                  `json:"priority"`
+	Enabled           bool                 `json:"enabled"`
+	RequireAttachment bool                 `json:"requireAttachment"`
+	Desc              string               `json:"desc,omitempty"`
+	Conditions        []*WorkflowCondition `json:"conditions"`
+	Steps             []*WorkflowStep      `json:"steps"`
+	CopyUserIds       []string             `json:"copyUserIds,omitempty"` // 抄送人
+	CopyRoles         []string             `json:"copyRoles,omitempty"`   // 抄送角色，与审批角色相同
+	CreateAt          int64                `json:"createAt,omitempty"`
+	UpdateAt          int64                `json:"updateAt,omitempty"`
+}
+
+type ApprovalWorkflowListReq struct {
+	Type  int `json:"type,omitempty" form:"type,omitempty"`
+	Page  int `json:"page,omitempty" form:"page,omitempty"`
+	Count int `json:"count,omitempty" form:"count,omitempty"`
+}
+
+type ApprovalWorkflowListResp struct {
+	Count int64               `json:"count"`
+	List  []*ApprovalWorkflow `json:"data"`
+}
+
+type UserRole struct {
+	Role    string   `json:"role"`
+	UserIds []string `json:"userIds"`
+}
+
+type UserRoleListResp struct {
+	List []*UserRole `json:"data"`
+}
+
 type ChatReq struct {
 	Prompts    string `json:"prompts,omitempty"`    // AI提示词/问题
 	ChatType   int    `json:"chatType,omitempty"`   // 聊天类型：0=默认，1=待办，2=审批等
@@ -358,19 +628,19 @@ type ConversationListResp struct {
 }
 
 type CreateGroupReq struct {
-	GroupId   string   `json:"groupId" binding:"required"`   // 群ID（conversationId）
-	GroupName string   `json:"groupName" binding:"required"` // 群名称
-	MemberIds []string `json:"memberIds" binding:"required"` // 成员ID列表（不包括创建者）
+	GroupId   string   `json:"groupId"`   // 群ID（conversationId）
+	GroupName string   `json:"groupName"` // 群名称
+	MemberIds []string `json:"memberIds"` // 成员ID列表（不包括创建者）
 }
 
 type AddGroupMembersReq struct {
-	GroupId   string   `json:"groupId" binding:"required"`   // 群ID
-	MemberIds []string `json:"memberIds" binding:"required"` // 成员ID列表
+	GroupId   string   `json:"groupId"`   // 群ID
+	MemberIds []string `json:"memberIds"` // 成员ID列表
 }
 
 type GroupPathReq struct {
-	GroupId string `uri:"groupId" binding:"required"` // 群ID
-	UserId  string `uri:"userId,omitempty"`           // 用户ID（可选）
+	GroupId string `uri:"groupId"`          // 群ID
+	UserId  string `uri:"userId,omitempty"` // 用户ID（可选）
 }
 
 type GroupMemberCountResp struct {
@@ -380,3 +650,261 @@ type GroupMemberCountResp struct {
 type IsMemberResp struct {
 	IsMember bool `json:"isMember"` // 是否是成员
 }
+
+type KnowledgeSearchReq struct {
+	Query string `json:"query" form:"query"`                     // 检索关键词或问题
+	Count int    `json:"count,omitempty" form:"count,omitempty"` // 返回数量，默认取配置的 TopK
+}
+
+type KnowledgeSnippet struct {
+	ChunkId     string  `json:"chunkId"`     // 文档块ID
+	Source      string  `json:"source"`      // 来源文件
+	Snippet     string  `json:"snippet"`     // 命中片段
+	Score       float64 `json:"score"`       // 融合得分
+	KeywordRank int     `json:"keywordRank"` // 关键词检索名次，0=未命中
+	VectorRank  int     `json:"vectorRank"`  // 向量检索名次，0=未命中
+}
+
+type KnowledgeSearchResp struct {
+	Count int64               `json:"count"`
+	List  []*KnowledgeSnippet `json:"data"`
+}
+
+type KnowledgeReindexReq struct {
+	Paths   []string `json:"paths,omitempty"`   // 需要重新索引的文件，只能是已入库文档或上传目录下的文件，为空时重新索引所有已入库文档
+	DocType string   `json:"docType,omitempty"` // 文档类型，为空时沿用原类型或文件扩展名
+	Force   bool     `json:"force,omitempty"`   // 忽略内容哈希，强制重新切分
+}
+
+type KnowledgeReindexJob struct {
+	Id        string   `json:"id"`
+	Status    string   `json:"status"`           // running=进行中 finished=已完成
+	Total     int      `json:"total"`            // 文档总数
+	Processed int      `json:"processed"`        // 已处理文档数
+	Skipped   int      `json:"skipped"`          // 未变化而跳过的文档数
+	Created   int      `json:"created"`          // 首次入库的文档数
+	Updated   int      `json:"updated"`          // 增量更新的文档数
+	Failed    int      `json:"failed"`           // 处理失败的文档数
+	Errors    []string `json:"errors,omitempty"` // 失败原因
+	StartAt   int64    `json:"startAt"`
+	FinishAt  int64    `json:"finishAt,omitempty"`
+}
+
+type Notification struct {
+	Id       string `json:"id"`
+	Type     string `json:"type"` // approval_copy=审批抄送
+	Title    string `json:"title"`
+	Content  string `json:"content"`
+	BizId    string `json:"bizId,omitempty"`  // 关联业务ID，如审批单ID
+	ReadAt   int64  `json:"readAt,omitempty"` // 阅读时间，0=未读
+	CreateAt int64  `json:"createAt"`
+}
+
+type NotificationListReq struct {
+	Unread bool   `json:"unread,omitempty" form:"unread,omitempty"` // 只看未读
+	Type   string `json:"type,omitempty" form:"type,omitempty"`
+	Page   int    `json:"page,omitempty" form:"page,omitempty"`
+	Count  int    `json:"count,omitempty" form:"count,omitempty"`
+}
+
+type NotificationListResp struct {
+	Count  int64           `json:"count"`
+	Unread int64           `json:"unread"` // 未读总数
+	List   []*Notification `json:"data"`
+}
+
+type NotificationReadReq struct {
+	Ids []string `json:"ids,omitempty"` // 为空时标记全部
+}
+
+type Delegation struct {
+	Id           string `json:"id,omitempty"`
+	UserId       string `json:"userId,omitempty"` // 委托人，创建时为当前用户
+	UserName     string `json:"userName,omitempty"`
+	DelegateId   string `json:"delegateId"` // 代理人
+	DelegateName string `json:"delegateName,omitempty"`
+	Type         int    `json:"type"` // 审批类型，0=全部类型
+	StartTime    int64  `json:"startTime"`
+	EndTime      int64  `json:"endTime"`
+	Reason       string `json:"reason,omitempty"`
+	Active       bool   `json:"active"` // 当前是否生效
+}
+
+type DelegationListReq struct {
+	Active bool `json:"active,omitempty" form:"active,omitempty"` // 只看生效中的委托
+	Page   int  `json:"page,omitempty" form:"page,omitempty"`
+	Count  int  `json:"count,omitempty" form:"count,omitempty"`
+}
+
+type DelegationListResp struct {
+	Count int64         `json:"count"`
+	List  []*Delegation `json:"data"`
+}
+
+type LeaveBalance struct {
+	Type      int     `json:"type"` // 假期类型
+	TypeName  string  `json:"typeName"`
+	Year      int     `json:"year"`
+	Total     float64 `json:"total"`     // 年度额度（天）
+	Used      float64 `json:"used"`      // 已使用（天）
+	Frozen    float64 `json:"frozen"`    // 审批中（天）
+	Available float64 `json:"available"` // 可用（天）
+}
+
+type LeaveBalanceReq struct {
+	UserId string `json:"userId,omitempty" form:"userId,omitempty"` // 为空时查询自己，查询他人需要管理员权限
+	Type   int    `json:"type,omitempty" form:"type,omitempty"`
+	Year   int    `json:"year,omitempty" form:"year,omitempty"` // 为空时为今年
+}
+
+type LeaveBalanceResp struct {
+	List []*LeaveBalance `json:"data"`
+}
+
+type LeaveLedger struct {
+	Id         string  `json:"id"`
+	Type       int     `json:"type"`
+	TypeName   string  `json:"typeName"`
+	Year       int     `json:"year"`
+	Kind       string  `json:"kind"`      // accrual=年度发放 adjust=调整 freeze=冻结 unfreeze=解冻 deduct=扣减 restore=返还
+	Days       float64 `json:"days"`      // 变动天数
+	Available  float64 `json:"available"` // 变动后的可用余额
+	ApprovalId string  `json:"approvalId,omitempty"`
+	Remark     string  `json:"remark,omitempty"`
+	CreateAt   int64   `json:"createAt"`
+}
+
+type LeaveLedgerListReq struct {
+	UserId string `json:"userId,omitempty" form:"userId,omitempty"`
+	Type   int    `json:"type,omitempty" form:"type,omitempty"`
+	Year   int    `json:"year,omitempty" form:"year,omitempty"`
+	Page   int    `json:"page,omitempty" form:"page,omitempty"`
+	Count  int    `json:"count,omitempty" form:"count,omitempty"`
+}
+
+type LeaveLedgerListResp struct {
+	Count int64          `json:"count"`
+	List  []*LeaveLedger `json:"data"`
+}
+
+type LeaveAdjustReq struct {
+	UserId string  `json:"userId"`
+	Type   int     `json:"type"`
+	Year   int     `json:"year,omitempty"` // 为空时为今年
+	Days   float64 `json:"days"`           // 正数增加，负数减少
+	Remark string  `json:"remark,omitempty"`
+}
+
+type CalendarDay struct {
+	Date string `json:"date"` // 2006-01-02
+	Kind int    `json:"kind"` // 1=放假 2=调休上班
+	Name string `json:"name,omitempty"`
+}
+
+type CalendarListReq struct {
+	Year int `json:"year,omitempty" form:"year,omitempty"` // 为空时为今年
+}
+
+type CalendarListResp struct {
+	WorkHours []string       `json:"workHours"` // 每天的工作时段
+	List      []*CalendarDay `json:"data"`
+}
+
+type CalendarImportResp struct {
+	Imported int `json:"imported"` // 导入的天数
+}
+
+type CalendarDurationReq struct {
+	StartTime int64 `json:"startTime" form:"startTime"`
+	EndTime   int64 `json:"endTime" form:"endTime"`
+	TimeType  int   `json:"timeType" form:"timeType"` // 1=小时 2=天 3=半天 4=上半天 5=下半天
+}
+
+type CalendarDurationResp struct {
+	StartTime int64   `json:"startTime"` // 上半天、下半天时为对应半天的起止时间
+	EndTime   int64   `json:"endTime"`
+	Duration  float32 `json:"duration"` // 小时或天
+}
+
+// ReportReq 统计报表查询条件，月份格式为 2006-01
+type ReportReq struct {
+	StartMonth   string `json:"startMonth,omitempty" form:"startMonth,omitempty"`     // 为空时为当月
+	EndMonth     string `json:"endMonth,omitempty" form:"endMonth,omitempty"`         // 为空时与开始月份相同，最多统计12个月
+	DepartmentId string `json:"departmentId,omitempty" form:"departmentId,omitempty"` // 包含下级部门
+	GroupBy      string `json:"groupBy,omitempty" form:"groupBy,omitempty"`           // user=按人员（默认） department=按部门，只对考勤异常统计有效
+}
+
+type ReportExportReq struct {
+	ReportReq
+	Report string `json:"report" form:"report"`                     // attendance=考勤异常统计 turnaround=审批时效统计
+	Format string `json:"format,omitempty" form:"format,omitempty"` // csv（默认）或 xlsx
+}
+
+// AttendanceReportRow 考勤异常统计，按请假、外出等业务开始时间所在的月份汇总已通过的审批单
+type AttendanceReportRow struct {
+	Month          string          `json:"month"`
+	DepartmentId   string          `json:"departmentId,omitempty"` // 所属的最下级部门
+	DepartmentName string          `json:"departmentName,omitempty"`
+	UserId         string          `json:"userId,omitempty"` // 按部门统计时为空
+	UserName       string          `json:"userName,omitempty"`
+	LeaveDays      map[int]float64 `json:"leaveDays"` // 按请假类型统计的请假天数
+	TotalLeaveDays float64         `json:"totalLeaveDays"`
+	GoOutHours     float64         `json:"goOutHours"`
+	MakeCardCount  int             `json:"makeCardCount"`
+	OvertimeHours  float64         `json:"overtimeHours"`
+	TripDays       float64         `json:"tripDays"`
+}
+
+type AttendanceReportResp struct {
+	List []*AttendanceReportRow `json:"data"`
+}
+
+// TurnaroundReportRow 审批时效统计，处理时长从所在步骤开始等待时算起
+type TurnaroundReportRow struct {
+	UserId         string  `json:"userId"`
+	UserName       string  `json:"userName"`
+	DepartmentName string  `json:"departmentName,omitempty"`
+	Count          int     `json:"count"`    // 处理数量
+	Refused        int     `json:"refused"`  // 其中驳回的数量
+	AvgHours       float64 `json:"avgHours"` // 平均处理时长（小时）
+	MaxHours       float64 `json:"maxHours"` // 最长处理时长（小时）
+}
+
+type TurnaroundReportResp struct {
+	List []*TurnaroundReportRow `json:"data"`
+}
+
+// FormField 自定义表单字段
+type FormField struct {
+	Key       string   `json:"key"`   // 字段名，由字母、数字和下划线组成
+	Label     string   `json:"label"` // 显示名称
+	Type      string   `json:"type"`  // text textarea number date datetime select multiselect bool
+	Required  bool     `json:"required,omitempty"`
+	Options   []string `json:"options,omitempty"`   // 单选、多选的选项
+	Min       *float64 `json:"min,omitempty"`       // 数字的最小值
+	Max       *float64 `json:"max,omitempty"`       // 数字的最大值
+	MaxLength int      `json:"maxLength,omitempty"` // 文本的最大字符数
+}
+
+type ApprovalForm struct {
+	Id               string       `json:"id,omitempty"`
+	Code             string       `json:"code"` // 表单编码，唯一
+	Name             string       `json:"name"`
+	Desc             string       `json:"desc,omitempty"`
+	Fields           []*FormField `json:"fields"`
+	AbstractTemplate string       `json:"abstractTemplate,omitempty"` // 摘要模板，{key} 替换为字段值，为空时列出前三个字段
+	Enabled          bool         `json:"enabled"`
+	CreateAt         int64        `json:"createAt,omitempty"`
+	UpdateAt         int64        `json:"updateAt,omitempty"`
+}
+
+type ApprovalFormListReq struct {
+	All   bool `json:"all,omitempty" form:"all,omitempty"` // 包括未启用的表单，仅管理员
+	Page  int  `json:"page,omitempty" form:"page,omitempty"`
+	Count int  `json:"count,omitempty" form:"count,omitempty"`
+}
+
+type ApprovalFormListResp struct {
+	Count int64           `json:"count"`
+	List  []*ApprovalForm `json:"data"`
+}
@@ -26,7 +26,15 @@ func (h *Approval) InitRegister(engine *gin.Engine) {
 	g.GET("/:id", h.Info)
 	g.POST("", h.Create)
 	g.PUT("/dispose", h.Dispose)
+	g.PUT("/dispose/batch", h.BatchDispose)
 	g.GET("/list", h.List)
+	g.GET("/inbox", h.Inbox)
+	g.GET("/counts", h.Counts)
+	g.PUT("/withdraw", h.Withdraw)
+	g.PUT("/draft", h.SaveDraft)
+	g.PUT("/submit", h.Submit)
+	g.POST("/resubmit", h.Resubmit)
+	g.POST("/comment", h.Comment)
 }
 
 func (h *Approval) Info(ctx *gin.Context) {
@@ -74,6 +82,21 @@ func (h *Approval) Dispose(ctx *gin.Context) {
 	}
 }
 
+func (h *Approval) BatchDispose(ctx *gin.Context) {
+	var req domain.BatchDisposeReq
+	if err := httpx.BindAndValidate(ctx, &req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+
+	res, err := h.approval.BatchDispose(ctx.Request.Context(), &req)
+	if err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", res)
+	}
+}
+
 func (h *Approval) List(ctx *gin.Context) {
 	var req domain.ApprovalListReq
 	if err := ctx.ShouldBindQuery(&req); err != nil {
@@ -88,3 +111,102 @@ func (h *Approval) List(ctx *gin.Context) {
 		httpx.SuccessWithMessage(ctx, "success", res)
 	}
 }
+
+func (h *Approval) Inbox(ctx *gin.Context) {
+	var req domain.ApprovalListReq
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+
+	res, err := h.approval.Inbox(ctx.Request.Context(), &req)
+	if err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", res)
+	}
+}
+
+func (h *Approval) Counts(ctx *gin.Context) {
+	res, err := h.approval.Counts(ctx.Request.Context())
+	if err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", res)
+	}
+}
+
+func (h *Approval) Withdraw(ctx *gin.Context) {
+	var req domain.ApprovalActionReq
+	if err := httpx.BindAndValidate(ctx, &req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+
+	err := h.approval.Withdraw(ctx.Request.Context(), &req)
+	if err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", nil)
+	}
+}
+
+func (h *Approval) SaveDraft(ctx *gin.Context) {
+	var req domain.Approval
+	if err := httpx.BindAndValidate(ctx, &req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+
+	err := h.approval.SaveDraft(ctx.Request.Context(), &req)
+	if err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", nil)
+	}
+}
+
+func (h *Approval) Submit(ctx *gin.Context) {
+	var req domain.ApprovalSubmitReq
+	if err := httpx.BindAndValidate(ctx, &req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+
+	err := h.approval.Submit(ctx.Request.Context(), &req)
+	if err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", nil)
+	}
+}
+
+func (h *Approval) Resubmit(ctx *gin.Context) {
+	var req domain.Approval
+	if err := httpx.BindAndValidate(ctx, &req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+
+	res, err := h.approval.Resubmit(ctx.Request.Context(), &req)
+	if err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", res)
+	}
+}
+
+func (h *Approval) Comment(ctx *gin.Context) {
+	var req domain.ApprovalCommentReq
+	if err := httpx.BindAndValidate(ctx, &req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+
+	err := h.approval.Comment(ctx.Request.Context(), &req)
+	if err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", nil)
+	}
+}
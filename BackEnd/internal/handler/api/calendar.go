@@ -0,0 +1,78 @@
+package api
+
+import (
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+
+	"BackEnd/internal/domain"
+	"BackEnd/internal/logic"
+	"BackEnd/internal/svc"
+	"BackEnd/pkg/httpx"
+)
+
+type Calendar struct {
+	svcCtx   *svc.ServiceContext
+	calendar logic.Calendar
+}
+
+func NewCalendar(svcCtx *svc.ServiceContext, calendar logic.Calendar) *Calendar {
+	return &Calendar{
+		svcCtx:   svcCtx,
+		calendar: calendar,
+	}
+}
+
+func (h *Calendar) InitRegister(engine *gin.Engine) {
+	g := engine.Group("v1/calendar", h.svcCtx.Jwt.Handler)
+	g.GET("/list", h.List)
+	g.GET("/duration", h.Duration)
+	g.POST("/import", h.Import)
+}
+
+func (h *Calendar) List(ctx *gin.Context) {
+	var req domain.CalendarListReq
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+
+	res, err := h.calendar.List(ctx.Request.Context(), &req)
+	if err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", res)
+	}
+}
+
+func (h *Calendar) Duration(ctx *gin.Context) {
+	var req domain.CalendarDurationReq
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+
+	res, err := h.calendar.Duration(ctx.Request.Context(), &req)
+	if err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", res)
+	}
+}
+
+// Import 导入节假日文件，表单字段 file 为 CSV 或 JSON 文件，replace 不为空时覆盖文件涉及年份的原有安排
+func (h *Calendar) Import(ctx *gin.Context) {
+	file, header, err := ctx.Request.FormFile("file")
+	if err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+	defer file.Close()
+
+	res, err := h.calendar.Import(ctx.Request.Context(), file, filepath.Ext(header.Filename), ctx.PostForm("replace") != "")
+	if err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", res)
+	}
+}
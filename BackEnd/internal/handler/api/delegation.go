@@ -0,0 +1,88 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"BackEnd/internal/domain"
+	"BackEnd/internal/logic"
+	"BackEnd/internal/svc"
+	"BackEnd/pkg/httpx"
+)
+
+type Delegation struct {
+	svcCtx     *svc.ServiceContext
+	delegation logic.Delegation
+}
+
+func NewDelegation(svcCtx *svc.ServiceContext, delegation logic.Delegation) *Delegation {
+	return &Delegation{
+		svcCtx:     svcCtx,
+		delegation: delegation,
+	}
+}
+
+func (h *Delegation) InitRegister(engine *gin.Engine) {
+	g := engine.Group("v1/delegation", h.svcCtx.Jwt.Handler)
+	g.GET("/list", h.List)
+	g.POST("", h.Create)
+	g.DELETE("/:id", h.Cancel)
+	g.PUT("/reclaim", h.Reclaim)
+}
+
+func (h *Delegation) List(ctx *gin.Context) {
+	var req domain.DelegationListReq
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+
+	res, err := h.delegation.List(ctx.Request.Context(), &req)
+	if err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", res)
+	}
+}
+
+func (h *Delegation) Create(ctx *gin.Context) {
+	var req domain.Delegation
+	if err := httpx.BindAndValidate(ctx, &req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+
+	res, err := h.delegation.Create(ctx.Request.Context(), &req)
+	if err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", res)
+	}
+}
+
+func (h *Delegation) Cancel(ctx *gin.Context) {
+	var req domain.IdPathReq
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+
+	if err := h.delegation.Cancel(ctx.Request.Context(), &req); err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", nil)
+	}
+}
+
+func (h *Delegation) Reclaim(ctx *gin.Context) {
+	var req domain.ApprovalActionReq
+	if err := httpx.BindAndValidate(ctx, &req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+
+	if err := h.delegation.Reclaim(ctx.Request.Context(), &req); err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", nil)
+	}
+}
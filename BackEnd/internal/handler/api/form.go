@@ -0,0 +1,105 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"BackEnd/internal/domain"
+	"BackEnd/internal/logic"
+	"BackEnd/internal/svc"
+	"BackEnd/pkg/httpx"
+)
+
+// Form 自定义审批表单管理接口
+type Form struct {
+	svcCtx *svc.ServiceContext
+	form   logic.Form
+}
+
+func NewForm(svcCtx *svc.ServiceContext, form logic.Form) *Form {
+	return &Form{
+		svcCtx: svcCtx,
+		form:   form,
+	}
+}
+
+func (h *Form) InitRegister(engine *gin.Engine) {
+	g := engine.Group("v1/form", h.svcCtx.Jwt.Handler)
+	g.GET("/list", h.List)
+	g.GET("/:id", h.Info)
+	g.POST("", h.Create)
+	g.PUT("", h.Edit)
+	g.DELETE("/:id", h.Delete)
+}
+
+func (h *Form) List(ctx *gin.Context) {
+	var req domain.ApprovalFormListReq
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+
+	res, err := h.form.List(ctx.Request.Context(), &req)
+	if err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", res)
+	}
+}
+
+func (h *Form) Info(ctx *gin.Context) {
+	var req domain.IdPathReq
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+
+	res, err := h.form.Info(ctx.Request.Context(), &req)
+	if err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", res)
+	}
+}
+
+func (h *Form) Create(ctx *gin.Context) {
+	var req domain.ApprovalForm
+	if err := httpx.BindAndValidate(ctx, &req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+
+	res, err := h.form.Create(ctx.Request.Context(), &req)
+	if err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", res)
+	}
+}
+
+func (h *Form) Edit(ctx *gin.Context) {
+	var req domain.ApprovalForm
+	if err := httpx.BindAndValidate(ctx, &req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+
+	if err := h.form.Edit(ctx.Request.Context(), &req); err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", nil)
+	}
+}
+
+func (h *Form) Delete(ctx *gin.Context) {
+	var req domain.IdPathReq
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+
+	if err := h.form.Delete(ctx.Request.Context(), &req); err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", nil)
+	}
+}
@@ -1,8 +1,10 @@
 package api
 
 import (
+	"BackEnd/internal/logic"
 	"BackEnd/internal/middleware"
 	"BackEnd/internal/svc"
+	"context"
 	"fmt"
 
 	"github.com/gin-gonic/gin"
@@ -61,8 +63,9 @@ func NewApiHandler(svcCtx *svc.ServiceContext) *ApiHandler {
 	return h
 }
 
-// Run 启动服务
+// Run 启动服务，同时启动后台定时任务
 func (h *ApiHandler) Run() error {
+	logic.RunJobs(context.Background(), logic.Jobs(h.svcCtx))
 	return h.srv.Run(h.addr)
 }
 
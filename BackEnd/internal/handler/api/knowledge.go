@@ -0,0 +1,74 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"BackEnd/internal/domain"
+	"BackEnd/internal/logic"
+	"BackEnd/internal/svc"
+	"BackEnd/pkg/httpx"
+)
+
+type Knowledge struct {
+	svcCtx    *svc.ServiceContext
+	knowledge logic.Knowledge
+}
+
+func NewKnowledge(svcCtx *svc.ServiceContext, knowledge logic.Knowledge) *Knowledge {
+	return &Knowledge{
+		svcCtx:    svcCtx,
+		knowledge: knowledge,
+	}
+}
+
+func (h *Knowledge) InitRegister(engine *gin.Engine) {
+	g := engine.Group("v1/knowledge", h.svcCtx.Jwt.Handler)
+	g.GET("/search", h.Search)
+	g.POST("/reindex", h.Reindex)
+	g.GET("/reindex/:id", h.ReindexStatus)
+}
+
+func (h *Knowledge) Search(ctx *gin.Context) {
+	var req domain.KnowledgeSearchReq
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+
+	res, err := h.knowledge.Search(ctx.Request.Context(), &req)
+	if err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", res)
+	}
+}
+
+func (h *Knowledge) Reindex(ctx *gin.Context) {
+	var req domain.KnowledgeReindexReq
+	if err := httpx.BindAndValidate(ctx, &req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+
+	res, err := h.knowledge.Reindex(ctx.Request.Context(), &req)
+	if err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", res)
+	}
+}
+
+func (h *Knowledge) ReindexStatus(ctx *gin.Context) {
+	var req domain.IdPathReq
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+
+	res, err := h.knowledge.ReindexStatus(ctx.Request.Context(), &req)
+	if err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", res)
+	}
+}
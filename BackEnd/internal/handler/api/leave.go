@@ -0,0 +1,73 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"BackEnd/internal/domain"
+	"BackEnd/internal/logic"
+	"BackEnd/internal/svc"
+	"BackEnd/pkg/httpx"
+)
+
+type Leave struct {
+	svcCtx *svc.ServiceContext
+	leave  logic.Leave
+}
+
+func NewLeave(svcCtx *svc.ServiceContext, leave logic.Leave) *Leave {
+	return &Leave{
+		svcCtx: svcCtx,
+		leave:  leave,
+	}
+}
+
+func (h *Leave) InitRegister(engine *gin.Engine) {
+	g := engine.Group("v1/leave", h.svcCtx.Jwt.Handler)
+	g.GET("/balance", h.Balance)
+	g.GET("/ledger", h.Ledger)
+	g.PUT("/balance/adjust", h.Adjust)
+}
+
+func (h *Leave) Balance(ctx *gin.Context) {
+	var req domain.LeaveBalanceReq
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+
+	res, err := h.leave.Balance(ctx.Request.Context(), &req)
+	if err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", res)
+	}
+}
+
+func (h *Leave) Ledger(ctx *gin.Context) {
+	var req domain.LeaveLedgerListReq
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+
+	res, err := h.leave.Ledger(ctx.Request.Context(), &req)
+	if err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", res)
+	}
+}
+
+func (h *Leave) Adjust(ctx *gin.Context) {
+	var req domain.LeaveAdjustReq
+	if err := httpx.BindAndValidate(ctx, &req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+
+	if err := h.leave.Adjust(ctx.Request.Context(), &req); err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", nil)
+	}
+}
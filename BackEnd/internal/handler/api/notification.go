@@ -0,0 +1,57 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"BackEnd/internal/domain"
+	"BackEnd/internal/logic"
+	"BackEnd/internal/svc"
+	"BackEnd/pkg/httpx"
+)
+
+type Notification struct {
+	svcCtx       *svc.ServiceContext
+	notification logic.Notification
+}
+
+func NewNotification(svcCtx *svc.ServiceContext, notification logic.Notification) *Notification {
+	return &Notification{
+		svcCtx:       svcCtx,
+		notification: notification,
+	}
+}
+
+func (h *Notification) InitRegister(engine *gin.Engine) {
+	g := engine.Group("v1/notification", h.svcCtx.Jwt.Handler)
+	g.GET("/list", h.List)
+	g.PUT("/read", h.Read)
+}
+
+func (h *Notification) List(ctx *gin.Context) {
+	var req domain.NotificationListReq
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+
+	res, err := h.notification.List(ctx.Request.Context(), &req)
+	if err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", res)
+	}
+}
+
+func (h *Notification) Read(ctx *gin.Context) {
+	var req domain.NotificationReadReq
+	if err := httpx.BindAndValidate(ctx, &req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+
+	if err := h.notification.Read(ctx.Request.Context(), &req); err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", nil)
+	}
+}
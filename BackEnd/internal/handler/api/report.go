@@ -0,0 +1,83 @@
+package api
+
+import (
+	"mime"
+	"net/http"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+
+	"BackEnd/internal/domain"
+	"BackEnd/internal/logic"
+	"BackEnd/internal/svc"
+	"BackEnd/pkg/httpx"
+)
+
+type Report struct {
+	svcCtx *svc.ServiceContext
+	report logic.Report
+}
+
+func NewReport(svcCtx *svc.ServiceContext, report logic.Report) *Report {
+	return &Report{
+		svcCtx: svcCtx,
+		report: report,
+	}
+}
+
+func (h *Report) InitRegister(engine *gin.Engine) {
+	g := engine.Group("v1/report", h.svcCtx.Jwt.Handler)
+	g.GET("/attendance", h.Attendance)
+	g.GET("/turnaround", h.Turnaround)
+	g.GET("/export", h.Export)
+}
+
+func (h *Report) Attendance(ctx *gin.Context) {
+	var req domain.ReportReq
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+
+	res, err := h.report.Attendance(ctx.Request.Context(), &req)
+	if err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", res)
+	}
+}
+
+func (h *Report) Turnaround(ctx *gin.Context) {
+	var req domain.ReportReq
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+
+	res, err := h.report.Turnaround(ctx.Request.Context(), &req)
+	if err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", res)
+	}
+}
+
+func (h *Report) Export(ctx *gin.Context) {
+	var req domain.ReportExportReq
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+
+	name, data, err := h.report.Export(ctx.Request.Context(), &req)
+	if err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+	contentType := "text/csv; charset=utf-8"
+	if filepath.Ext(name) == ".xlsx" {
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	ctx.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	ctx.Data(http.StatusOK, contentType, data)
+}
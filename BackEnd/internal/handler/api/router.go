@@ -14,6 +14,14 @@ func initHandler(svc *svc.ServiceContext) []Handler {
 		approvalLogic   = logic.NewApproval(svc)
 		chatLogic       = logic.NewChat(svc)
 		groupLogic      = logic.NewGroup(svc)
+		knowledgeLogic  = logic.NewKnowledge(svc)
+		workflowLogic   = logic.NewWorkflow(svc)
+		noticeLogic     = logic.NewNotification(svc)
+		delegationLogic = logic.NewDelegation(svc)
+		leaveLogic      = logic.NewLeave(svc)
+		calendarLogic   = logic.NewCalendar(svc)
+		reportLogic     = logic.NewReport(svc)
+		formLogic       = logic.NewForm(svc)
 	)
 
 	// new handlers
@@ -25,6 +33,14 @@ func initHandler(svc *svc.ServiceContext) []Handler {
 		department = NewDepartment(svc, departmentLogic)
 		todo       = NewTodo(svc, todoLogic)
 		approval   = NewApproval(svc, approvalLogic)
+		knowledge  = NewKnowledge(svc, knowledgeLogic)
+		workflow   = NewWorkflow(svc, workflowLogic)
+		notice     = NewNotification(svc, noticeLogic)
+		delegation = NewDelegation(svc, delegationLogic)
+		leave      = NewLeave(svc, leaveLogic)
+		calendar   = NewCalendar(svc, calendarLogic)
+		report     = NewReport(svc, reportLogic)
+		form       = NewForm(svc, formLogic)
 	)
 
 	return []Handler{
@@ -35,5 +51,13 @@ func initHandler(svc *svc.ServiceContext) []Handler {
 		department,
 		todo,
 		approval,
+		knowledge,
+		workflow,
+		notice,
+		delegation,
+		leave,
+		calendar,
+		report,
+		form,
 	}
 }
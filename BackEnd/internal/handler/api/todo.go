@@ -31,7 +31,11 @@ func (h *Todo) InitRegister(r *gin.Engine) {
 		group.DELETE("/:id", h.Delete)
 		group.GET("/:id", h.Get)
 		group.GET("/list", h.List)
+		group.GET("/labels", h.Labels)
 		group.POST("/finish", h.Finish)
+		group.POST("/status", h.UpdateStatus)
+		group.POST("/reassign", h.Reassign)
+		group.POST("/handoff", h.Handoff)
 		group.POST("/record", h.CreateRecord)
 	}
 }
@@ -130,6 +134,20 @@ func (h *Todo) List(ctx *gin.Context) {
 	httpx.Success(ctx, resp)
 }
 
+func (h *Todo) Labels(ctx *gin.Context) {
+	userID, err := token.GetUserIDFromGin(ctx)
+	if err != nil {
+		userID = 1 // Temporary fallback
+	}
+
+	resp, err := h.logic.Labels(ctx.Request.Context(), userID)
+	if err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+	httpx.Success(ctx, resp)
+}
+
 func (h *Todo) Finish(ctx *gin.Context) {
 	var req domain.FinishedTodoReq
 	if err := httpx.BindAndValidate(ctx, &req); err != nil {
@@ -149,6 +167,63 @@ func (h *Todo) Finish(ctx *gin.Context) {
 	httpx.SuccessWithMessage(ctx, "操作成功", nil)
 }
 
+func (h *Todo) UpdateStatus(ctx *gin.Context) {
+	var req domain.TodoStatusReq
+	if err := httpx.BindAndValidate(ctx, &req); err != nil {
+		httpx.BadRequest(ctx, err.Error())
+		return
+	}
+
+	userID, err := token.GetUserIDFromGin(ctx)
+	if err != nil {
+		userID = 1 // Temporary fallback
+	}
+
+	if err := h.logic.UpdateStatus(ctx.Request.Context(), userID, &req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+	httpx.SuccessWithMessage(ctx, "操作成功", nil)
+}
+
+func (h *Todo) Reassign(ctx *gin.Context) {
+	var req domain.TodoReassignReq
+	if err := httpx.BindAndValidate(ctx, &req); err != nil {
+		httpx.BadRequest(ctx, err.Error())
+		return
+	}
+
+	userID, err := token.GetUserIDFromGin(ctx)
+	if err != nil {
+		userID = 1 // Temporary fallback
+	}
+
+	if err := h.logic.Reassign(ctx.Request.Context(), userID, &req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+	httpx.SuccessWithMessage(ctx, "操作成功", nil)
+}
+
+func (h *Todo) Handoff(ctx *gin.Context) {
+	var req domain.TodoHandoffReq
+	if err := httpx.BindAndValidate(ctx, &req); err != nil {
+		httpx.BadRequest(ctx, err.Error())
+		return
+	}
+
+	userID, err := token.GetUserIDFromGin(ctx)
+	if err != nil {
+		userID = 1 // Temporary fallback
+	}
+
+	if err := h.logic.Handoff(ctx.Request.Context(), userID, &req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+	httpx.SuccessWithMessage(ctx, "操作成功", nil)
+}
+
 func (h *Todo) CreateRecord(ctx *gin.Context) {
 	var req domain.TodoRecord
 	if err := httpx.BindAndValidate(ctx, &req); err != nil {
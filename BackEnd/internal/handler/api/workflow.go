@@ -0,0 +1,130 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"BackEnd/internal/domain"
+	"BackEnd/internal/logic"
+	"BackEnd/internal/svc"
+	"BackEnd/pkg/httpx"
+)
+
+// Workflow 审批流程模板管理接口
+type Workflow struct {
+	svcCtx   *svc.ServiceContext
+	workflow logic.Workflow
+}
+
+func NewWorkflow(svcCtx *svc.ServiceContext, workflow logic.Workflow) *Workflow {
+	return &Workflow{
+		svcCtx:   svcCtx,
+		workflow: workflow,
+	}
+}
+
+func (h *Workflow) InitRegister(engine *gin.Engine) {
+	g := engine.Group("v1/workflow", h.svcCtx.Jwt.Handler)
+	g.GET("/list", h.List)
+	g.GET("/role/list", h.RoleList)
+	g.PUT("/role", h.SetRole)
+	g.GET("/:id", h.Info)
+	g.POST("", h.Create)
+	g.PUT("", h.Edit)
+	g.DELETE("/:id", h.Delete)
+}
+
+func (h *Workflow) List(ctx *gin.Context) {
+	var req domain.ApprovalWorkflowListReq
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+
+	res, err := h.workflow.List(ctx.Request.Context(), &req)
+	if err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", res)
+	}
+}
+
+func (h *Workflow) Info(ctx *gin.Context) {
+	var req domain.IdPathReq
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+
+	res, err := h.workflow.Info(ctx.Request.Context(), &req)
+	if err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", res)
+	}
+}
+
+func (h *Workflow) Create(ctx *gin.Context) {
+	var req domain.ApprovalWorkflow
+	if err := httpx.BindAndValidate(ctx, &req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+
+	res, err := h.workflow.Create(ctx.Request.Context(), &req)
+	if err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", res)
+	}
+}
+
+func (h *Workflow) Edit(ctx *gin.Context) {
+	var req domain.ApprovalWorkflow
+	if err := httpx.BindAndValidate(ctx, &req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+
+	if err := h.workflow.Edit(ctx.Request.Context(), &req); err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", nil)
+	}
+}
+
+func (h *Workflow) Delete(ctx *gin.Context) {
+	var req domain.IdPathReq
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+
+	if err := h.workflow.Delete(ctx.Request.Context(), &req); err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", nil)
+	}
+}
+
+func (h *Workflow) RoleList(ctx *gin.Context) {
+	res, err := h.workflow.RoleList(ctx.Request.Context())
+	if err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", res)
+	}
+}
+
+func (h *Workflow) SetRole(ctx *gin.Context) {
+	var req domain.UserRole
+	if err := httpx.BindAndValidate(ctx, &req); err != nil {
+		httpx.FailWithErr(ctx, err)
+		return
+	}
+
+	if err := h.workflow.SetRole(ctx.Request.Context(), &req); err != nil {
+		httpx.FailWithErr(ctx, err)
+	} else {
+		httpx.SuccessWithMessage(ctx, "success", nil)
+	}
+}
@@ -5,24 +5,50 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"BackEnd/internal/domain"
 	"BackEnd/internal/model"
 	"BackEnd/internal/svc"
+	"BackEnd/pkg/formx"
 	"BackEnd/pkg/timeutil"
 	"BackEnd/pkg/token"
 	"BackEnd/pkg/util"
+	"BackEnd/pkg/workcal"
 	"BackEnd/pkg/xerr"
 
 	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// maxBatchDispose 批量审批一次最多处理的审批单数量
+const maxBatchDispose = 100
+
 type Approval interface {
 	Info(ctx context.Context, req *domain.IdPathReq) (resp *domain.ApprovalInfoResp, err error)
 	Create(ctx context.Context, req *domain.Approval) (resp *domain.IdResp, err error)
 	Dispose(ctx context.Context, req *domain.DisposeReq) (err error)
 	List(ctx context.Context, req *domain.ApprovalListReq) (resp *domain.ApprovalListResp, err error)
+	// Withdraw 申请人撤回待审批的审批单，或撤销已通过的请假（销假）
+	Withdraw(ctx context.Context, req *domain.ApprovalActionReq) (err error)
+	// SaveDraft 修改草稿
+	SaveDraft(ctx context.Context, req *domain.Approval) (err error)
+	// Submit 提交草稿进入审批
+	Submit(ctx context.Context, req *domain.ApprovalSubmitReq) (err error)
+	// Resubmit 修改被驳回或已撤回的审批单，作为新的修订版本重新提交
+	Resubmit(ctx context.Context, req *domain.Approval) (resp *domain.IdResp, err error)
+	// BatchDispose 批量审批，逐个按 Dispose 的规则处理并返回每个审批单的结果
+	BatchDispose(ctx context.Context, req *domain.BatchDisposeReq) (resp *domain.BatchDisposeResp, err error)
+	// Inbox 待我审批的审批单，同时返回各页签的数量
+	Inbox(ctx context.Context, req *domain.ApprovalListReq) (resp *domain.ApprovalInboxResp, err error)
+	// Counts 各页签的数量，用于显示角标
+	Counts(ctx context.Context) (resp *domain.ApprovalTabCounts, err error)
+	// Comment 评论审批单，可附带上传的文件
+	Comment(ctx context.Context, req *domain.ApprovalCommentReq) (err error)
+	// CheckSLA 提醒超时未处理的审批人，超过升级时长时转交上级或自动处理，由后台任务定时执行
+	CheckSLA(ctx context.Context) (err error)
 }
 
 type approval struct {
@@ -40,12 +66,27 @@ func (l *approval) Info(ctx context.Context, req *domain.IdPathReq) (resp *domai
 	// 关联查询：查出审批单，同时查出申请人信息，以及审批流程记录（包含审批人信息）
 	if err := l.svcCtx.DB.WithContext(ctx).
 		Preload("User").
-		Preload("Approvers").
+		Preload("Approvers", func(db *gorm.DB) *gorm.DB { return db.Order("step, id") }).
 		Preload("Approvers.User").
+		Preload("Approvers.OriginalUser").
+		Preload("CopyPersons.User").
 		First(&approval, req.Id).Error; err != nil {
 		log.Error().Err(err).Str("id", req.Id).Msg("failed to find approval info")
 		return nil, xerr.New(err)
 	}
+	// 只有申请人、审批人、抄送人和管理员可以查看
+	uid, err := token.GetUserID(ctx)
+	if err != nil {
+		return nil, xerr.New(err)
+	}
+	ok, err := l.isParticipant(ctx, &approval, uid)
+	if err != nil {
+		log.Error().Err(err).Str("id", req.Id).Msg("failed to check approval participant")
+		return nil, xerr.New(err)
+	}
+	if !ok {
+		return nil, xerr.New(errors.New("only participants of this request can view it"))
+	}
 
 	// 转换基础信息
 	resp = &domain.ApprovalInfoResp{
@@ -62,6 +103,11 @@ func (l *approval) Info(ctx context.Context, req *domain.IdPathReq) (resp *domai
 		FinishYeas:  approval.FinishYeas,
 		UpdateAt:    approval.UpdatedAt.Unix(),
 		CreateAt:    approval.CreatedAt.Unix(),
+		Attachments: approval.Attachments,
+		Revision:    approval.Revision,
+	}
+	if approval.ParentID > 0 {
+		resp.ParentId = util.UintToString(approval.ParentID)
 	}
 
 	if approval.FinishAt != nil {
@@ -104,27 +150,70 @@ func (l *approval) Info(ctx context.Context, req *domain.IdPathReq) (resp *domai
 		}
 	}
 
-	// 处理审批流程
+	if approval.Overtime != nil {
+		resp.Overtime = &domain.Overtime{
+			StartTime:    approval.Overtime.StartTime,
+			EndTime:      approval.Overtime.EndTime,
+			Duration:     approval.Overtime.Duration,
+			Compensation: int(approval.Overtime.Compensation),
+			Reason:       approval.Overtime.Reason,
+		}
+	}
+	if approval.BusinessTrip != nil {
+		resp.BusinessTrip = toDomainBusinessTrip(approval.BusinessTrip)
+	}
+	if approval.Form != nil {
+		resp.FormId = util.UintToString(approval.FormID)
+		resp.FormName = approval.Form.Name
+		resp.FormFields = toDomainFormFields(approval.Form.Fields)
+		resp.Form = approval.Form.Values
+	}
+
+	// 处理审批流程，审批人已按步骤排序
+	step, pending := model.CurrentStep(approval.Approvers)
 	for _, approver := range approval.Approvers {
 		// 添加到审批人列表
-		resp.Approvers = append(resp.Approvers, &domain.Approver{
+		item := &domain.Approver{
 			UserId:   strconv.Itoa(int(approver.UserID)),
 			UserName: approver.User.Name,
 			Status:   int(approver.Status),
 			Reason:   approver.Reason,
-		})
+			Step:     approver.Step,
+			Mode:     string(approver.Mode),
+		}
+		if approver.OriginalUserID > 0 {
+			// 委托审批，展示原审批人
+			item.OriginalUserId = util.UintToString(approver.OriginalUserID)
+			item.OriginalUserName = approver.OriginalUser.Name
+		}
+		resp.Approvers = append(resp.Approvers, item)
 
-		// 查找当前审批人 (第一个待审批的人)
-		// 注意：这里假设 Approvers 是按顺序插入的。如果不是，可能需要 Sort 一下。
-		if resp.Approver == nil && approver.Status == model.Processed { // 0: 待审批
+		// 当前审批人：审批中时，当前步骤第一个待审批的人
+		if resp.Approver == nil && pending && approval.Status == model.Processed &&
+			approver.Step == step && approver.Status == model.Processed {
 			resp.Approver = &domain.Approver{
 				UserId:   strconv.Itoa(int(approver.UserID)),
 				UserName: approver.User.Name,
 				Status:   int(approver.Status),
+				Step:     approver.Step,
 			}
 		}
 	}
 
+	if resp.Timeline, err = l.timeline(ctx, approval.ID); err != nil {
+		log.Error().Err(err).Msg("failed to find approval timeline")
+		return nil, xerr.New(err)
+	}
+	l.recordView(ctx, &approval, uid)
+
+	resp.CopyPersons = make([]*domain.Approver, 0, len(approval.CopyPersons))
+	for _, c := range approval.CopyPersons {
+		resp.CopyPersons = append(resp.CopyPersons, &domain.Approver{
+			UserId:   util.UintToString(c.UserID),
+			UserName: c.User.Name,
+		})
+	}
+
 	return resp, nil
 }
 
@@ -149,13 +238,40 @@ func (l *approval) Create(ctx context.Context, req *domain.Approval) (resp *doma
 		return nil, xerr.New(errors.New("user_id is required"))
 	}
 
+	// 新建的审批单只能是草稿或直接提交审批
+	status := model.Processed
+	if model.ApprovalStatus(req.Status) == model.Draft {
+		status = model.Draft
+	}
+
 	// Create basic approval object
 	approval := &model.Approval{
-		No:     GenRandomNo(11),
-		Reason: req.Reason,
-		Type:   model.ApprovalType(req.Type),
-		Status: model.ApprovalStatus(req.Status),
-		UserID: userID,
+		UserID:   userID,
+		Revision: 1,
+	}
+	if err := l.fillApproval(ctx, approval, req); err != nil {
+		return nil, err
+	}
+	if err := l.saveApproval(ctx, approval, status, req.Approvers, req.CopyPersons); err != nil {
+		return nil, err
+	}
+
+	return &domain.IdResp{
+		Id: util.UintToString(approval.ID),
+	}, nil
+}
+
+// fillApproval 根据请求填充审批单内容：类型、业务详情、摘要、标题和附件
+func (l *approval) fillApproval(ctx context.Context, approval *model.Approval, req *domain.Approval) error {
+	approval.Reason = req.Reason
+	approval.Type = model.ApprovalType(req.Type)
+	approval.Leave, approval.GoOut, approval.MakeCard = nil, nil, nil
+	approval.Overtime, approval.BusinessTrip = nil, nil
+	approval.FormID, approval.Form = 0, nil
+
+	cal, err := loadWorkCalendar(ctx, l.svcCtx)
+	if err != nil {
+		return xerr.New(err)
 	}
 
 	// Handle details based on type
@@ -164,28 +280,36 @@ func (l *approval) Create(ctx context.Context, req *domain.Approval) (resp *doma
 	case model.LeaveApproval:
 		if req.Leave != nil {
 			approval.Leave = &model.Leave{
-				Type:      model.LeaveType(req.Leave.Type),
-				StartTime: req.Leave.StartTime,
-				EndTime:   req.Leave.EndTime,
-				Reason:    req.Leave.Reason,
-				TimeType:  model.TimeFormatType(req.Leave.TimeType),
+				Type:     model.LeaveType(req.Leave.Type),
+				Reason:   req.Leave.Reason,
+				TimeType: model.TimeFormatType(req.Leave.TimeType),
+			}
+			if approval.Leave.TimeType == 0 {
+				approval.Leave.TimeType = model.DayTimeFormatType
+			}
+			// 按工作日历计算请假时长，不使用客户端传入的时长
+			var err error
+			approval.Leave.StartTime, approval.Leave.EndTime, approval.Leave.Duration, err = workDuration(cal,
+				req.Leave.StartTime, req.Leave.EndTime, approval.Leave.TimeType)
+			if err != nil {
+				return xerr.New(err)
 			}
-			// Calculate duration if not provided or just trust frontend?
-			// AIWorkHelper trusts frontend or calculates it. Let's just copy.
-			approval.Leave.Duration = req.Leave.Duration
 
 			abstract = fmt.Sprintf("【%s】: 【%s】-【%s】",
 				approval.Leave.Type.ToString(),
-				timeutil.Format(req.Leave.StartTime),
-				timeutil.Format(req.Leave.EndTime))
+				timeutil.Format(approval.Leave.StartTime),
+				timeutil.Format(approval.Leave.EndTime))
 			if approval.Reason == "" {
 				approval.Reason = req.Leave.Reason
 			}
 		}
 	case model.GoOutApproval:
 		if req.GoOut != nil {
-			// Calculate duration if needed. AIWorkHelper does: duration := float32(req.GoOut.EndTime-req.GoOut.StartTime) / 3600.0
-			duration := float32(req.GoOut.EndTime-req.GoOut.StartTime) / 3600.0
+			// 外出时长按工作时段计算小时数，不计夜间、周末和节假日
+			_, _, duration, err := workDuration(cal, req.GoOut.StartTime, req.GoOut.EndTime, model.HourTimeFormatType)
+			if err != nil {
+				return xerr.New(err)
+			}
 			approval.GoOut = &model.GoOut{
 				StartTime: req.GoOut.StartTime,
 				EndTime:   req.GoOut.EndTime,
@@ -214,26 +338,214 @@ func (l *approval) Create(ctx context.Context, req *domain.Approval) (resp *doma
 				approval.Reason = req.MakeCard.Reason
 			}
 		}
+	case model.OvertimeApproval:
+		// 加班时长用于调休额度和统计，缺少加班信息的审批单无法处理
+		if req.Overtime == nil {
+			return xerr.New(errors.New("overtime details are required"))
+		}
+		overtime, err := toModelOvertime(req.Overtime)
+		if err != nil {
+			return xerr.New(err)
+		}
+		approval.Overtime = overtime
+		abstract = fmt.Sprintf("【%s】-【%s】【%s】",
+			timeutil.Format(overtime.StartTime),
+			timeutil.Format(overtime.EndTime),
+			overtime.Compensation.ToString())
+		if approval.Reason == "" {
+			approval.Reason = req.Overtime.Reason
+		}
+	case model.TripApproval:
+		if req.BusinessTrip == nil {
+			return xerr.New(errors.New("business trip details are required"))
+		}
+		trip, err := toModelBusinessTrip(req.BusinessTrip, l.svcCtx.Location())
+		if err != nil {
+			return xerr.New(err)
+		}
+		approval.BusinessTrip = trip
+		abstract = fmt.Sprintf("【%s】: 【%s】-【%s】",
+			strings.Join(trip.Destinations, "、"),
+			timeutil.Format(trip.StartTime),
+			timeutil.Format(trip.EndTime))
+		if approval.Reason == "" {
+			approval.Reason = req.BusinessTrip.Reason
+		}
+	case model.FormApproval:
+		// 按表单定义校验数据，审批单保存提交时的表单定义，表单修改后不影响已提交的审批单
+		form, err := findForm(ctx, l.svcCtx.DB, req.FormId, req.FormCode)
+		if err != nil {
+			return xerr.New(err)
+		}
+		fields := toFormxFields(form.Fields)
+		values, err := formx.Validate(fields, req.Form)
+		if err != nil {
+			return xerr.New(err)
+		}
+		approval.FormID = form.ID
+		approval.Form = &model.FormData{
+			Name:   form.Name,
+			Fields: form.Fields,
+			Values: values,
+		}
+		abstract = formx.Render(form.AbstractTemplate, fields, values, l.svcCtx.Location())
 	}
 
 	// Get User Name for Title
 	var user model.User
-	if err := l.svcCtx.DB.WithContext(ctx).First(&user, userID).Error; err != nil {
-		log.Error().Err(err).Uint("userID", userID).Msg("failed to find user for approval title")
-		return nil, xerr.New(err)
+	if err := l.svcCtx.DB.WithContext(ctx).First(&user, approval.UserID).Error; err != nil {
+		log.Error().Err(err).Uint("userID", approval.UserID).Msg("failed to find user for approval title")
+		return xerr.New(err)
+	}
+	typeName := approval.Type.ToString()
+	if approval.Form != nil {
+		typeName = approval.Form.Name
+	}
+	approval.Title = fmt.Sprintf("%s 提交的 %s", user.Name, typeName)
+	if err := l.checkAttachments(req.Attachments); err != nil {
+		return xerr.New(err)
 	}
-	approval.Title = fmt.Sprintf("%s 提交的 %s", user.Name, approval.Type.ToString())
 	approval.Abstract = abstract
+	approval.Attachments = req.Attachments
+	return nil
+}
 
-	if err := l.svcCtx.DB.WithContext(ctx).Create(approval).Error; err != nil {
-		log.Error().Err(err).Msg("failed to create approval")
-		return nil, xerr.New(err)
+// approvalStartAt 审批单的业务开始时间：请假、外出、加班、出差的开始时间或补卡时间，其他审批返回 0
+func approvalStartAt(a *model.Approval) int64 {
+	switch {
+	case a.Type == model.LeaveApproval && a.Leave != nil:
+		return a.Leave.StartTime
+	case a.Type == model.GoOutApproval && a.GoOut != nil:
+		return a.GoOut.StartTime
+	case a.Type == model.MakeCardApproval && a.MakeCard != nil:
+		return a.MakeCard.Date
+	case a.Type == model.OvertimeApproval && a.Overtime != nil:
+		return a.Overtime.StartTime
+	case a.Type == model.TripApproval && a.BusinessTrip != nil:
+		return a.BusinessTrip.StartTime
+	}
+	return 0
+}
+
+// saveApproval 以 status 状态保存审批单，提交审批时同时生成审批人和抄送人
+func (l *approval) saveApproval(ctx context.Context, approval *model.Approval, status model.ApprovalStatus, reqApprovers, reqCopyPersons []*domain.Approver) error {
+	var (
+		approvers []model.Approver
+		copies    []model.ApprovalCopy
+		cal       *workcal.Calendar
+	)
+	if status == model.Processed {
+		workflow, err := matchWorkflow(ctx, l.svcCtx.DB, approval)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to match approval workflow")
+			return xerr.New(err)
+		}
+		if approvers, err = l.buildApprovers(ctx, approval, reqApprovers, workflow); err != nil {
+			return err
+		}
+		if copies, err = l.buildCopyPersons(ctx, approval, reqCopyPersons, workflow); err != nil {
+			log.Error().Err(err).Msg("failed to build approval copy persons")
+			return xerr.New(err)
+		}
+		if approval.Type == model.LeaveApproval {
+			if cal, err = loadWorkCalendar(ctx, l.svcCtx); err != nil {
+				return xerr.New(err)
+			}
+		}
 	}
+	approval.Status = status
+	isNew := approval.ID == 0
 
-	// Save Approvers
-	var approvers []model.Approver
-	if len(req.Approvers) > 0 {
-		for _, a := range req.Approvers {
+	return l.svcCtx.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if approval.No == "" {
+			no, err := l.nextApprovalNo(tx, approval.Type)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to generate approval no")
+				return xerr.New(err)
+			}
+			approval.No = no
+		}
+		approval.StartAt = approvalStartAt(approval)
+		if err := tx.Save(approval).Error; err != nil {
+			log.Error().Err(err).Msg("failed to save approval")
+			return xerr.New(err)
+		}
+		if err := l.logSave(tx, approval, isNew); err != nil {
+			log.Error().Err(err).Msg("failed to record approval timeline")
+			return xerr.New(err)
+		}
+		if len(approvers) > 0 {
+			applyDelegations(tx, approval, approvers)
+			for i := range approvers {
+				approvers[i].ApprovalID = approval.ID
+			}
+			if err := tx.Create(&approvers).Error; err != nil {
+				log.Error().Err(err).Msg("failed to create approvers")
+				return xerr.New(err)
+			}
+			for i := range approvers {
+				if approvers[i].OriginalUserID == 0 {
+					continue
+				}
+				if err := addDelegationLog(tx, &approvers[i]); err != nil {
+					log.Error().Err(err).Msg("failed to record approval delegation")
+					return xerr.New(err)
+				}
+			}
+		}
+		if len(copies) > 0 {
+			for i := range copies {
+				copies[i].ApprovalID = approval.ID
+			}
+			if err := tx.Create(&copies).Error; err != nil {
+				log.Error().Err(err).Msg("failed to create approval copy persons")
+				return xerr.New(err)
+			}
+		}
+		if status == model.Processed {
+			// 请假提交时冻结余额，余额不足时不允许提交
+			if err := freezeLeave(tx, l.svcCtx.Config, cal, approval); err != nil {
+				return xerr.New(err)
+			}
+		}
+		return nil
+	})
+}
+
+// logSave 记录审批单的创建和提交
+func (l *approval) logSave(tx *gorm.DB, approval *model.Approval, isNew bool) error {
+	if isNew {
+		content := ""
+		if approval.ParentID > 0 {
+			content = fmt.Sprintf("修改后重新提交，第%d版", approval.Revision)
+		}
+		if err := addApprovalLog(tx, approval.ID, approval.UserID, model.ActionCreate, content); err != nil {
+			return err
+		}
+	}
+	if approval.Status == model.Processed {
+		return addApprovalLog(tx, approval.ID, approval.UserID, model.ActionSubmit, "")
+	}
+	return nil
+}
+
+// buildApprovers 生成审批人：匹配到流程模板时必须按模板，其次使用指定的审批人，最后按部门层级
+func (l *approval) buildApprovers(ctx context.Context, approval *model.Approval, reqApprovers []*domain.Approver, workflow *model.ApprovalWorkflow) (approvers []model.Approver, err error) {
+	// 流程模板可要求上传附件（如病假证明）
+	if workflow != nil && workflow.RequireAttachment && len(approval.Attachments) == 0 {
+		return nil, xerr.New(fmt.Errorf("审批流程「%s」需要上传附件", workflow.Name))
+	}
+
+	// Build Approvers
+	if workflow != nil {
+		// 按流程模板设置审批人，角色在提交时解析为具体用户，忽略申请人指定的审批人
+		approvers, err = l.buildApproversFromWorkflow(ctx, workflow, approval.UserID)
+		if err != nil {
+			log.Error().Err(err).Str("workflow", workflow.Name).Msg("failed to build approvers from workflow")
+			return nil, xerr.New(err)
+		}
+	} else if len(reqApprovers) > 0 {
+		for _, a := range reqApprovers {
 			if a.UserId == "" {
 				continue
 			}
@@ -241,137 +553,100 @@ func (l *approval) Create(ctx context.Context, req *domain.Approval) (resp *doma
 			if err != nil || uid <= 0 {
 				continue
 			}
+			if uid == approval.UserID {
+				return nil, xerr.New(errors.New("不能指定自己为审批人"))
+			}
 			approvers = append(approvers, model.Approver{
-				ApprovalID: approval.ID,
-				UserID:     uid,
-				Status:     model.Processed,
+				UserID: uid,
+				Status: model.Processed,
+				Step:   len(approvers),
 			})
 		}
 	} else {
 		// 自动根据部门层级设置审批人
-		approvers, err = l.buildApproversFromDepartment(ctx, userID)
+		approvers, err = l.buildApproversFromDepartment(ctx, approval.UserID)
 		if err != nil {
 			log.Error().Err(err).Msg("failed to build approvers from department")
-		}
-	}
-
-	if len(approvers) > 0 {
-		for i := range approvers {
-			approvers[i].ApprovalID = approval.ID
-		}
-		if err := l.svcCtx.DB.WithContext(ctx).Create(&approvers).Error; err != nil {
-			log.Error().Err(err).Msg("failed to create approvers")
 			return nil, xerr.New(err)
 		}
 	}
 
-	return &domain.IdResp{
-		Id: util.UintToString(approval.ID),
-	}, nil
+	// 没有审批人的审批单无法处理，拒绝提交
+	if len(approvers) == 0 {
+		return nil, xerr.New(errors.New("未找到审批人，请指定审批人或联系管理员配置审批流程"))
+	}
+	return approvers, nil
 }
 
 // buildApproversFromDepartment 根据部门层级自动构建审批人列表
 // 参考 AIWorkHelper 的实现：找到用户所属的最深层部门，然后按层级向上添加审批人
 func (l *approval) buildApproversFromDepartment(ctx context.Context, userID uint) ([]model.Approver, error) {
-	// 1. 查找用户所属的所有部门
-	var deptUsers []model.DepartmentUser
-	if err := l.svcCtx.DB.WithContext(ctx).Where("user_id = ?", userID).Find(&deptUsers).Error; err != nil {
-		return nil, err
-	}
-
-	if len(deptUsers) == 0 {
-		return nil, errors.New("用户未关联任何部门")
-	}
-
-	// 2. 查询这些部门的详细信息
-	var deptIDs []uint
-	for _, du := range deptUsers {
-		deptIDs = append(deptIDs, du.DepartmentID)
-	}
-
-	var depts []model.Department
-	if err := l.svcCtx.DB.WithContext(ctx).Where("id IN ?", deptIDs).Find(&depts).Error; err != nil {
+	targetDept, err := l.userDepartment(ctx, userID)
+	if err != nil {
 		return nil, err
 	}
-
-	// 3. 找出层级最深的部门（ParentPath最长的）
-	var targetDept *model.Department
-	maxPathLen := -1
-	for i := range depts {
-		pathLen := len(depts[i].ParentPath)
-		if pathLen > maxPathLen {
-			maxPathLen = pathLen
-			targetDept = &depts[i]
-		}
-	}
-
-	if targetDept == nil || targetDept.LeaderID == 0 {
+	if targetDept.LeaderID == 0 {
 		return nil, errors.New("未找到用户所属部门或部门无负责人")
 	}
 
-	// 4. 构建审批人列表
-	var approvers []model.Approver
-
-	// 添加直属部门负责人作为第一级审批人
-	approvers = append(approvers, model.Approver{
-		UserID: targetDept.LeaderID,
-		Status: model.Processed,
-	})
-
-	// 5. 解析父部门路径，添加上级部门负责人
-	if targetDept.ParentPath != "" {
-		parentIds := model.ParseParentPath(targetDept.ParentPath)
-		if len(parentIds) > 0 {
-			// 查询所有父部门
-			var parentDepts []model.Department
-			if err := l.svcCtx.DB.WithContext(ctx).Where("id IN ?", parentIds).Find(&parentDepts).Error; err == nil {
-				// 按层级从下到上添加审批人（从最近的上级到最远的上级）
-				for i := len(parentIds) - 1; i >= 0; i-- {
-					// 查找对应的部门
-					for _, pd := range parentDepts {
-						if pd.ID == parentIds[i] && pd.LeaderID > 0 {
-							approvers = append(approvers, model.Approver{
-								UserID: pd.LeaderID,
-								Status: model.Processed,
-							})
-							break
-						}
-					}
-				}
-			}
+	// 直属部门负责人作为第一级审批人，上级部门负责人从近到远依次审批，申请人自己是负责人时跳过
+	leaders := append([]uint{targetDept.LeaderID}, l.parentLeaders(ctx, targetDept)...)
+	approvers := make([]model.Approver, 0, len(leaders))
+	for _, uid := range leaders {
+		if uid == userID {
+			continue
 		}
+		approvers = append(approvers, model.Approver{
+			UserID: uid,
+			Status: model.Processed,
+			Step:   len(approvers),
+		})
 	}
-
 	return approvers, nil
 }
 
-// updateApprovalStatus 更新审批状态（提取的独立方法）
-func (l *approval) updateApprovalStatus(ctx context.Context, approval *model.Approval, status model.ApprovalStatus) error {
+// updateApprovalStatus 根据审批人的处理结果推进审批单状态（提取的独立方法）
+// 任一步骤驳回则整个流程结束；当前步骤全部通过后进入下一步，没有待审批的步骤时审批通过
+func (l *approval) updateApprovalStatus(tx *gorm.DB, approval *model.Approval, approvers []model.Approver, status model.ApprovalStatus) error {
 	if status == model.Refuse {
 		approval.Status = model.Refuse
-	} else if status == model.Pass {
-		// 查询所有审批人，检查是否全部通过
-		var approvers []model.Approver
-		if err := l.svcCtx.DB.WithContext(ctx).Where("approval_id = ?", approval.ID).Find(&approvers).Error; err != nil {
-			return xerr.New(err)
-		}
-
-		allPassed := true
-		for _, a := range approvers {
-			if a.Status != model.Pass {
-				allPassed = false
-				break
-			}
-		}
+	} else if _, pending := model.CurrentStep(approvers); pending {
+		return nil
+	} else {
+		approval.Status = model.Pass
+	}
 
-		if allPassed {
-			approval.Status = model.Pass
-			now := time.Now()
-			approval.FinishAt = &now
-		}
+	now := time.Now()
+	local := now.In(l.svcCtx.Location())
+	approval.FinishAt = &now
+	// 完成日期用于按日、月、年统计，格式分别为 20060102、200601、2006
+	approval.FinishYeas = int64(local.Year())
+	approval.FinishMonth = approval.FinishYeas*100 + int64(local.Month())
+	approval.FinishDay = approval.FinishMonth*100 + int64(local.Day())
+	if err := tx.Save(approval).Error; err != nil {
+		return err
+	}
+	if err := settleLeave(tx, approval, model.Processed, approval.Status); err != nil {
+		return err
 	}
+	if err := creditOvertime(tx, l.svcCtx.Config, approval); err != nil {
+		return err
+	}
+	return l.notifyCopyPersons(tx, approval)
+}
 
-	return l.svcCtx.DB.WithContext(ctx).Save(approval).Error
+// notifyCopyPersons 审批完成后通知抄送人
+func (l *approval) notifyCopyPersons(tx *gorm.DB, approval *model.Approval) error {
+	var uids []uint
+	if err := tx.Model(&model.ApprovalCopy{}).Where("approval_id = ?", approval.ID).Pluck("user_id", &uids).Error; err != nil {
+		return err
+	}
+	return notify(tx, model.Notification{
+		Type:    model.ApprovalCopyNotification,
+		Title:   fmt.Sprintf("%s（%s）", approval.Title, approval.Status.ToString()),
+		Content: approval.Abstract,
+		BizID:   approval.ID,
+	}, uids...)
 }
 
 func (l *approval) Dispose(ctx context.Context, req *domain.DisposeReq) (err error) {
@@ -380,71 +655,150 @@ func (l *approval) Dispose(ctx context.Context, req *domain.DisposeReq) (err err
 		return xerr.New(err)
 	}
 
+	status := model.ApprovalStatus(req.Status)
+	if status != model.Pass && status != model.Refuse {
+		return xerr.New(errors.New("invalid dispose status"))
+	}
+
 	// 1. Check if approval exists
 	approvalID, err := util.StringToUint(req.ApprovalId)
 	if err != nil {
 		return xerr.New(errors.New("invalid approval id"))
 	}
 
-	var approval model.Approval
-	if err := l.svcCtx.DB.WithContext(ctx).First(&approval, approvalID).Error; err != nil {
-		log.Error().Err(err).Str("approvalId", req.ApprovalId).Msg("failed to find approval for dispose")
-		return xerr.New(err)
-	}
+	return l.svcCtx.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 锁定审批单，避免同一步骤的审批人并发处理
+		var approval model.Approval
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&approval, approvalID).Error; err != nil {
+			log.Error().Err(err).Str("approvalId", req.ApprovalId).Msg("failed to find approval for dispose")
+			return xerr.New(err)
+		}
+		if approval.Status != model.Processed {
+			return xerr.New(errors.New("this request has already been finished"))
+		}
 
-	// 2. Find the approver record for current user using GORM query
-	var currentApprover model.Approver
-	if err := l.svcCtx.DB.WithContext(ctx).
-		Where("approval_id = ? AND user_id = ?", approvalID, userID).
-		First(&currentApprover).Error; err != nil {
-		return xerr.New(errors.New("you are not an approver for this request"))
-	}
+		var approvers []model.Approver
+		if err := tx.Where("approval_id = ?", approvalID).Order("step, id").Find(&approvers).Error; err != nil {
+			return xerr.New(err)
+		}
 
-	if currentApprover.Status != model.Processed {
-		return xerr.New(errors.New("you have already processed this request"))
-	}
+		// 2. 只有当前步骤的审批人可以处理
+		current, ok := model.CurrentStep(approvers)
+		if !ok {
+			return xerr.New(errors.New("this request has no pending approver"))
+		}
+		idx, participating := -1, false
+		for i, a := range approvers {
+			if a.UserID != userID {
+				continue
+			}
+			participating = true
+			if a.Step == current && a.Status == model.Processed {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			if !participating {
+				return xerr.New(errors.New("you are not an approver for this request"))
+			}
+			for _, a := range approvers {
+				if a.UserID == userID && a.Status == model.Processed && a.Step > current {
+					return xerr.New(errors.New("it is not your turn to approve this request"))
+				}
+			}
+			return xerr.New(errors.New("you have no pending approval on this request"))
+		}
 
-	// 3. Update approver status
-	currentApprover.Status = model.ApprovalStatus(req.Status)
-	currentApprover.Reason = req.Reason
-	if err := l.svcCtx.DB.WithContext(ctx).Save(&currentApprover).Error; err != nil {
-		log.Error().Err(err).Msg("failed to update approver status")
-		return xerr.New(err)
+		// 3. Update approver status
+		currentApprover := &approvers[idx]
+		currentApprover.Status = status
+		currentApprover.Reason = req.Reason
+		if err := tx.Save(currentApprover).Error; err != nil {
+			log.Error().Err(err).Msg("failed to update approver status")
+			return xerr.New(err)
+		}
+		action := model.ActionPass
+		if status == model.Refuse {
+			action = model.ActionRefuse
+		}
+		if err := tx.Create(&model.ApprovalLog{
+			ApprovalID: approval.ID,
+			ApproverID: currentApprover.ID,
+			UserID:     userID,
+			Action:     action,
+			Content:    req.Reason,
+		}).Error; err != nil {
+			log.Error().Err(err).Msg("failed to record approval timeline")
+			return xerr.New(err)
+		}
+
+		// 4. Update approval status using extracted method
+		if err := l.updateApprovalStatus(tx, &approval, approvers, status); err != nil {
+			log.Error().Err(err).Msg("failed to update approval status")
+			return xerr.New(err)
+		}
+		return nil
+	})
+}
+
+func (l *approval) BatchDispose(ctx context.Context, req *domain.BatchDisposeReq) (resp *domain.BatchDisposeResp, err error) {
+	if len(req.ApprovalIds) == 0 {
+		return nil, xerr.New(errors.New("approval ids must not be empty"))
+	}
+	if len(req.ApprovalIds) > maxBatchDispose {
+		return nil, xerr.New(fmt.Errorf("at most %d approvals can be disposed at once", maxBatchDispose))
+	}
+	status := model.ApprovalStatus(req.Status)
+	if status != model.Pass && status != model.Refuse {
+		return nil, xerr.New(errors.New("invalid dispose status"))
 	}
 
-	// 4. Update approval status using extracted method
-	if err := l.updateApprovalStatus(ctx, &approval, model.ApprovalStatus(req.Status)); err != nil {
-		return err
+	// 每个审批单单独处理，一个失败不影响其他审批单
+	resp = &domain.BatchDisposeResp{Results: make([]*domain.BatchDisposeResult, 0, len(req.ApprovalIds))}
+	seen := make(map[string]bool, len(req.ApprovalIds))
+	for _, id := range req.ApprovalIds {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		result := &domain.BatchDisposeResult{ApprovalId: id, Success: true}
+		if err := l.Dispose(ctx, &domain.DisposeReq{Status: req.Status, Reason: req.Reason, ApprovalId: id}); err != nil {
+			result.Success = false
+			result.Error = causeMessage(err)
+			resp.Failed++
+		} else {
+			resp.Succeeded++
+		}
+		resp.Results = append(resp.Results, result)
 	}
+	return resp, nil
+}
 
-	return nil
+// causeMessage 返回错误的根本原因，去掉 xerr 附加的调用位置
+func causeMessage(err error) string {
+	if c, ok := err.(interface{ Cause() error }); ok {
+		return c.Cause().Error()
+	}
+	return err.Error()
 }
 
 func (l *approval) List(ctx context.Context, req *domain.ApprovalListReq) (resp *domain.ApprovalListResp, err error) {
 	// 1. 处理分页参数
 	pagination := util.NormalizePagination(req.Page, req.Count)
 
-	// Force use current user ID from token to match AIWorkHelper behavior
+	// 只能查询与自己相关的审批单
 	uid, err := token.GetUserID(ctx)
-	if err == nil && uid > 0 {
-		req.UserId = strconv.Itoa(int(uid))
+	if err != nil {
+		return nil, xerr.New(err)
 	}
+	req.UserId = util.UintToString(uid)
 
 	// 2. 构建查询
-	db := l.svcCtx.DB.WithContext(ctx).Model(&model.Approval{})
-
-	// 过滤条件
-	if req.UserId != "" {
-		userId, _ := strconv.Atoi(req.UserId)
-		if userId > 0 {
-			// 查询我发起的 OR 我审批的
-			// Subquery for approvals where I am an approver
-			subQuery := l.svcCtx.DB.Model(&model.Approver{}).Select("approval_id").Where("user_id = ?", userId)
-			db = db.Where("user_id = ? OR id IN (?)", userId, subQuery)
-		}
-	}
-	if req.Type > 0 {
-		db = db.Where("type = ?", req.Type)
+	db, err := l.listQuery(ctx, uid, req)
+	if err != nil {
+		return nil, err
 	}
 
 	// 3. 查询总数
@@ -456,7 +810,7 @@ func (l *approval) List(ctx context.Context, req *domain.ApprovalListReq) (resp
 
 	// 4. 查询列表数据
 	var approvals []*model.Approval
-	if err = db.Preload("Approvers").Order("id desc").Offset(pagination.Offset).Limit(pagination.Count).Find(&approvals).Error; err != nil {
+	if err = db.Preload("User").Preload("Approvers").Order("id desc").Offset(pagination.Offset).Limit(pagination.Count).Find(&approvals).Error; err != nil {
 		log.Error().Err(err).Msg("failed to list approvals")
 		return nil, xerr.New(err)
 	}
@@ -464,16 +818,12 @@ func (l *approval) List(ctx context.Context, req *domain.ApprovalListReq) (resp
 	// 5. 组装响应
 	list := make([]*domain.ApprovalList, 0, len(approvals))
 	for _, v := range approvals {
+		// 当前步骤中等待我处理的审批节点
 		var participatingId string
-		if req.UserId != "" {
-			uid, _ := strconv.Atoi(req.UserId)
+		if step, pending := model.CurrentStep(v.Approvers); pending && v.Status == model.Processed {
 			for _, a := range v.Approvers {
-				if int(a.UserID) == uid {
-					// Assuming ParticipatingId refers to the Approver record ID or UserID?
-					// AIWorkHelper likely uses it to identify the 'task' for the user.
-					// Let's use Approver ID (which is unique for this user-approval pair).
-					// But wait, Approver ID is uint.
-					participatingId = strconv.Itoa(int(a.UserID))
+				if a.UserID == uid && a.Step == step && a.Status == model.Processed {
+					participatingId = util.UintToString(a.ID)
 					break
 				}
 			}
@@ -481,12 +831,15 @@ func (l *approval) List(ctx context.Context, req *domain.ApprovalListReq) (resp
 
 		list = append(list, &domain.ApprovalList{
 			Id:              strconv.Itoa(int(v.ID)),
+			No:              v.No,
 			Type:            int(v.Type),
 			Status:          int(v.Status),
 			Title:           v.Title,
 			Abstract:        v.Abstract,
 			CreateId:        strconv.Itoa(int(v.UserID)),
+			CreateName:      v.User.Name,
 			ParticipatingId: participatingId,
+			CreateAt:        v.CreatedAt.Unix(),
 		})
 	}
 
@@ -498,16 +851,129 @@ func (l *approval) List(ctx context.Context, req *domain.ApprovalListReq) (resp
 	return resp, nil
 }
 
-// GenRandomNo 生成指定位数的随机数字字符串
-// 使用时间戳的前 width 位作为审批单号
-func GenRandomNo(width int) string {
-	if width <= 0 {
-		width = 11
+func (l *approval) Inbox(ctx context.Context, req *domain.ApprovalListReq) (resp *domain.ApprovalInboxResp, err error) {
+	uid, err := token.GetUserID(ctx)
+	if err != nil {
+		return nil, xerr.New(err)
+	}
+	req.Scope = domain.ApprovalScopeTodo
+	list, err := l.List(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	tabs, err := l.tabCounts(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.ApprovalInboxResp{Count: list.Count, List: list.List, Tabs: tabs}, nil
+}
+
+func (l *approval) Counts(ctx context.Context) (resp *domain.ApprovalTabCounts, err error) {
+	uid, err := token.GetUserID(ctx)
+	if err != nil {
+		return nil, xerr.New(err)
+	}
+	return l.tabCounts(ctx, uid)
+}
+
+// tabCounts 统计审批列表各个页签的数量，用于显示角标
+func (l *approval) tabCounts(ctx context.Context, uid uint) (*domain.ApprovalTabCounts, error) {
+	tabs := &domain.ApprovalTabCounts{}
+	for scope, n := range map[string]*int64{
+		domain.ApprovalScopeTodo: &tabs.Todo,
+		domain.ApprovalScopeDone: &tabs.Done,
+		domain.ApprovalScopeCopy: &tabs.Cc,
+	} {
+		db, err := l.listQuery(ctx, uid, &domain.ApprovalListReq{Scope: scope})
+		if err != nil {
+			return nil, err
+		}
+		if err := db.Count(n).Error; err != nil {
+			log.Error().Err(err).Str("scope", scope).Msg("failed to count approvals")
+			return nil, xerr.New(err)
+		}
+	}
+	// 我发起的只统计审批中的
+	db, err := l.listQuery(ctx, uid, &domain.ApprovalListReq{Scope: domain.ApprovalScopeApplied, Status: []int{int(model.Processed)}})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Count(&tabs.Applied).Error; err != nil {
+		log.Error().Err(err).Msg("failed to count approvals")
+		return nil, xerr.New(err)
+	}
+	return tabs, nil
+}
+
+// pendingOnUser 查询当前步骤等待 uid 处理的审批单ID
+// 当前步骤之前的步骤都已完成：会签步骤没有待审批的人，或签步骤已有人通过；或签步骤已有他人通过时也不再等待 uid
+func pendingOnUser(db *gorm.DB, uid uint) *gorm.DB {
+	const stepPassed = `EXISTS (SELECT 1 FROM approvers p WHERE p.approval_id = %[1]s.approval_id AND p.step = %[1]s.step
+		AND p.status = @pass AND p.deleted_at IS NULL)`
+	return db.Table("approvers AS r").Select("r.approval_id").
+		Where("r.user_id = @uid AND r.status = @pending AND r.deleted_at IS NULL", map[string]any{
+			"uid": uid, "pending": model.Processed,
+		}).
+		Where(fmt.Sprintf("NOT (r.mode = @or AND %s)", fmt.Sprintf(stepPassed, "r")), map[string]any{
+			"or": model.AnyOfStep, "pass": model.Pass,
+		}).
+		Where(fmt.Sprintf(`NOT EXISTS (SELECT 1 FROM approvers e WHERE e.approval_id = r.approval_id AND e.step < r.step
+			AND e.status = @pending AND e.deleted_at IS NULL AND NOT (e.mode = @or AND %s))`, fmt.Sprintf(stepPassed, "e")), map[string]any{
+			"pending": model.Processed, "or": model.AnyOfStep, "pass": model.Pass,
+		})
+}
+
+// listQuery 按页签和筛选条件构建审批单查询
+func (l *approval) listQuery(ctx context.Context, uid uint, req *domain.ApprovalListReq) (*gorm.DB, error) {
+	db := l.svcCtx.DB.WithContext(ctx).Model(&model.Approval{})
+	sub := l.svcCtx.DB.Model(&model.Approver{}).Select("approval_id")
+
+	switch req.Scope {
+	case domain.ApprovalScopeApplied:
+		db = db.Where("user_id = ?", uid)
+	case domain.ApprovalScopeTodo:
+		db = db.Where("id IN (?) AND status = ?", pendingOnUser(l.svcCtx.DB, uid), model.Processed)
+	case domain.ApprovalScopeDone:
+		// 我已处理过的，包括委托给代理人处理的
+		db = db.Where("id IN (?)", sub.Where("(user_id = ? OR original_user_id = ?) AND status IN ?",
+			uid, uid, []model.ApprovalStatus{model.Pass, model.Refuse}))
+	case domain.ApprovalScopeCopy:
+		// 抄送给我的，只看已提交的审批单
+		copies := l.svcCtx.DB.Model(&model.ApprovalCopy{}).Select("approval_id").Where("user_id = ?", uid)
+		db = db.Where("id IN (?) AND status <> ?", copies, model.Draft)
+	case "":
+		// 我发起的和我审批的，不包括他人的草稿
+		db = db.Where("user_id = ? OR (id IN (?) AND status <> ?)",
+			uid, sub.Where("user_id = ? OR original_user_id = ?", uid, uid), model.Draft)
+	default:
+		return nil, xerr.New(fmt.Errorf("unknown approval scope %q", req.Scope))
+	}
+
+	if len(req.Status) > 0 {
+		db = db.Where("status IN ?", req.Status)
+	}
+	if req.ApplicantId != "" {
+		applicant, err := util.StringToUint(req.ApplicantId)
+		if err != nil {
+			return nil, xerr.New(errors.New("invalid applicant id"))
+		}
+		db = db.Where("user_id = ?", applicant)
+	}
+	if req.Type > 0 {
+		db = db.Where("type = ?", req.Type)
+	}
+	if req.FormId != "" {
+		formID, err := util.StringToUint(req.FormId)
+		if err != nil {
+			return nil, xerr.New(errors.New("invalid form id"))
+		}
+		db = db.Where("form_id = ?", formID)
+	}
+	if req.StartTime > 0 {
+		db = db.Where("created_at >= ?", time.Unix(req.StartTime, 0))
 	}
-	timestamp := fmt.Sprintf("%d", time.Now().UnixNano())
-	if len(timestamp) > width {
-		return timestamp[:width]
+	if req.EndTime > 0 {
+		db = db.Where("created_at <= ?", time.Unix(req.EndTime, 0))
 	}
-	// 如果时间戳长度不够，前面补0
-	return fmt.Sprintf("%0*d", width, 0) + timestamp
+	return db, nil
 }
@@ -0,0 +1,106 @@
+package logic
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"BackEnd/internal/domain"
+	"BackEnd/internal/model"
+)
+
+// toModelOvertime 校验加班申请并计算时长，时长按实际时间计算，不足半小时的部分不计
+func toModelOvertime(req *domain.Overtime) (*model.Overtime, error) {
+	if req.EndTime <= req.StartTime {
+		return nil, errors.New("end time must be after start time")
+	}
+	compensation := model.OvertimeCompensation(req.Compensation)
+	switch compensation {
+	case 0:
+		compensation = model.PayCompensation
+	case model.PayCompensation, model.RestCompensation:
+	default:
+		return nil, errors.New("invalid overtime compensation")
+	}
+	hours := math.Floor(float64(req.EndTime-req.StartTime)/3600*2) / 2
+	if hours <= 0 {
+		return nil, errors.New("overtime must be at least half an hour")
+	}
+	return &model.Overtime{
+		StartTime:    req.StartTime,
+		EndTime:      req.EndTime,
+		Duration:     float32(hours),
+		Compensation: compensation,
+		Reason:       req.Reason,
+	}, nil
+}
+
+// toModelBusinessTrip 校验出差申请，天数按出发到返回经过的自然日计算，目的地为空时取行程中的到达地
+func toModelBusinessTrip(req *domain.BusinessTrip, loc *time.Location) (*model.BusinessTrip, error) {
+	if req.EndTime <= req.StartTime {
+		return nil, errors.New("end time must be after start time")
+	}
+	if req.EstimatedCost < 0 {
+		return nil, errors.New("estimated cost must not be negative")
+	}
+	trip := &model.BusinessTrip{
+		StartTime:     req.StartTime,
+		EndTime:       req.EndTime,
+		EstimatedCost: req.EstimatedCost,
+		Reason:        req.Reason,
+	}
+	seen := make(map[string]bool)
+	addDestination := func(d string) {
+		if d = strings.TrimSpace(d); d != "" && !seen[d] {
+			seen[d] = true
+			trip.Destinations = append(trip.Destinations, d)
+		}
+	}
+	for _, d := range req.Destinations {
+		addDestination(d)
+	}
+	for _, seg := range req.Itinerary {
+		if seg == nil {
+			continue
+		}
+		if len(req.Destinations) == 0 {
+			addDestination(seg.To)
+		}
+		trip.Itinerary = append(trip.Itinerary, model.TripSegment{
+			Date:      seg.Date,
+			From:      strings.TrimSpace(seg.From),
+			To:        strings.TrimSpace(seg.To),
+			Transport: seg.Transport,
+		})
+	}
+	if len(trip.Destinations) == 0 {
+		return nil, errors.New("business trip needs at least one destination")
+	}
+
+	start, end := time.Unix(req.StartTime, 0).In(loc), time.Unix(req.EndTime, 0).In(loc)
+	startDay := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+	endDay := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, loc)
+	trip.Duration = float32(math.Round(endDay.Sub(startDay).Hours()/24) + 1)
+	return trip, nil
+}
+
+func toDomainBusinessTrip(trip *model.BusinessTrip) *domain.BusinessTrip {
+	res := &domain.BusinessTrip{
+		StartTime:     trip.StartTime,
+		EndTime:       trip.EndTime,
+		Duration:      trip.Duration,
+		Destinations:  trip.Destinations,
+		EstimatedCost: trip.EstimatedCost,
+		Reason:        trip.Reason,
+	}
+	for _, seg := range trip.Itinerary {
+		res.Itinerary = append(res.Itinerary, &domain.TripSegment{
+			Date:      seg.Date,
+			From:      seg.From,
+			To:        seg.To,
+			Transport: seg.Transport,
+		})
+	}
+	return res
+}
@@ -0,0 +1,172 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"BackEnd/internal/domain"
+	"BackEnd/internal/model"
+	"BackEnd/pkg/util"
+
+	"github.com/rs/zerolog/log"
+)
+
+// userDepartment 查找用户所属的层级最深的部门（ParentPath最长的）
+func (l *approval) userDepartment(ctx context.Context, userID uint) (*model.Department, error) {
+	var deptUsers []model.DepartmentUser
+	if err := l.svcCtx.DB.WithContext(ctx).Where("user_id = ?", userID).Find(&deptUsers).Error; err != nil {
+		return nil, err
+	}
+	if len(deptUsers) == 0 {
+		return nil, errors.New("用户未关联任何部门")
+	}
+
+	var deptIDs []uint
+	for _, du := range deptUsers {
+		deptIDs = append(deptIDs, du.DepartmentID)
+	}
+	var depts []model.Department
+	if err := l.svcCtx.DB.WithContext(ctx).Where("id IN ?", deptIDs).Find(&depts).Error; err != nil {
+		return nil, err
+	}
+
+	var target *model.Department
+	for i := range depts {
+		if target == nil || len(depts[i].ParentPath) > len(target.ParentPath) {
+			target = &depts[i]
+		}
+	}
+	if target == nil {
+		return nil, errors.New("未找到用户所属部门")
+	}
+	return target, nil
+}
+
+// parentLeaders 按层级从下到上返回上级部门负责人（从最近的上级到最远的上级），没有负责人的部门跳过
+func (l *approval) parentLeaders(ctx context.Context, dept *model.Department) []uint {
+	parentIds := model.ParseParentPath(dept.ParentPath)
+	if len(parentIds) == 0 {
+		return nil
+	}
+	var parentDepts []model.Department
+	if err := l.svcCtx.DB.WithContext(ctx).Where("id IN ?", parentIds).Find(&parentDepts).Error; err != nil {
+		log.Error().Err(err).Msg("failed to find parent departments")
+		return nil
+	}
+
+	var leaders []uint
+	for i := len(parentIds) - 1; i >= 0; i-- {
+		for _, pd := range parentDepts {
+			if pd.ID == parentIds[i] && pd.LeaderID > 0 {
+				leaders = append(leaders, pd.LeaderID)
+				break
+			}
+		}
+	}
+	return leaders
+}
+
+// approverResolver 将模板中的指定用户和角色解析为具体用户，申请人部门只在用到内置角色时查询一次
+// 解析结果不包含申请人，避免申请人审批自己的申请
+type approverResolver struct {
+	l      *approval
+	userID uint              // 申请人ID
+	dept   *model.Department // 申请人所属部门
+}
+
+func (r *approverResolver) resolve(ctx context.Context, userIds []uint, roles []string) ([]uint, error) {
+	ids := append([]uint{}, userIds...)
+	for _, role := range roles {
+		switch role {
+		case model.RoleLeader, model.RoleSuperior:
+			if r.dept == nil {
+				d, err := r.l.userDepartment(ctx, r.userID)
+				if err != nil {
+					return nil, err
+				}
+				r.dept = d
+			}
+			if role == model.RoleLeader {
+				ids = append(ids, r.dept.LeaderID)
+			} else if parents := r.l.parentLeaders(ctx, r.dept); len(parents) > 0 {
+				ids = append(ids, parents[0])
+			}
+		default:
+			var members []uint
+			if err := r.l.svcCtx.DB.WithContext(ctx).Model(&model.UserRole{}).
+				Where("role = ?", role).Order("id").Pluck("user_id", &members).Error; err != nil {
+				return nil, err
+			}
+			ids = append(ids, members...)
+		}
+	}
+	return slices.DeleteFunc(uniqueIDs(ids), func(id uint) bool { return id == r.userID }), nil
+}
+
+// buildApproversFromWorkflow 按流程模板构建审批人列表
+// 依次审批的步骤展开为多个连续步骤，会签、或签步骤的审批人共用同一个步骤
+func (l *approval) buildApproversFromWorkflow(ctx context.Context, w *model.ApprovalWorkflow, userID uint) ([]model.Approver, error) {
+	var (
+		approvers []model.Approver
+		step      int
+		resolver  = &approverResolver{l: l, userID: userID}
+	)
+	for i, s := range w.Steps {
+		ids, err := resolver.resolve(ctx, s.UserIds, s.Roles)
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) == 0 {
+			// 例如申请人已是最上级部门负责人时没有上级，或步骤的审批人只有申请人自己，跳过该步骤
+			log.Warn().Str("workflow", w.Name).Int("step", i+1).Msg("no approver resolved for workflow step, skipped")
+			continue
+		}
+
+		for _, uid := range ids {
+			a := model.Approver{UserID: uid, Status: model.Processed, Step: step}
+			if s.Mode == model.SequentialStep {
+				step++
+			} else {
+				a.Mode = s.Mode
+			}
+			approvers = append(approvers, a)
+		}
+		if s.Mode != model.SequentialStep {
+			step++
+		}
+	}
+
+	if len(approvers) == 0 {
+		return nil, fmt.Errorf("审批流程「%s」没有找到审批人", w.Name)
+	}
+	return approvers, nil
+}
+
+// buildCopyPersons 生成抄送人：指定的抄送人与流程模板配置的抄送人合并去重
+func (l *approval) buildCopyPersons(ctx context.Context, approval *model.Approval, reqCopyPersons []*domain.Approver, w *model.ApprovalWorkflow) ([]model.ApprovalCopy, error) {
+	var ids []uint
+	for _, c := range reqCopyPersons {
+		if uid := util.StringToUintSafe(c.UserId); uid > 0 {
+			ids = append(ids, uid)
+		}
+	}
+	if w != nil && (len(w.CopyUserIds) > 0 || len(w.CopyRoles) > 0) {
+		resolver := &approverResolver{l: l, userID: approval.UserID}
+		resolved, err := resolver.resolve(ctx, w.CopyUserIds, w.CopyRoles)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, resolved...)
+	}
+
+	var copies []model.ApprovalCopy
+	for _, uid := range uniqueIDs(ids) {
+		if uid == approval.UserID {
+			continue // 不抄送给申请人自己
+		}
+		copies = append(copies, model.ApprovalCopy{UserID: uid})
+	}
+	return copies, nil
+}
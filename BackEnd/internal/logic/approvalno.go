@@ -0,0 +1,56 @@
+package logic
+
+import (
+	"time"
+
+	"BackEnd/internal/config"
+	"BackEnd/internal/model"
+	"BackEnd/pkg/seqno"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultApprovalNoFormat 未配置审批单号格式时使用的格式
+const defaultApprovalNoFormat = "SP-{date}-{seq:4}"
+
+// approvalNoFormat 审批类型的单号格式，配置有误时使用默认格式
+func approvalNoFormat(c config.Config, typ model.ApprovalType) *seqno.Format {
+	pattern := c.ApprovalNo.Default
+	for _, r := range c.ApprovalNo.Rules {
+		if model.ApprovalType(r.Type) == typ {
+			pattern = r.Format
+			break
+		}
+	}
+	if pattern != "" {
+		f, err := seqno.Parse(pattern)
+		if err == nil {
+			return f
+		}
+		log.Error().Err(err).Int("type", int(typ)).Msg("invalid ApprovalNo format, using default format")
+	}
+	f, _ := seqno.Parse(defaultApprovalNoFormat)
+	return f
+}
+
+// nextApprovalNo 在事务中分配审批单号
+// 计数器行在事务提交前保持锁定，多个实例并发创建时依次取号，事务回滚时流水号也随之回滚
+func (l *approval) nextApprovalNo(tx *gorm.DB, typ model.ApprovalType) (string, error) {
+	f := approvalNoFormat(l.svcCtx.Config, typ)
+	now := time.Now().In(l.svcCtx.Location())
+	key := f.Key(now)
+
+	if err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.Assignments(map[string]any{"value": gorm.Expr("value + 1"), "updated_at": now}),
+	}).Create(&model.Sequence{Name: key, Value: 1}).Error; err != nil {
+		return "", err
+	}
+	var seq model.Sequence
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("name = ?", key).First(&seq).Error; err != nil {
+		return "", err
+	}
+	return f.Render(now, seq.Value)
+}
@@ -0,0 +1,248 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"BackEnd/internal/model"
+	"BackEnd/pkg/workcal"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 审批超时升级方式，见配置 ApprovalSLA.EscalateAction
+const (
+	escalateToSuperior = "superior" // 转交审批人的上级
+	escalatePass       = "pass"     // 自动通过
+	escalateRefuse     = "refuse"   // 自动驳回
+)
+
+func (l *approval) CheckSLA(ctx context.Context) (err error) {
+	sla := l.svcCtx.Config.ApprovalSLA
+	if sla.RemindHours <= 0 && sla.EscalateHours <= 0 {
+		return nil
+	}
+
+	var cal *workcal.Calendar
+	if sla.WorkHoursOnly {
+		if cal, err = loadWorkCalendar(ctx, l.svcCtx); err != nil {
+			return err
+		}
+	}
+
+	var ids []uint
+	if err := l.svcCtx.DB.WithContext(ctx).Model(&model.Approval{}).
+		Where("status = ?", model.Processed).Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, id := range ids {
+		err := l.svcCtx.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return l.checkApprovalSLA(ctx, tx, id, cal, now)
+		})
+		if err != nil {
+			log.Error().Err(err).Uint("approvalID", id).Msg("failed to check approval sla")
+		}
+	}
+	return nil
+}
+
+// elapsedHours 计算从 since 到 now 经过的小时数，cal 不为空时只计算工作时间
+func elapsedHours(cal *workcal.Calendar, since, now time.Time) float64 {
+	if cal != nil {
+		return cal.WorkHours(since, now)
+	}
+	return now.Sub(since).Hours()
+}
+
+// stepStartedAt 推算当前步骤开始等待的时间：前面步骤最后一次处理的时间，第一个步骤为提交时间
+func stepStartedAt(approvers []model.Approver, step int) time.Time {
+	var since time.Time
+	for _, a := range approvers {
+		if since.IsZero() || a.CreatedAt.Before(since) {
+			since = a.CreatedAt
+		}
+	}
+	for _, a := range approvers {
+		if a.Step < step && a.Status != model.Processed && a.UpdatedAt.After(since) {
+			since = a.UpdatedAt
+		}
+	}
+	return since
+}
+
+// checkApprovalSLA 检查审批单当前步骤的待审批人，超时提醒或升级
+func (l *approval) checkApprovalSLA(ctx context.Context, tx *gorm.DB, id uint, cal *workcal.Calendar, now time.Time) error {
+	sla := l.svcCtx.Config.ApprovalSLA
+
+	var approval model.Approval
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&approval, id).Error; err != nil {
+		return err
+	}
+	if approval.Status != model.Processed {
+		return nil
+	}
+	var approvers []model.Approver
+	if err := tx.Where("approval_id = ?", id).Order("step, id").Find(&approvers).Error; err != nil {
+		return err
+	}
+	step, ok := model.CurrentStep(approvers)
+	if !ok {
+		return nil
+	}
+	started := stepStartedAt(approvers, step)
+
+	for i := range approvers {
+		a := &approvers[i]
+		if a.Step != step || a.Status != model.Processed {
+			continue
+		}
+		// 升级后的审批人从转交时开始计时
+		since := started
+		if a.EscalatedAt != nil {
+			since = *a.EscalatedAt
+		}
+		elapsed := elapsedHours(cal, since, now)
+
+		if sla.EscalateHours > 0 && elapsed >= sla.EscalateHours && a.EscalatedAt == nil {
+			finished, err := l.escalate(ctx, tx, &approval, approvers, i, elapsed, now)
+			if err != nil {
+				return err
+			}
+			if finished {
+				return nil
+			}
+			continue
+		}
+
+		if sla.RemindHours > 0 && elapsed >= sla.RemindHours &&
+			(a.RemindedAt == nil || sla.RemindInterval > 0 && elapsedHours(cal, *a.RemindedAt, now) >= sla.RemindInterval) {
+			if err := l.remind(tx, &approval, a, elapsed, now); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// remind 提醒审批人处理超时的审批单
+func (l *approval) remind(tx *gorm.DB, approval *model.Approval, a *model.Approver, elapsed float64, now time.Time) error {
+	if err := tx.Model(&model.Approver{}).Where("id = ?", a.ID).Update("reminded_at", now).Error; err != nil {
+		return err
+	}
+	a.RemindedAt = &now
+
+	content := fmt.Sprintf("审批已等待 %.1f 小时，提醒审批人尽快处理", elapsed)
+	if err := tx.Create(&model.ApprovalLog{
+		ApprovalID: approval.ID,
+		ApproverID: a.ID,
+		TargetID:   a.UserID,
+		Action:     model.ActionRemind,
+		Content:    content,
+	}).Error; err != nil {
+		return err
+	}
+	return notify(tx, model.Notification{
+		Type:    model.ApprovalRemindNotification,
+		Title:   fmt.Sprintf("待审批提醒：%s", approval.Title),
+		Content: content,
+		BizID:   approval.ID,
+	}, a.UserID)
+}
+
+// escalate 升级超时的审批节点：转交上级或按配置自动通过、驳回，返回审批单是否已结束
+func (l *approval) escalate(ctx context.Context, tx *gorm.DB, approval *model.Approval, approvers []model.Approver, idx int, elapsed float64, now time.Time) (finished bool, err error) {
+	a := &approvers[idx]
+	a.EscalatedAt = &now
+
+	switch l.svcCtx.Config.ApprovalSLA.EscalateAction {
+	case escalatePass, escalateRefuse:
+		status, action, reason := model.Pass, model.ActionAutoPass, "审批超时，系统自动通过"
+		if l.svcCtx.Config.ApprovalSLA.EscalateAction == escalateRefuse {
+			status, action, reason = model.Refuse, model.ActionAutoRefuse, "审批超时，系统自动驳回"
+		}
+		a.Status, a.Reason = status, reason
+		if err := tx.Model(&model.Approver{}).Where("id = ?", a.ID).Updates(map[string]any{
+			"status": status, "reason": reason, "escalated_at": now,
+		}).Error; err != nil {
+			return false, err
+		}
+		if err := tx.Create(&model.ApprovalLog{
+			ApprovalID: approval.ID,
+			ApproverID: a.ID,
+			TargetID:   a.UserID,
+			Action:     action,
+			Content:    fmt.Sprintf("审批已等待 %.1f 小时，%s", elapsed, reason),
+		}).Error; err != nil {
+			return false, err
+		}
+		if err := l.updateApprovalStatus(tx, approval, approvers, status); err != nil {
+			return false, err
+		}
+		return approval.Status != model.Processed, nil
+	}
+
+	// 默认转交上级，找不到上级时只记录，不再重复升级
+	superior := l.superiorOf(ctx, a.UserID, approval.UserID, approvers, a.Step)
+	updates := map[string]any{"escalated_at": now}
+	content := fmt.Sprintf("审批已等待 %.1f 小时，未找到可转交的上级", elapsed)
+	if superior > 0 {
+		original := a.UserID
+		if a.OriginalUserID > 0 {
+			original = a.OriginalUserID
+		}
+		updates["user_id"] = superior
+		updates["original_user_id"] = original
+		updates["reminded_at"] = nil
+		content = fmt.Sprintf("审批已等待 %.1f 小时，转交上级审批", elapsed)
+	}
+	if err := tx.Model(&model.Approver{}).Where("id = ?", a.ID).Updates(updates).Error; err != nil {
+		return false, err
+	}
+	if err := tx.Create(&model.ApprovalLog{
+		ApprovalID: approval.ID,
+		ApproverID: a.ID,
+		TargetID:   superior,
+		Action:     model.ActionEscalate,
+		Content:    content,
+	}).Error; err != nil {
+		return false, err
+	}
+	if superior == 0 {
+		return false, nil
+	}
+	return false, notify(tx, model.Notification{
+		Type:    model.ApprovalEscalateNotification,
+		Title:   fmt.Sprintf("超时转交审批：%s", approval.Title),
+		Content: approval.Abstract,
+		BizID:   approval.ID,
+	}, superior)
+}
+
+// superiorOf 按部门层级查找审批人的上级：所在部门负责人，审批人本身是负责人时为上级部门负责人
+// 跳过申请人和当前步骤中已在审批的人，没有合适的上级时返回 0
+func (l *approval) superiorOf(ctx context.Context, uid, applicant uint, approvers []model.Approver, step int) uint {
+	dept, err := l.userDepartment(ctx, uid)
+	if err != nil {
+		log.Error().Err(err).Uint("userID", uid).Msg("failed to find approver department")
+		return 0
+	}
+
+	skip := map[uint]bool{0: true, uid: true, applicant: true}
+	for _, a := range approvers {
+		if a.Step == step && a.Status == model.Processed {
+			skip[a.UserID] = true
+		}
+	}
+	candidates := append([]uint{dept.LeaderID}, l.parentLeaders(ctx, dept)...)
+	for _, c := range candidates {
+		if !skip[c] {
+			return c
+		}
+	}
+	return 0
+}
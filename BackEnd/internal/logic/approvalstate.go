@@ -0,0 +1,172 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"BackEnd/internal/domain"
+	"BackEnd/internal/model"
+	"BackEnd/pkg/token"
+	"BackEnd/pkg/util"
+	"BackEnd/pkg/xerr"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// approvalTransitions 审批单状态机：当前状态 -> 允许转换到的状态
+// 已驳回、已撤销为终态，只能通过 Resubmit 生成新的修订版本；已通过的请假可以由申请人撤销（销假）
+var approvalTransitions = map[model.ApprovalStatus][]model.ApprovalStatus{
+	model.Draft:     {model.Draft, model.Processed},           // 编辑草稿、提交审批
+	model.Processed: {model.Pass, model.Refuse, model.Cancel}, // 审批通过、驳回、申请人撤回
+	model.Pass:      {model.Cancel},                           // 销假，仅限请假审批
+}
+
+// resubmittable 可以重新提交的审批单状态
+var resubmittable = map[model.ApprovalStatus]bool{
+	model.Refuse: true,
+	model.Cancel: true,
+}
+
+// checkTransition 校验审批单状态转换是否合法
+func checkTransition(from, to model.ApprovalStatus) error {
+	for _, s := range approvalTransitions[from] {
+		if s == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot change approval from %s to %s", from.ToString(), to.ToString())
+}
+
+// applicantApproval 查询当前用户作为申请人的审批单
+func (l *approval) applicantApproval(ctx context.Context, id string) (*model.Approval, error) {
+	userID, err := token.GetUserID(ctx)
+	if err != nil {
+		return nil, xerr.New(err)
+	}
+	approvalID, err := util.StringToUint(id)
+	if err != nil {
+		return nil, xerr.New(errors.New("invalid approval id"))
+	}
+
+	var approval model.Approval
+	if err := l.svcCtx.DB.WithContext(ctx).First(&approval, approvalID).Error; err != nil {
+		log.Error().Err(err).Str("approvalId", id).Msg("failed to find approval")
+		return nil, xerr.New(err)
+	}
+	if approval.UserID != userID {
+		return nil, xerr.New(errors.New("only the applicant can change this request"))
+	}
+	return &approval, nil
+}
+
+func (l *approval) Withdraw(ctx context.Context, req *domain.ApprovalActionReq) (err error) {
+	approval, err := l.applicantApproval(ctx, req.ApprovalId)
+	if err != nil {
+		return err
+	}
+	if err := checkTransition(approval.Status, model.Cancel); err != nil {
+		return xerr.New(err)
+	}
+	if approval.Status == model.Pass && approval.Type != model.LeaveApproval {
+		return xerr.New(errors.New("only approved leave requests can be cancelled"))
+	}
+
+	from := approval.Status
+	return l.svcCtx.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 仅在状态未被审批人改变时撤回，避免与审批人的处理并发冲突
+		res := tx.Model(approval).
+			Where("status = ?", from).
+			Update("status", model.Cancel)
+		if res.Error != nil {
+			log.Error().Err(res.Error).Msg("failed to withdraw approval")
+			return xerr.New(res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return xerr.New(errors.New("this request has already been finished"))
+		}
+		action := model.ActionWithdraw
+		if from == model.Pass {
+			action = model.ActionCancel
+		}
+		if err := addApprovalLog(tx, approval.ID, approval.UserID, action, ""); err != nil {
+			log.Error().Err(err).Msg("failed to record approval timeline")
+			return xerr.New(err)
+		}
+		// 解冻或返还假期余额
+		if err := settleLeave(tx, approval, from, model.Cancel); err != nil {
+			log.Error().Err(err).Msg("failed to settle leave balance")
+			return xerr.New(err)
+		}
+		return nil
+	})
+}
+
+func (l *approval) SaveDraft(ctx context.Context, req *domain.Approval) (err error) {
+	approval, err := l.applicantApproval(ctx, req.Id)
+	if err != nil {
+		return err
+	}
+	if err := checkTransition(approval.Status, model.Draft); err != nil {
+		return xerr.New(err)
+	}
+	// 草稿不能修改审批类型
+	req.Type = int(approval.Type)
+	if err := l.fillApproval(ctx, approval, req); err != nil {
+		return err
+	}
+	return l.saveApproval(ctx, approval, model.Draft, nil, nil)
+}
+
+func (l *approval) Submit(ctx context.Context, req *domain.ApprovalSubmitReq) (err error) {
+	approval, err := l.applicantApproval(ctx, req.ApprovalId)
+	if err != nil {
+		return err
+	}
+	if err := checkTransition(approval.Status, model.Processed); err != nil {
+		return xerr.New(err)
+	}
+	return l.saveApproval(ctx, approval, model.Processed, req.Approvers, req.CopyPersons)
+}
+
+func (l *approval) Resubmit(ctx context.Context, req *domain.Approval) (resp *domain.IdResp, err error) {
+	original, err := l.applicantApproval(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if !resubmittable[original.Status] {
+		return nil, xerr.New(fmt.Errorf("cannot resubmit a request that is %s", original.Status.ToString()))
+	}
+
+	// 每个审批单只能重新提交一次，后续修改应基于最新的修订版本
+	var count int64
+	if err := l.svcCtx.DB.WithContext(ctx).Model(&model.Approval{}).Where("parent_id = ?", original.ID).Count(&count).Error; err != nil {
+		return nil, xerr.New(err)
+	}
+	if count > 0 {
+		return nil, xerr.New(errors.New("this request has already been resubmitted"))
+	}
+
+	status := model.Processed
+	if model.ApprovalStatus(req.Status) == model.Draft {
+		status = model.Draft
+	}
+
+	approval := &model.Approval{
+		UserID:   original.UserID,
+		ParentID: original.ID,
+		Revision: original.Revision + 1,
+	}
+	req.Type = int(original.Type)
+	if req.FormId == "" && req.FormCode == "" && original.FormID > 0 {
+		req.FormId = util.UintToString(original.FormID)
+	}
+	if err := l.fillApproval(ctx, approval, req); err != nil {
+		return nil, err
+	}
+	if err := l.saveApproval(ctx, approval, status, req.Approvers, req.CopyPersons); err != nil {
+		return nil, err
+	}
+	return &domain.IdResp{Id: util.UintToString(approval.ID)}, nil
+}
@@ -0,0 +1,196 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"BackEnd/internal/domain"
+	"BackEnd/internal/model"
+	"BackEnd/pkg/token"
+	"BackEnd/pkg/util"
+	"BackEnd/pkg/xerr"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// maxCommentLength 评论内容的最大字符数，与 ApprovalLog.Content 的长度一致
+const maxCommentLength = 512
+
+// addApprovalLog 在审批时间线上记录一条操作
+func addApprovalLog(tx *gorm.DB, approvalID, userID uint, action model.ApprovalAction, content string) error {
+	return tx.Create(&model.ApprovalLog{
+		ApprovalID: approvalID,
+		UserID:     userID,
+		Action:     action,
+		Content:    content,
+	}).Error
+}
+
+func (l *approval) Comment(ctx context.Context, req *domain.ApprovalCommentReq) (err error) {
+	uid, err := token.GetUserID(ctx)
+	if err != nil {
+		return xerr.New(err)
+	}
+	approvalID, err := util.StringToUint(req.ApprovalId)
+	if err != nil {
+		return xerr.New(errors.New("invalid approval id"))
+	}
+	content := strings.TrimSpace(req.Content)
+	if content == "" && len(req.Attachments) == 0 {
+		return xerr.New(errors.New("comment must not be empty"))
+	}
+	if utf8.RuneCountInString(content) > maxCommentLength {
+		return xerr.New(fmt.Errorf("comment must be at most %d characters", maxCommentLength))
+	}
+	if err := l.checkAttachments(req.Attachments); err != nil {
+		return xerr.New(err)
+	}
+
+	var approval model.Approval
+	if err := l.svcCtx.DB.WithContext(ctx).Preload("Approvers").First(&approval, approvalID).Error; err != nil {
+		return xerr.New(err)
+	}
+	if approval.Status == model.Draft {
+		return xerr.New(errors.New("cannot comment on a draft"))
+	}
+	ok, err := l.isParticipant(ctx, &approval, uid)
+	if err != nil {
+		return xerr.New(err)
+	}
+	if !ok {
+		return xerr.New(errors.New("only participants of this request can comment"))
+	}
+
+	// 通知申请人和当前待审批的人
+	receivers := []uint{approval.UserID}
+	if step, pending := model.CurrentStep(approval.Approvers); pending && approval.Status == model.Processed {
+		for _, a := range approval.Approvers {
+			if a.Step == step && a.Status == model.Processed {
+				receivers = append(receivers, a.UserID)
+			}
+		}
+	}
+	receivers = removeID(receivers, uid)
+
+	return l.svcCtx.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&model.ApprovalLog{
+			ApprovalID:  approval.ID,
+			UserID:      uid,
+			Action:      model.ActionComment,
+			Content:     content,
+			Attachments: req.Attachments,
+		}).Error; err != nil {
+			log.Error().Err(err).Msg("failed to create approval comment")
+			return xerr.New(err)
+		}
+		return notify(tx, model.Notification{
+			Type:    model.ApprovalCommentNotification,
+			Title:   fmt.Sprintf("审批评论：%s", approval.Title),
+			Content: content,
+			BizID:   approval.ID,
+		}, receivers...)
+	})
+}
+
+// isParticipant 判断用户是否参与了审批单：申请人、审批人（含委托前的原审批人）、抄送人或管理员
+func (l *approval) isParticipant(ctx context.Context, approval *model.Approval, uid uint) (bool, error) {
+	if approval.UserID == uid {
+		return true, nil
+	}
+	db := l.svcCtx.DB.WithContext(ctx)
+	var count int64
+	if err := db.Model(&model.Approver{}).
+		Where("approval_id = ? AND (user_id = ? OR original_user_id = ?)", approval.ID, uid, uid).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count == 0 {
+		if err := db.Model(&model.ApprovalCopy{}).
+			Where("approval_id = ? AND user_id = ?", approval.ID, uid).Count(&count).Error; err != nil {
+			return false, err
+		}
+	}
+	if count == 0 {
+		var user model.User
+		if err := db.Select("id", "is_admin").First(&user, uid).Error; err != nil {
+			return false, err
+		}
+		return user.IsAdmin, nil
+	}
+	return true, nil
+}
+
+// checkAttachments 附件必须是通过上传接口保存的文件，即位于上传目录下，上传目录未配置时不允许添加附件
+func (l *approval) checkAttachments(files []string) error {
+	for _, f := range files {
+		if f == "" {
+			return errors.New("invalid attachment, upload the file first")
+		}
+		if _, err := resolveUploadPath(l.svcCtx.Config.Upload.SavePath, f); err != nil {
+			return fmt.Errorf("invalid attachment %q, upload the file first: %w", filepath.Base(f), err)
+		}
+	}
+	return nil
+}
+
+// recordView 记录用户首次查看审批单，申请人查看不记录
+func (l *approval) recordView(ctx context.Context, approval *model.Approval, uid uint) {
+	if uid == 0 || uid == approval.UserID {
+		return
+	}
+	db := l.svcCtx.DB.WithContext(ctx)
+	var count int64
+	if err := db.Model(&model.ApprovalLog{}).
+		Where("approval_id = ? AND user_id = ? AND action = ?", approval.ID, uid, model.ActionView).
+		Count(&count).Error; err != nil || count > 0 {
+		return
+	}
+	if err := addApprovalLog(db, approval.ID, uid, model.ActionView, ""); err != nil {
+		log.Error().Err(err).Msg("failed to record approval view")
+	}
+}
+
+// timeline 查询审批时间线
+func (l *approval) timeline(ctx context.Context, approvalID uint) ([]*domain.ApprovalLog, error) {
+	var logs []*model.ApprovalLog
+	if err := l.svcCtx.DB.WithContext(ctx).Preload("User").Preload("Target").
+		Where("approval_id = ?", approvalID).Order("id").Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	list := make([]*domain.ApprovalLog, 0, len(logs))
+	for _, e := range logs {
+		item := &domain.ApprovalLog{
+			Id:          util.UintToString(e.ID),
+			Action:      string(e.Action),
+			UserName:    e.User.Name,
+			Content:     e.Content,
+			Attachments: e.Attachments,
+			CreateAt:    e.CreatedAt.Unix(),
+		}
+		if e.UserID > 0 {
+			item.UserId = util.UintToString(e.UserID)
+		}
+		if e.TargetID > 0 {
+			item.TargetId = util.UintToString(e.TargetID)
+			item.TargetName = e.Target.Name
+		}
+		list = append(list, item)
+	}
+	return list, nil
+}
+
+// removeID 从 ids 中去掉 id
+func removeID(ids []uint, id uint) []uint {
+	out := ids[:0]
+	for _, v := range ids {
+		if v != id {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,200 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"BackEnd/internal/config"
+	"BackEnd/internal/domain"
+	"BackEnd/internal/model"
+	"BackEnd/internal/svc"
+	"BackEnd/pkg/workcal"
+	"BackEnd/pkg/xerr"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Calendar 工作日历：节假日、调休上班和工作时长计算
+type Calendar interface {
+	// List 查询某年的节假日和调休上班安排
+	List(ctx context.Context, req *domain.CalendarListReq) (resp *domain.CalendarListResp, err error)
+	// Import 从 CSV 或 JSON 文件导入节假日安排（管理员），format 为文件扩展名
+	Import(ctx context.Context, r io.Reader, format string, replace bool) (resp *domain.CalendarImportResp, err error)
+	// Duration 按工作日历计算请假、外出时长
+	Duration(ctx context.Context, req *domain.CalendarDurationReq) (resp *domain.CalendarDurationResp, err error)
+}
+
+type calendar struct {
+	svcCtx *svc.ServiceContext
+}
+
+func NewCalendar(svcCtx *svc.ServiceContext) Calendar {
+	return &calendar{
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *calendar) List(ctx context.Context, req *domain.CalendarListReq) (resp *domain.CalendarListResp, err error) {
+	year := req.Year
+	if year == 0 {
+		year = time.Now().In(l.svcCtx.Location()).Year()
+	}
+
+	var days []*model.CalendarDay
+	if err := l.svcCtx.DB.WithContext(ctx).
+		Where("date LIKE ?", fmt.Sprintf("%d-%%", year)).
+		Order("date").Find(&days).Error; err != nil {
+		log.Error().Err(err).Msg("failed to list calendar days")
+		return nil, xerr.New(err)
+	}
+
+	resp = &domain.CalendarListResp{
+		WorkHours: workPeriodSpecs(l.svcCtx.Config),
+		List:      make([]*domain.CalendarDay, 0, len(days)),
+	}
+	for _, d := range days {
+		resp.List = append(resp.List, &domain.CalendarDay{Date: d.Date, Kind: d.Kind, Name: d.Name})
+	}
+	return resp, nil
+}
+
+func (l *calendar) Import(ctx context.Context, r io.Reader, format string, replace bool) (resp *domain.CalendarImportResp, err error) {
+	if err := checkAdmin(ctx, l.svcCtx); err != nil {
+		return nil, err
+	}
+
+	days, err := workcal.ParseDays(r, format)
+	if err != nil {
+		return nil, xerr.New(err)
+	}
+	if len(days) == 0 {
+		return nil, xerr.New(errors.New("no calendar days in file"))
+	}
+
+	years := make(map[string]bool)
+	rows := make([]model.CalendarDay, 0, len(days))
+	for _, d := range days {
+		years[d.Date[:4]] = true
+		rows = append(rows, model.CalendarDay{Date: d.Date, Kind: int(d.Kind), Name: d.Name})
+	}
+
+	err = l.svcCtx.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 覆盖导入时先清除文件涉及年份的原有安排
+		if replace {
+			for y := range years {
+				if err := tx.Unscoped().Where("date LIKE ?", y+"-%").Delete(&model.CalendarDay{}).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "date"}},
+			DoUpdates: clause.AssignmentColumns([]string{"kind", "name", "updated_at", "deleted_at"}),
+		}).CreateInBatches(&rows, 100).Error
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("failed to import calendar")
+		return nil, xerr.New(err)
+	}
+	return &domain.CalendarImportResp{Imported: len(rows)}, nil
+}
+
+func (l *calendar) Duration(ctx context.Context, req *domain.CalendarDurationReq) (resp *domain.CalendarDurationResp, err error) {
+	cal, err := loadWorkCalendar(ctx, l.svcCtx)
+	if err != nil {
+		return nil, xerr.New(err)
+	}
+	start, end, duration, err := workDuration(cal, req.StartTime, req.EndTime, model.TimeFormatType(req.TimeType))
+	if err != nil {
+		return nil, xerr.New(err)
+	}
+	return &domain.CalendarDurationResp{StartTime: start, EndTime: end, Duration: duration}, nil
+}
+
+// workPeriods 读取配置的工作时段，配置有误时使用默认时段
+func workPeriods(c config.Config) []workcal.Period {
+	periods, err := workcal.ParsePeriods(c.Calendar.WorkHours)
+	if err != nil {
+		log.Error().Err(err).Msg("invalid Calendar.WorkHours, using default work hours")
+		return nil
+	}
+	return periods
+}
+
+// workPeriodSpecs 返回生效中的工作时段，格式为 09:00-12:00
+func workPeriodSpecs(c config.Config) []string {
+	periods := workPeriods(c)
+	if len(periods) == 0 {
+		periods = workcal.DefaultPeriods
+	}
+	specs := make([]string, 0, len(periods))
+	for _, p := range periods {
+		start, end := time.Time{}.Add(p.Start), time.Time{}.Add(p.End)
+		specs = append(specs, start.Format("15:04")+"-"+end.Format("15:04"))
+	}
+	return specs
+}
+
+// workWeekend 读取配置的周末，未配置时返回 nil 使用默认周末
+func workWeekend(c config.Config) []time.Weekday {
+	if len(c.Calendar.Weekend) == 0 {
+		return nil
+	}
+	weekend := make([]time.Weekday, 0, len(c.Calendar.Weekend))
+	for _, w := range c.Calendar.Weekend {
+		weekend = append(weekend, time.Weekday(w%7))
+	}
+	return weekend
+}
+
+// hoursPerDay 每个工作日的工作小时数
+func hoursPerDay(c config.Config) float64 {
+	return workcal.New(nil, workPeriods(c), nil, nil).HoursPerDay()
+}
+
+// loadWorkCalendar 加载工作日历，包括导入的节假日和调休上班
+func loadWorkCalendar(ctx context.Context, svcCtx *svc.ServiceContext) (*workcal.Calendar, error) {
+	var rows []model.CalendarDay
+	if err := svcCtx.DB.WithContext(ctx).Find(&rows).Error; err != nil {
+		log.Error().Err(err).Msg("failed to load calendar days")
+		return nil, err
+	}
+	days := make([]workcal.Day, 0, len(rows))
+	for _, r := range rows {
+		days = append(days, workcal.Day{Date: r.Date, Kind: workcal.DayKind(r.Kind), Name: r.Name})
+	}
+	return workcal.New(svcCtx.Location(), workPeriods(svcCtx.Config), workWeekend(svcCtx.Config), days), nil
+}
+
+// workDuration 按工作日历计算时长，返回调整后的起止时间
+// 小时按工作时段计算；天、半天以天为单位，最小单位半天；上半天、下半天取开始日期对应的半天
+func workDuration(cal *workcal.Calendar, startTime, endTime int64, timeType model.TimeFormatType) (start, end int64, duration float32, err error) {
+	s, e := time.Unix(startTime, 0), time.Unix(endTime, 0)
+	switch timeType {
+	case model.MorningTimeFormatType, model.AfternoonTimeFormatType:
+		if !cal.IsWorkday(s) {
+			return 0, 0, 0, fmt.Errorf("%s is not a workday", s.Format(time.DateOnly))
+		}
+		s, e = cal.HalfDay(s, timeType == model.AfternoonTimeFormatType)
+		return s.Unix(), e.Unix(), 0.5, nil
+	}
+
+	if !e.After(s) {
+		return 0, 0, 0, errors.New("end time must be after start time")
+	}
+	var v float64
+	if timeType == model.HourTimeFormatType {
+		v = cal.WorkHours(s, e)
+	} else {
+		v = cal.WorkDays(s, e)
+	}
+	if v == 0 {
+		return 0, 0, 0, errors.New("the requested period contains no working time")
+	}
+	return startTime, endTime, float32(v), nil
+}
@@ -71,7 +71,7 @@ func NewChat(svcCtx *svc.ServiceContext) Chat {
 		departmentHandle := chatinternal.NewDepartmentHandle(svcCtx, deptLogic)
 
 		approvalLogic := NewApproval(svcCtx)
-		approvalHandle := chatinternal.NewApprovalHandle(svcCtx, approvalLogic)
+		approvalHandle := chatinternal.NewApprovalHandle(svcCtx, approvalLogic, NewLeave(svcCtx))
 
 		// Inject knowledge handler
 		knowledgeHandle := chatinternal.NewKnowledge(svcCtx)
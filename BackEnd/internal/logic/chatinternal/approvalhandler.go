@@ -21,11 +21,12 @@ type ApprovalHandle struct {
 }
 
 // NewApprovalHandle now accepts the local interface
-func NewApprovalHandle(svc *svc.ServiceContext, l ApprovalLogic) *ApprovalHandle {
+func NewApprovalHandle(svc *svc.ServiceContext, l ApprovalLogic, leave toolx.LeaveBalanceLogic) *ApprovalHandle {
 	return &ApprovalHandle{
 		AgentChat: NewAgentChat(svc, []tools.Tool{
 			toolx.NewApprovalAdd(svc, l),
 			toolx.NewApprovalFind(svc, l),
+			toolx.NewLeaveBalance(svc, leave),
 		}),
 	}
 }
@@ -35,5 +36,5 @@ func (t *ApprovalHandle) Name() string {
 }
 
 func (t *ApprovalHandle) Description() string {
-	return "suitable for approval processing, such as applying for leave, overtime, go out, or querying approval records."
+	return "suitable for approval processing, such as applying for leave, overtime, go out, business trip, querying approval records, or checking remaining leave balance."
 }
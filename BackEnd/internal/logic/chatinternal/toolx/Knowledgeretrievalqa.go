@@ -3,21 +3,47 @@ package toolx
 import (
 	"BackEnd/internal/svc"
 	"context"
+	"path/filepath"
+	"strings"
+
 	"github.com/tmc/langchaingo/callbacks"
 	"github.com/tmc/langchaingo/chains"
-	"github.com/tmc/langchaingo/vectorstores"
-	"github.com/tmc/langchaingo/vectorstores/redisvector"
+	"github.com/tmc/langchaingo/prompts"
+	"github.com/tmc/langchaingo/schema"
 )
 
+// KnowledgeSourcesPrefix 知识库回答中引用来源所在行的前缀，多个来源以“、”分隔
+const KnowledgeSourcesPrefix = "参考来源："
+
+// KnowledgeSourceLabel 检索内容中标注来源文件的前缀，大模型据此写出引用来源
+const KnowledgeSourceLabel = "来源："
+
+// knowledgeQATemplate 要求大模型在回答最后列出实际引用的来源，引用由模型给出而不是由检索结果拼接
+const knowledgeQATemplate = `Use the following pieces of context to answer the question at the end. Each piece starts with its source file. If you don't know the answer, just say that you don't know, don't try to make up an answer.
+After the answer, add a final line listing the source files you actually used, in the format: ` + KnowledgeSourcesPrefix + `文件名1、文件名2. Leave it out if you used none.
+
+{{.context}}
+
+Question: {{.question}}
+Helpful Answer:`
+
 type KnowledgeRetrievalQA struct {
 	svc      *svc.ServiceContext
 	Callback callbacks.Handler
-	store    *redisvector.Store
 	qa       chains.Chain
 }
 
 func NewKnowledgeRetrievalQA(svc *svc.ServiceContext) *KnowledgeRetrievalQA {
-	return &KnowledgeRetrievalQA{svc: svc}
+	// 关键词 + 向量混合检索，弥补纯向量检索对专有名词、表单编号不敏感的问题
+	// 检索链在创建时构建，工具实例在并发的请求间共享
+	prompt := prompts.NewPromptTemplate(knowledgeQATemplate, []string{"context", "question"})
+	return &KnowledgeRetrievalQA{
+		svc: svc,
+		qa: chains.NewRetrievalQA(
+			chains.NewStuffDocuments(chains.NewLLMChain(svc.LLMs, prompt)),
+			sourceRetriever{NewKnowledgeSearcher(svc)},
+		),
+	}
 }
 
 func (k *KnowledgeRetrievalQA) Name() string {
@@ -31,23 +57,52 @@ keep Chinese output.`
 }
 
 func (k *KnowledgeRetrievalQA) Call(ctx context.Context, input string) (string, error) {
-	var err error
-	if k.qa == nil {
-		k.store, err = getKnowledgeStore(ctx, k.svc)
-		if err != nil {
-			return "", err
-		}
-
-		k.qa = chains.NewRetrievalQAFromLLM(k.svc.LLMs, vectorstores.ToRetriever(k.store, 1))
-	}
-
-	res, err := chains.Predict(ctx, k.qa, map[string]any{
+	out, err := chains.Call(ctx, k.qa, map[string]any{
 		"query": input,
 	})
 	if err != nil {
 		return "", err
 	}
+	res, _ := out[k.qa.GetOutputKeys()[0]].(string)
 
 	return `The following are the consultation results. When outputting, please output the results directly, do not make summaries, keep them in Chinese, and only do the original output:
-\n` + res, nil
+\n` + strings.TrimSpace(res), nil
+}
+
+// sourceRetriever 在每段检索内容前标注来源文件名
+type sourceRetriever struct {
+	schema.Retriever
+}
+
+func (r sourceRetriever) GetRelevantDocuments(ctx context.Context, query string) ([]schema.Document, error) {
+	docs, err := r.Retriever.GetRelevantDocuments(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	for i, d := range docs {
+		if source, _ := d.Metadata["source"].(string); source != "" {
+			docs[i].PageContent = KnowledgeSourceLabel + filepath.Base(source) + "\n" + d.PageContent
+		}
+	}
+	return docs, nil
+}
+
+// ParseKnowledgeSources 从知识库回答中解析引用的来源文件名
+func ParseKnowledgeSources(answer string) []string {
+	idx := strings.LastIndex(answer, KnowledgeSourcesPrefix)
+	if idx < 0 {
+		return nil
+	}
+	line := answer[idx+len(KnowledgeSourcesPrefix):]
+	if end := strings.IndexAny(line, "\r\n"); end >= 0 {
+		line = line[:end]
+	}
+
+	var sources []string
+	for _, s := range strings.Split(line, "、") {
+		if s = strings.TrimSpace(s); s != "" {
+			sources = append(sources, s)
+		}
+	}
+	return sources
 }
@@ -2,10 +2,12 @@ package toolx
 
 import (
 	"BackEnd/internal/domain"
+	"BackEnd/internal/model"
 	"BackEnd/internal/svc"
 	"BackEnd/pkg/langchain/outputparserx"
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/tmc/langchaingo/callbacks"
@@ -30,7 +32,7 @@ func NewApprovalAdd(svc *svc.ServiceContext, l ApprovalAddLogic) *ApprovalAdd {
 		outputparser: outputparserx.NewStructured([]outputparserx.ResponseSchema{
 			{
 				Name:        "type",
-				Description: "approval type: 1=Leave, 2=GoOut, 4=MakeCard(ClockIn Fix)",
+				Description: "approval type: 1=Leave, 2=GoOut, 3=MakeCard(ClockIn Fix), 4=Overtime(加班), 5=BusinessTrip(出差), 6=CustomForm(自定义表单, e.g. purchase or reimbursement)",
 				Type:        "int",
 			},
 			{
@@ -41,17 +43,17 @@ func NewApprovalAdd(svc *svc.ServiceContext, l ApprovalAddLogic) *ApprovalAdd {
 			// Mixed fields for different types
 			{
 				Name:        "startTime",
-				Description: "start time (yyyy-MM-dd HH:mm:ss) for Leave/GoOut",
+				Description: "start time (yyyy-MM-dd HH:mm:ss) for Leave/GoOut/Overtime/BusinessTrip",
 				Type:        "string",
 			},
 			{
 				Name:        "endTime",
-				Description: "end time (yyyy-MM-dd HH:mm:ss) for Leave/GoOut",
+				Description: "end time (yyyy-MM-dd HH:mm:ss) for Leave/GoOut/Overtime/BusinessTrip",
 				Type:        "string",
 			},
 			{
 				Name:        "leaveType",
-				Description: "leave type: 1=Personal, 2=Sick, 3=Annual, etc.",
+				Description: "leave type: 1=Personal(事假), 2=Time-off(调休), 3=Sick(病假), 4=Annual(年假), 5=Maternity, 6=Paternity, 7=Marriage, 8=Funeral, 9=Breastfeeding",
 				Type:        "int",
 			},
 			{
@@ -59,6 +61,31 @@ func NewApprovalAdd(svc *svc.ServiceContext, l ApprovalAddLogic) *ApprovalAdd {
 				Description: "date (yyyy-MM-dd) for MakeCard",
 				Type:        "string",
 			},
+			{
+				Name:        "compensation",
+				Description: "compensation for Overtime: 1=overtime pay(加班费), 2=time-off credit(调休), default 1",
+				Type:        "int",
+			},
+			{
+				Name:        "destinations",
+				Description: "destination cities for BusinessTrip, separated by commas",
+				Type:        "string",
+			},
+			{
+				Name:        "estimatedCost",
+				Description: "estimated cost in yuan for BusinessTrip (optional)",
+				Type:        "float",
+			},
+			{
+				Name:        "formCode",
+				Description: "form code for CustomForm, leave empty to get the list of available forms",
+				Type:        "string",
+			},
+			{
+				Name:        "form",
+				Description: "field values for CustomForm, keyed by field key; dates as yyyy-MM-dd",
+				Type:        "object",
+			},
 		}),
 	}
 }
@@ -68,7 +95,7 @@ func (t *ApprovalAdd) Name() string {
 }
 
 func (t *ApprovalAdd) Description() string {
-	return "Useful for submitted a new approval application (leave, go out, clock-in fix). Requires distinct parameters based on type." + t.outputparser.GetFormatInstructions()
+	return "Useful for submitted a new approval application (leave, go out, clock-in fix, overtime, business trip, custom forms defined by admins). Requires distinct parameters based on type." + t.outputparser.GetFormatInstructions()
 }
 
 func (t *ApprovalAdd) Call(ctx context.Context, input string) (string, error) {
@@ -144,7 +171,7 @@ func (t *ApprovalAdd) Call(ctx context.Context, input string) (string, error) {
 			Reason:    req.Reason,
 		}
 
-	case 4: // MakeCard (ClockIn Fix)
+	case 3: // MakeCard (ClockIn Fix)
 		dateStr := getString(p, "date")
 		if dateStr == "" {
 			return "", fmt.Errorf("date is required for MakeCard")
@@ -155,6 +182,56 @@ func (t *ApprovalAdd) Call(ctx context.Context, input string) (string, error) {
 			Reason: req.Reason,
 		}
 
+	case 4: // Overtime
+		startTimeStr := getString(p, "startTime")
+		endTimeStr := getString(p, "endTime")
+
+		if startTimeStr == "" || endTimeStr == "" {
+			return "", fmt.Errorf("startTime and endTime are required for Overtime")
+		}
+
+		req.Overtime = &domain.Overtime{
+			StartTime:    parseTime(startTimeStr),
+			EndTime:      parseTime(endTimeStr),
+			Compensation: int(getFloat(p, "compensation")),
+			Reason:       req.Reason,
+		}
+
+	case 5: // BusinessTrip
+		startTimeStr := getString(p, "startTime")
+		endTimeStr := getString(p, "endTime")
+		destinations := strings.FieldsFunc(getString(p, "destinations"), func(r rune) bool {
+			return r == ',' || r == '，' || r == '、'
+		})
+
+		if startTimeStr == "" || endTimeStr == "" || len(destinations) == 0 {
+			return "", fmt.Errorf("startTime, endTime and destinations are required for BusinessTrip")
+		}
+
+		req.BusinessTrip = &domain.BusinessTrip{
+			StartTime:     parseTime(startTimeStr),
+			EndTime:       parseTime(endTimeStr),
+			Destinations:  destinations,
+			EstimatedCost: getFloat(p, "estimatedCost"),
+			Reason:        req.Reason,
+		}
+
+	case 6: // CustomForm
+		code := getString(p, "formCode")
+		var form model.ApprovalForm
+		if code == "" || t.svc.DB.WithContext(ctx).Where("code = ? AND enabled = ?", code, true).First(&form).Error != nil {
+			return t.availableForms(ctx, code)
+		}
+		values, _ := p["form"].(map[string]any)
+		for _, f := range form.Fields {
+			// 日期字段按日期字符串传入，转换为时间戳
+			if s, ok := values[f.Key].(string); ok && (f.Type == "date" || f.Type == "datetime") {
+				values[f.Key] = parseTime(s)
+			}
+		}
+		req.FormCode = code
+		req.Form = values
+
 	default:
 		return "", fmt.Errorf("unsupported approval type: %d", req.Type)
 	}
@@ -171,6 +248,38 @@ func (t *ApprovalAdd) Call(ctx context.Context, input string) (string, error) {
 	return fmt.Sprintf("Successfully created approval. ID: %s", resp.Id), nil
 }
 
+// availableForms 表单编码无效时返回可用的表单及其字段，便于重新填写
+func (t *ApprovalAdd) availableForms(ctx context.Context, code string) (string, error) {
+	var forms []*model.ApprovalForm
+	if err := t.svc.DB.WithContext(ctx).Where("enabled = ?", true).Order("id").Find(&forms).Error; err != nil {
+		return "", err
+	}
+	if len(forms) == 0 {
+		return "", fmt.Errorf("no custom approval forms are available")
+	}
+
+	var b strings.Builder
+	if code != "" {
+		fmt.Fprintf(&b, "Form %q not found. ", code)
+	}
+	b.WriteString("Available forms, retry with formCode and form values:\n")
+	for _, f := range forms {
+		fmt.Fprintf(&b, "- %s (%s):", f.Code, f.Name)
+		for _, field := range f.Fields {
+			fmt.Fprintf(&b, " %s=%s[%s", field.Key, field.Label, field.Type)
+			if len(field.Options) > 0 {
+				fmt.Fprintf(&b, ": %s", strings.Join(field.Options, "/"))
+			}
+			if field.Required {
+				b.WriteString(", required")
+			}
+			b.WriteString("]")
+		}
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
+
 // Helpers
 func getString(m map[string]any, key string) string {
 	if v, ok := m[key].(string); ok {
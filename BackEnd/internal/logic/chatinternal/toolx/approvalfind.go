@@ -2,6 +2,7 @@ package toolx
 
 import (
 	"BackEnd/internal/domain"
+	"BackEnd/internal/model"
 	"BackEnd/internal/svc"
 	"BackEnd/pkg/langchain/outputparserx"
 	"context"
@@ -36,9 +37,19 @@ func NewApprovalFind(svc *svc.ServiceContext, l ApprovalFindLogic) *ApprovalFind
 			},
 			{
 				Name:        "type",
-				Description: "Approval type (optional). 0=all, 1=leave, 2=go_out, 3=overtime, 4=make_card",
+				Description: "Approval type (optional). 0=all, 1=leave, 2=go_out, 3=make_card, 4=overtime, 5=business_trip, 6=custom_form",
 				Type:        "int",
 			},
+			{
+				Name:        "scope",
+				Description: "Optional. \"applied\" for approvals the user applied for, \"todo\" for approvals waiting for the user to approve (待我审批), \"done\" for approvals the user has approved or rejected, \"cc\" for approvals CC'd (抄送) to the user, empty for approvals the user applied for or approves",
+				Type:        "string",
+			},
+			{
+				Name:        "timeRange",
+				Description: "Time range the approval was submitted in, copy the user's words such as 本周, 上个月, Q3, 节前 (optional, do not calculate timestamps)",
+				Type:        "string",
+			},
 			{
 				Name:        "count",
 				Description: "Number of records to return (default 5)",
@@ -84,9 +95,17 @@ func (t *ApprovalFind) Call(ctx context.Context, input string) (string, error) {
 	if v, ok := p["type"].(float64); ok {
 		req.Type = int(v)
 	}
+	if v, ok := p["scope"].(string); ok {
+		req.Scope = v
+	}
 	if v, ok := p["count"].(float64); ok {
 		req.Count = int(v)
 	}
+	if v, ok := p["timeRange"].(string); ok {
+		if req.StartTime, req.EndTime, err = resolveTimeRange(ctx, t.svc, v); err != nil {
+			return "", err
+		}
+	}
 
 	// 2. Call Logic
 	resp, err := t.logic.List(ctx, req)
@@ -102,22 +121,31 @@ func (t *ApprovalFind) Call(ctx context.Context, input string) (string, error) {
 	var result []map[string]any
 	for _, item := range resp.List {
 		statusStr := "Pending"
-		if item.Status == 2 {
+		switch model.ApprovalStatus(item.Status) {
+		case model.Pass:
 			statusStr = "Passed"
-		} else if item.Status == 3 {
+		case model.Refuse:
 			statusStr = "Rejected"
+		case model.Cancel:
+			statusStr = "Withdrawn"
+		case model.Draft:
+			statusStr = "Draft"
 		}
 
 		typeStr := "Unknown"
-		switch item.Type {
-		case 1:
+		switch model.ApprovalType(item.Type) {
+		case model.LeaveApproval:
 			typeStr = "Leave"
-		case 2:
+		case model.GoOutApproval:
 			typeStr = "GoOut"
-		case 3:
-			typeStr = "Overtime"
-		case 4:
+		case model.MakeCardApproval:
 			typeStr = "MakeCard"
+		case model.OvertimeApproval:
+			typeStr = "Overtime"
+		case model.TripApproval:
+			typeStr = "BusinessTrip"
+		case model.FormApproval:
+			typeStr = "CustomForm"
 		}
 
 		result = append(result, map[string]any{
@@ -0,0 +1,317 @@
+package toolx
+
+import (
+	"BackEnd/internal/config"
+	"BackEnd/internal/model"
+	"BackEnd/internal/svc"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/rueidis"
+	"github.com/tmc/langchaingo/schema"
+	"github.com/tmc/langchaingo/vectorstores/redisvector"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	knowledgeIndexName = "knowledge" // 向量索引名称
+	defaultDocType     = "default"   // 未配置切分参数时使用的文档类型
+)
+
+// 单个文档的索引结果
+const (
+	IndexSkipped = "skipped" // 内容与切分参数均未变化，跳过
+	IndexCreated = "created" // 首次入库
+	IndexUpdated = "updated" // 内容或切分参数变化，增量更新
+)
+
+// IndexResult 单个文档的索引结果
+type IndexResult struct {
+	Source  string // 来源文件
+	Status  string // skipped / created / updated
+	Added   int    // 新增文档块数量
+	Removed int    // 删除文档块数量
+	Kept    int    // 内容未变化而保留的文档块数量
+}
+
+// KnowledgeIndexer 知识库增量索引器
+// 以文件内容哈希判断文档是否变化，以文档块内容哈希判断哪些块需要新增或删除，
+// 保证重复更新同一文件不会产生重复的文档块
+type KnowledgeIndexer struct {
+	svc   *svc.ServiceContext
+	mu    sync.Mutex
+	store *redisvector.Store // 首次写入向量时连接，连接失败时下次重试
+	redis rueidis.Client     // 首次删除向量时连接，连接失败时下次重试
+}
+
+// NewKnowledgeIndexer 创建知识库增量索引器
+func NewKnowledgeIndexer(svc *svc.ServiceContext) *KnowledgeIndexer {
+	return &KnowledgeIndexer{svc: svc}
+}
+
+// ChunkConfig 返回文档类型对应的切分参数，未配置时依次回退到 default 和内置默认值
+func (k *KnowledgeIndexer) ChunkConfig(docType string) config.ChunkConfig {
+	chunking := k.svc.Config.Knowledge.Chunking
+	if c, ok := chunking[strings.ToLower(docType)]; ok && c.Size > 0 {
+		return c
+	}
+	if c, ok := chunking[defaultDocType]; ok && c.Size > 0 {
+		return c
+	}
+	return config.ChunkConfig{Size: 500, Overlap: 50}
+}
+
+// IndexFile 增量索引单个文件
+// docType 为空时使用文件扩展名作为文档类型；force 为 true 时忽略文件哈希强制重新切分
+func (k *KnowledgeIndexer) IndexFile(ctx context.Context, filePath, docType string, force bool) (*IndexResult, error) {
+	if docType == "" {
+		docType = strings.TrimPrefix(strings.ToLower(filepath.Ext(filePath)), ".")
+	}
+	chunkConfig := k.ChunkConfig(docType)
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("读取文件失败: %v", err)
+	}
+	fileHash := hashContent(string(content))
+
+	// 同一文件的索引串行执行：在事务中锁定文档记录直到索引完成，
+	// 其他实例、工具或评测程序同时索引该文件时等待，之后按文件哈希跳过
+	var result *IndexResult
+	err = k.svc.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		result, err = k.indexLocked(ctx, tx, filePath, docType, fileHash, chunkConfig, force)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// indexLocked 在持有文档记录行锁的事务中索引文件，文档块通过独立连接写入
+func (k *KnowledgeIndexer) indexLocked(ctx context.Context, tx *gorm.DB, filePath, docType, fileHash string,
+	chunkConfig config.ChunkConfig, force bool) (*IndexResult, error) {
+	db := k.svc.DB.WithContext(ctx)
+
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.KnowledgeDocument{Source: filePath, DocType: docType}).Error; err != nil {
+		return nil, err
+	}
+	var doc model.KnowledgeDocument
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("source = ?", filePath).First(&doc).Error; err != nil {
+		return nil, err
+	}
+
+	// 1. 文件内容和切分参数都没有变化时直接跳过
+	result := &IndexResult{Source: filePath, Status: IndexCreated}
+	if doc.IndexedAt != nil {
+		if !force && doc.Hash == fileHash && doc.ChunkSize == chunkConfig.Size && doc.ChunkOverlap == chunkConfig.Overlap {
+			result.Status = IndexSkipped
+			result.Kept = doc.ChunkCount
+			return result, nil
+		}
+		result.Status = IndexUpdated
+	}
+
+	// 2. 切分文档
+	docs, err := NewPDFProcessor().LoadAndSplitPDF(ctx, filePath, chunkConfig.Size, chunkConfig.Overlap)
+	if err != nil {
+		return nil, fmt.Errorf("PDF处理失败: %v", err)
+	}
+
+	// 文件哈希和切分参数在文档块全部写入后才更新，中途失败时下次会重新索引
+	if doc.DocType != docType {
+		if err := tx.Model(&doc).Update("doc_type", docType).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	// 3. 与已有文档块按内容哈希比对（同一文件内重复的块只保留一份）
+	var existing []model.KnowledgeChunk
+	if err := db.Where("source = ?", filePath).Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	existingByHash := make(map[string]model.KnowledgeChunk, len(existing))
+	var removed []model.KnowledgeChunk
+	for _, c := range existing {
+		if _, dup := existingByHash[c.Hash]; dup || c.Hash == "" {
+			removed = append(removed, c)
+			continue
+		}
+		existingByHash[c.Hash] = c
+	}
+
+	var added []model.KnowledgeChunk
+	seen := make(map[string]bool, len(docs))
+	for _, d := range docs {
+		h := hashContent(d.PageContent)
+		if seen[h] {
+			continue
+		}
+		seen[h] = true
+
+		index := len(seen) - 1
+		if c, ok := existingByHash[h]; ok {
+			delete(existingByHash, h)
+			if c.ChunkIndex != index || c.DocumentID != doc.ID {
+				if err := db.Model(&c).Updates(map[string]any{"chunk_index": index, "document_id": doc.ID}).Error; err != nil {
+					return nil, err
+				}
+			}
+			result.Kept++
+			continue
+		}
+		added = append(added, model.KnowledgeChunk{
+			DocumentID: doc.ID,
+			Source:     filePath,
+			ChunkIndex: index,
+			Hash:       h,
+			Content:    d.PageContent,
+		})
+	}
+	for _, c := range existingByHash {
+		removed = append(removed, c)
+	}
+
+	// 4. 写入新增块、删除失效块
+	if err := k.addChunks(ctx, added); err != nil {
+		return nil, err
+	}
+	if err := k.removeChunks(ctx, removed); err != nil {
+		return nil, err
+	}
+	result.Added = len(added)
+	result.Removed = len(removed)
+
+	now := time.Now()
+	if err := tx.Model(&doc).Updates(map[string]any{
+		"hash":          fileHash,
+		"chunk_size":    chunkConfig.Size,
+		"chunk_overlap": chunkConfig.Overlap,
+		"chunk_count":   len(seen),
+		"indexed_at":    &now,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// addChunks 将文档块同时写入数据库、向量索引和关键词索引
+// 文档块先入库获得ID，再以该ID作为向量文档的键，便于混合检索融合以及后续删除
+func (k *KnowledgeIndexer) addChunks(ctx context.Context, chunks []model.KnowledgeChunk) error {
+	if len(chunks) == 0 {
+		return nil
+	}
+
+	db := k.svc.DB.WithContext(ctx)
+	if err := db.Create(&chunks).Error; err != nil {
+		return err
+	}
+
+	docs := make([]schema.Document, 0, len(chunks))
+	for _, c := range chunks {
+		docs = append(docs, schema.Document{
+			PageContent: c.Content,
+			Metadata: map[string]any{
+				"source":   c.Source,
+				chunkIDKey: c.ID,
+				"keys":     c.ID, // 指定向量文档键为 doc:knowledge:<chunkID>
+			},
+		})
+	}
+
+	store, err := k.getStore(ctx)
+	if err == nil {
+		_, err = store.AddDocuments(ctx, docs)
+	}
+	if err != nil {
+		// 向量写入失败时回滚文档块，避免两个索引数据不一致
+		db.Unscoped().Delete(&chunks)
+		return err
+	}
+
+	for _, c := range chunks {
+		k.svc.KnowledgeIndex.Add(strconv.Itoa(int(c.ID)), c.Content)
+	}
+	return nil
+}
+
+// removeChunks 从数据库、向量索引和关键词索引中删除文档块
+func (k *KnowledgeIndexer) removeChunks(ctx context.Context, chunks []model.KnowledgeChunk) error {
+	if len(chunks) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(chunks))
+	for _, c := range chunks {
+		keys = append(keys, fmt.Sprintf("doc:%s:%d", knowledgeIndexName, c.ID))
+	}
+
+	client, err := k.getRedis()
+	if err != nil {
+		return err
+	}
+	if err := client.Do(ctx, client.B().Del().Key(keys...).Build()).Error(); err != nil {
+		return err
+	}
+
+	if err := k.svc.DB.WithContext(ctx).Unscoped().Delete(&chunks).Error; err != nil {
+		return err
+	}
+
+	for _, c := range chunks {
+		k.svc.KnowledgeIndex.Remove(strconv.Itoa(int(c.ID)))
+	}
+	return nil
+}
+
+// getStore 懒加载向量存储
+func (k *KnowledgeIndexer) getStore(ctx context.Context) (*redisvector.Store, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.store != nil {
+		return k.store, nil
+	}
+	store, err := getKnowledgeStore(ctx, k.svc)
+	if err != nil {
+		return nil, err
+	}
+	k.store = store
+	return store, nil
+}
+
+// getRedis 懒加载 Redis 客户端，用于删除向量文档
+func (k *KnowledgeIndexer) getRedis() (rueidis.Client, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.redis != nil {
+		return k.redis, nil
+	}
+	opt, err := rueidis.ParseURL(knowledgeRedisURL(k.svc))
+	if err != nil {
+		return nil, err
+	}
+	client, err := rueidis.NewClient(opt)
+	if err != nil {
+		return nil, err
+	}
+	k.redis = client
+	return client, nil
+}
+
+// hashContent 计算内容的 SHA-256 哈希
+func hashContent(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
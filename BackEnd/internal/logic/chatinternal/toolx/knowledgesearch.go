@@ -0,0 +1,198 @@
+package toolx
+
+import (
+	"BackEnd/internal/model"
+	"BackEnd/internal/svc"
+	"BackEnd/pkg/search"
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"github.com/tmc/langchaingo/schema"
+	"github.com/tmc/langchaingo/vectorstores/redisvector"
+)
+
+// chunkIDKey 向量文档元数据中记录知识库文档块ID的字段
+const chunkIDKey = "chunk_id"
+
+// KnowledgeHit 混合检索命中的文档块
+type KnowledgeHit struct {
+	ChunkID     string  // 文档块ID（旧数据没有ID时为向量库中的文档键）
+	Source      string  // 来源文件
+	Content     string  // 文档块内容
+	Score       float64 // RRF 融合得分
+	KeywordRank int     // 关键词检索名次，0 表示未命中
+	VectorRank  int     // 向量检索名次，0 表示未命中
+}
+
+// KnowledgeSearcher 知识库混合检索器
+// 同时进行关键词（BM25）检索和向量检索，并使用 RRF 融合两路结果
+// 实现了 schema.Retriever 接口，可直接用于 RetrievalQA，可以在多个请求间共享
+type KnowledgeSearcher struct {
+	svc  *svc.ServiceContext
+	topK int
+	rrfK int
+
+	mu    sync.Mutex
+	store *redisvector.Store // 首次向量检索时连接，连接失败时下次重试
+}
+
+// NewKnowledgeSearcher 创建知识库混合检索器
+func NewKnowledgeSearcher(svc *svc.ServiceContext) *KnowledgeSearcher {
+	topK := svc.Config.Knowledge.TopK
+	if topK <= 0 {
+		topK = 4
+	}
+	return &KnowledgeSearcher{
+		svc:  svc,
+		topK: topK,
+		rrfK: svc.Config.Knowledge.RRFK,
+	}
+}
+
+// GetRelevantDocuments 检索与 query 相关的文档，供 RetrievalQA 使用
+func (s *KnowledgeSearcher) GetRelevantDocuments(ctx context.Context, query string) ([]schema.Document, error) {
+	hits, err := s.Search(ctx, query, s.topK)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make([]schema.Document, 0, len(hits))
+	for _, h := range hits {
+		docs = append(docs, schema.Document{
+			PageContent: h.Content,
+			Metadata: map[string]any{
+				"source":   h.Source,
+				chunkIDKey: h.ChunkID,
+			},
+			Score: float32(h.Score),
+		})
+	}
+	return docs, nil
+}
+
+// Search 混合检索，返回融合排序后的前 k 个文档块
+func (s *KnowledgeSearcher) Search(ctx context.Context, query string, k int) ([]KnowledgeHit, error) {
+	if k <= 0 {
+		k = s.topK
+	}
+	// 每一路多取一些候选，融合后再截断
+	candidates := k * 2
+
+	// 1. 关键词检索
+	var keywordIDs []string
+	for _, h := range s.svc.KnowledgeIndex.Search(query, candidates) {
+		keywordIDs = append(keywordIDs, h.ID)
+	}
+
+	// 2. 向量检索，失败时降级为仅关键词检索
+	vectorIDs, vectorDocs, vecErr := s.vectorSearch(ctx, query, candidates)
+	if vecErr != nil {
+		if len(keywordIDs) == 0 {
+			return nil, vecErr
+		}
+		log.Warn().Err(vecErr).Str("query", query).Msg("向量检索失败，仅使用关键词检索结果")
+	}
+
+	// 3. RRF 融合
+	fused := search.FuseRRF(s.rrfK, keywordIDs, vectorIDs)
+	if len(fused) > k {
+		fused = fused[:k]
+	}
+
+	// 4. 补全文档块内容与来源
+	chunks, err := s.loadChunks(ctx, fused)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]KnowledgeHit, 0, len(fused))
+	for _, f := range fused {
+		hit := KnowledgeHit{
+			ChunkID:     f.ID,
+			Score:       f.Score,
+			KeywordRank: f.Ranks[0],
+			VectorRank:  f.Ranks[1],
+		}
+		if c, ok := chunks[f.ID]; ok {
+			hit.Source = c.Source
+			hit.Content = c.Content
+		} else if d, ok := vectorDocs[f.ID]; ok {
+			hit.Source = fmt.Sprintf("%v", d.Metadata["source"])
+			hit.Content = d.PageContent
+		} else {
+			continue
+		}
+		hits = append(hits, hit)
+	}
+
+	return hits, nil
+}
+
+// vectorStore 返回向量库连接，尚未连接时建立连接
+func (s *KnowledgeSearcher) vectorStore(ctx context.Context) (*redisvector.Store, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.store == nil {
+		store, err := getKnowledgeStore(ctx, s.svc)
+		if err != nil {
+			return nil, err
+		}
+		s.store = store
+	}
+	return s.store, nil
+}
+
+// vectorSearch 向量检索，返回按相似度排序的文档ID以及未关联文档块的旧文档
+func (s *KnowledgeSearcher) vectorSearch(ctx context.Context, query string, k int) ([]string, map[string]schema.Document, error) {
+	store, err := s.vectorStore(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	docs, err := store.SimilaritySearch(ctx, query, k)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ids := make([]string, 0, len(docs))
+	legacy := make(map[string]schema.Document)
+	for _, d := range docs {
+		// 新数据以文档块ID关联关键词索引，内容以数据库为准（已删除的块会被忽略）；
+		// 旧数据没有该字段时退化为向量库文档键，直接使用向量库中的内容
+		if v, ok := d.Metadata[chunkIDKey]; ok {
+			ids = append(ids, fmt.Sprintf("%v", v))
+			continue
+		}
+		id := fmt.Sprintf("%v", d.Metadata["id"])
+		ids = append(ids, id)
+		legacy[id] = d
+	}
+	return ids, legacy, nil
+}
+
+// loadChunks 按ID批量查询文档块
+func (s *KnowledgeSearcher) loadChunks(ctx context.Context, fused []search.Fused) (map[string]model.KnowledgeChunk, error) {
+	var ids []uint
+	for _, f := range fused {
+		if id, err := strconv.ParseUint(f.ID, 10, 64); err == nil {
+			ids = append(ids, uint(id))
+		}
+	}
+
+	res := make(map[string]model.KnowledgeChunk, len(ids))
+	if len(ids) == 0 {
+		return res, nil
+	}
+
+	var chunks []model.KnowledgeChunk
+	if err := s.svc.DB.WithContext(ctx).Where("id IN ?", ids).Find(&chunks).Error; err != nil {
+		return nil, err
+	}
+	for _, c := range chunks {
+		res[strconv.Itoa(int(c.ID))] = c
+	}
+	return res, nil
+}
@@ -19,13 +19,14 @@ type KnowledgeUpdate struct {
 	svc          *svc.ServiceContext
 	Callback     callbacks.Handler
 	outPutParser outputparserx.Structured
-	store        *redisvector.Store
+	indexer      *KnowledgeIndexer
 }
 
 func NewKnowledgeUpdate(svc *svc.ServiceContext) *KnowledgeUpdate {
 	return &KnowledgeUpdate{
 		svc:      svc,
 		Callback: svc.Callbacks,
+		indexer:  NewKnowledgeIndexer(svc),
 		outPutParser: outputparserx.NewStructured([]outputparserx.ResponseSchema{
 			{
 				Name:        "path",
@@ -36,6 +37,9 @@ func NewKnowledgeUpdate(svc *svc.ServiceContext) *KnowledgeUpdate {
 			}, {
 				Name:        "time",
 				Description: "file update time",
+			}, {
+				Name:        "type",
+				Description: "document type used to choose chunking parameters, such as handbook. none is empty",
 			},
 		}),
 	}
@@ -49,7 +53,7 @@ func (k *KnowledgeUpdate) Description() string {
 	return `a knowledge base update interface.
 use when you need to update knowledge base content.
 your output should be in the following json format:
-{"path": "file path", "name": "file name", "time": "update time"}`
+{"path": "file path", "name": "file name", "time": "update time", "type": "document type, optional"}`
 }
 
 func (k *KnowledgeUpdate) Call(ctx context.Context, input string) (string, error) {
@@ -87,27 +91,22 @@ func (k *KnowledgeUpdate) Call(ctx context.Context, input string) (string, error
 		return "", fmt.Errorf("文件不存在: %s", filePath)
 	}
 
-	// 使用新的PDF处理器
-	pdfProcessor := NewPDFProcessor()
-	chunkedDocuments, err := pdfProcessor.LoadAndSplitPDF(ctx, filePath, 500, 50)
-	if err != nil {
-		return "", fmt.Errorf("PDF处理失败: %v", err)
-	}
-
-	fmt.Printf("成功处理PDF文件: %s，生成 %d 个文档块\n", filePath, len(chunkedDocuments))
-
-	if k.store == nil {
-		k.store, err = getKnowledgeStore(ctx, k.svc)
-		if err != nil {
-			return "", err
-		}
+	// 增量索引：文件未变化时跳过，变化时只替换有差异的文档块
+	docType := ""
+	if v, ok := file["type"]; ok && v != nil {
+		docType = fmt.Sprintf("%v", v)
 	}
-
-	_, err = k.store.AddDocuments(ctx, chunkedDocuments)
+	res, err := k.indexer.IndexFile(ctx, filePath, docType, false)
 	if err != nil {
 		return "", err
 	}
 
+	fmt.Printf("知识库文件 %s 索引完成: %s, 新增 %d, 删除 %d, 保留 %d\n",
+		filePath, res.Status, res.Added, res.Removed, res.Kept)
+
+	if res.Status == IndexSkipped {
+		return Success + "the file content has not changed, skipped.", nil
+	}
 	return Success, nil
 }
 
@@ -118,11 +117,14 @@ func getKnowledgeStore(ctx context.Context, svc *svc.ServiceContext) (*redisvect
 		return nil, err
 	}
 
+	return redisvector.New(ctx, redisvector.WithEmbedder(embedder), redisvector.WithConnectionURL(knowledgeRedisURL(svc)), redisvector.WithIndexName(knowledgeIndexName, true))
+}
+
+// knowledgeRedisURL 根据配置拼接知识库使用的 Redis 连接地址
+func knowledgeRedisURL(svc *svc.ServiceContext) string {
 	redisUrl := "redis://"
 	if svc.Config.Redis.Password != "" {
 		redisUrl += ":" + svc.Config.Redis.Password + "@"
 	}
-	redisUrl += svc.Config.Redis.Addr
-
-	return redisvector.New(ctx, redisvector.WithEmbedder(embedder), redisvector.WithConnectionURL(redisUrl), redisvector.WithIndexName("knowledge", true))
+	return redisUrl + svc.Config.Redis.Addr
 }
@@ -0,0 +1,96 @@
+package toolx
+
+import (
+	"BackEnd/internal/domain"
+	"BackEnd/internal/svc"
+	"BackEnd/pkg/langchain/outputparserx"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/tmc/langchaingo/callbacks"
+)
+
+// LeaveBalanceLogic 工具依赖的假期余额查询逻辑
+type LeaveBalanceLogic interface {
+	Balance(ctx context.Context, req *domain.LeaveBalanceReq) (resp *domain.LeaveBalanceResp, err error)
+}
+
+// LeaveBalance 假期余额查询工具，回答“我还有几天年假”这类问题
+type LeaveBalance struct {
+	svc          *svc.ServiceContext
+	callback     callbacks.Handler
+	outputparser outputparserx.Structured
+	logic        LeaveBalanceLogic
+}
+
+func NewLeaveBalance(svc *svc.ServiceContext, l LeaveBalanceLogic) *LeaveBalance {
+	return &LeaveBalance{
+		svc:      svc,
+		callback: svc.Callbacks,
+		logic:    l,
+		outputparser: outputparserx.NewStructured([]outputparserx.ResponseSchema{
+			{
+				Name:        "type",
+				Description: "Leave type (optional, 0=all). 1=事假, 2=调休, 3=病假, 4=年假, 5=产假, 6=陪产假, 7=婚假, 8=丧假, 9=哺乳假",
+				Type:        "int",
+			},
+			{
+				Name:        "year",
+				Description: "Year of the balance (optional, default this year)",
+				Type:        "int",
+			},
+		}),
+	}
+}
+
+func (t *LeaveBalance) Name() string {
+	return "leave_balance"
+}
+
+func (t *LeaveBalance) Description() string {
+	return "Useful for answering how many leave days the current user has left, such as annual leave (年假) or sick leave. Balances are in days; leave types without a quota are unlimited and not listed." + t.outputparser.GetFormatInstructions()
+}
+
+func (t *LeaveBalance) Call(ctx context.Context, input string) (string, error) {
+	if t.callback != nil {
+		t.callback.HandleText(ctx, "Querying leave balance: "+input)
+	}
+
+	params, err := t.outputparser.Parse(input)
+	if err != nil {
+		return "", err
+	}
+	p := params.(map[string]any)
+
+	req := &domain.LeaveBalanceReq{}
+	if v, ok := p["type"].(float64); ok {
+		req.Type = int(v)
+	}
+	if v, ok := p["year"].(float64); ok {
+		req.Year = int(v)
+	}
+
+	resp, err := t.logic.Balance(ctx, req)
+	if err != nil {
+		return "", fmt.Errorf("failed to query leave balance: %v", err)
+	}
+	if len(resp.List) == 0 {
+		return "This leave type has no quota limit.", nil
+	}
+
+	var result []map[string]any
+	for _, b := range resp.List {
+		result = append(result, map[string]any{
+			"type":      b.TypeName,
+			"year":      b.Year,
+			"total":     b.Total,
+			"used":      b.Used,
+			"pending":   b.Frozen,
+			"available": b.Available,
+		})
+	}
+
+	jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+	return string(jsonBytes), nil
+}
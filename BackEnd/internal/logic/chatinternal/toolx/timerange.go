@@ -0,0 +1,56 @@
+package toolx
+
+import (
+	"BackEnd/internal/model"
+	"BackEnd/internal/svc"
+	"BackEnd/pkg/nltime"
+	"BackEnd/pkg/workcal"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// newTimeParser 按服务配置的时区和节假日创建自然语言时间解析器，节假日包括配置的和工作日历中导入的
+// 读取工作日历失败时只使用配置的节假日
+func newTimeParser(ctx context.Context, svc *svc.ServiceContext, loc *time.Location) *nltime.Parser {
+	p := nltime.NewParser(time.Now().In(loc))
+	for _, h := range svc.Config.Holidays {
+		start, err1 := time.ParseInLocation(time.DateOnly, h.Start, loc)
+		end, err2 := time.ParseInLocation(time.DateOnly, h.End, loc)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		p.Holidays = append(p.Holidays, nltime.Holiday{Name: h.Name, Start: start, End: end})
+	}
+
+	var days []model.CalendarDay
+	if err := svc.DB.WithContext(ctx).Where("kind = ?", workcal.Holiday).Find(&days).Error; err != nil {
+		log.Error().Err(err).Msg("failed to load holidays from work calendar")
+	} else {
+		holidays := make([]workcal.Day, 0, len(days))
+		for _, d := range days {
+			holidays = append(holidays, workcal.Day{Date: d.Date, Kind: workcal.Holiday, Name: d.Name})
+		}
+		for _, r := range workcal.New(loc, nil, nil, holidays).Holidays() {
+			p.Holidays = append(p.Holidays, nltime.Holiday{Name: r.Name, Start: r.Start, End: r.End})
+		}
+	}
+	return p
+}
+
+// resolveTimeRange 将“本周”“上个月”“Q3”“节前”等表达式解析为时间戳范围 [start, end]（均包含）
+// 工具统一通过该方法计算查询时间范围，不再让AI自行推算时间戳
+func resolveTimeRange(ctx context.Context, svc *svc.ServiceContext, expr string) (start, end int64, err error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return 0, 0, nil
+	}
+	res, err := newTimeParser(ctx, svc, svc.Location()).ParseRange(expr)
+	if err != nil {
+		return 0, 0, fmt.Errorf("cannot resolve time range %q: %v", expr, err)
+	}
+	return res.Start.Unix(), res.Last().Unix(), nil
+}
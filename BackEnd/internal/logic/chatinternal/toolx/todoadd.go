@@ -33,7 +33,7 @@ func NewTodoAdd(svc *svc.ServiceContext) *TodoAdd {
 				Description: "todo title",
 			}, {
 				Name:        "deadlineAt",
-				Description: "the deadline Unix timestamp (in seconds). You MUST use the time_parser tool first to convert the user's time expression to a timestamp, then use the timestamp value returned by time_parser tool here.",
+				Description: "the deadline Unix timestamp (in seconds). You MUST use the time_parser tool first to convert the user's time expression, then use the deadline value returned by time_parser tool here.",
 				Type:        "int64",
 			}, {
 				Name:        "desc",
@@ -42,6 +42,9 @@ func NewTodoAdd(svc *svc.ServiceContext) *TodoAdd {
 				Name:        "executeIds",
 				Description: "list of participating users in the backlog. the data type is a set of string ids. none is empty",
 				Type:        "[]string",
+			}, {
+				Name:        "recurrence",
+				Description: "repeat rule in RRULE format when the user asks for a repeating todo, empty otherwise. e.g. every day: FREQ=DAILY; every Friday: FREQ=WEEKLY;BYDAY=FR; every Monday and Thursday: FREQ=WEEKLY;BYDAY=MO,TH; every two weeks: FREQ=WEEKLY;INTERVAL=2; on the 25th of every month: FREQ=MONTHLY;BYMONTHDAY=25; last day of every month: FREQ=MONTHLY;BYMONTHDAY=-1. deadlineAt must be the first occurrence",
 			},
 		}),
 	}
@@ -58,6 +61,7 @@ func (t *TodoAdd) Description() string {
 	a todo add interface.
 	use when you need to create a todo.
 	IMPORTANT: if user mentions a person's name (like "王员工"), you MUST first use the user_list tool to query and get the user's ID, then use that ID in executeIds field.
+	for repeating todos like "每周五交周报", set recurrence and use the first occurrence (e.g. the coming Friday) as deadlineAt.
 	keep Chinese output.
 ` + t.outputparser.GetFormatInstructions()
 
@@ -2,6 +2,7 @@ package toolx
 
 import (
 	"BackEnd/internal/domain"
+	"BackEnd/internal/model"
 	"BackEnd/internal/svc"
 	"BackEnd/pkg/curl"
 	"BackEnd/pkg/httpx"
@@ -37,20 +38,60 @@ func NewTodoFind(svc *svc.ServiceContext) *TodoFind {
 				Type:        "string",
 			},
 			{
-				Name:        "startTime",
-				Description: "start time, data application time stamp, such as 1720921573. none is empty",
-				Type:        "int64",
+				Name:        "createdRange",
+				Description: "time range the todo was created in, copy the user's words, such as 本周, 上个月, Q3, 最近7天. none is empty",
+				Type:        "string",
 			},
 			{
-				Name:        "endTime",
-				Description: "end time, data application time stamp, such as 1720921573. none is empty",
-				Type:        "int64",
+				Name:        "deadlineRange",
+				Description: "time range the todo deadline falls in, copy the user's words, such as 今天, 下周, 月底前, 节前. none is empty",
+				Type:        "string",
 			},
 			{
 				Name:        "userId",
 				Description: "user id",
 				Type:        "string",
 			},
+			{
+				Name:        "role",
+				Description: "created: todos I created, assigned: todos assigned to me. empty means both",
+				Type:        "string",
+			},
+			{
+				Name:        "status",
+				Description: "todo status, 0: pending, 1: in progress, 2: finished, 3: overdue. none is empty",
+				Type:        "string",
+			},
+			{
+				Name:        "myStatus",
+				Description: "my own status as an executor, same values as status. none is empty",
+				Type:        "string",
+			},
+			{
+				Name:        "label",
+				Description: "todo label, such as 工作, 学习. none is empty",
+				Type:        "string",
+			},
+			{
+				Name:        "priority",
+				Description: "todo priority, 1: low, 2: medium, 3: high, 4: urgent. none is empty",
+				Type:        "string",
+			},
+			{
+				Name:        "keyword",
+				Description: "keyword in the todo title or description. none is empty",
+				Type:        "string",
+			},
+			{
+				Name:        "sort",
+				Description: "sort by created, deadline or priority. none is empty",
+				Type:        "string",
+			},
+			{
+				Name:        "order",
+				Description: "asc or desc. none is empty",
+				Type:        "string",
+			},
 		}),
 	}
 }
@@ -66,7 +107,10 @@ func (t *TodoFind) Description() string {
 	a todo find interface.
 	use when you need to find, query, search or list todos.
 	use when user asks: "我的待办", "查询待办", "有哪些待办", "待办事项", "find my todos", etc.
-	If user doesn't provide specific conditions (id, startTime, endTime), query all todos by leaving those fields empty.
+	do not calculate timestamps, put the user's time words into createdRange or deadlineRange as they are.
+	use role when user asks "我创建的" or "分配给我的", status for "未完成", "已逾期" etc., priority for "紧急", "高优先级" etc.
+	use sort deadline when user asks "快到期的", sort priority when user asks "最重要的".
+	If user doesn't provide specific conditions, query all todos by leaving those fields empty.
 	If the condition is null, return {}
 	keep Chinese output.` + t.outputparser.GetFormatInstructions()
 }
@@ -90,10 +134,26 @@ func (t *TodoFind) Call(ctx context.Context, input string) (string, error) {
 		data = make(map[string]any)
 	}
 	uid, _ := token.GetUserID(ctx)
-	data["userId"] = uid              // 设置当前用户ID
-	data["count"] = 10                // 设置查询数量限制
-	conversionTime("startTime", data) // 转换开始时间格式
-	conversionTime("endTime", data)   // 转换结束时间格式
+	data["userId"] = uid // 设置当前用户ID
+	data["count"] = 10   // 设置查询数量限制
+	// 整体状态对应列表接口的 todoStatus，空的条件不传，避免按零值过滤
+	if status, ok := data["status"]; ok {
+		data["todoStatus"] = status
+		delete(data, "status")
+	}
+	for key, value := range data {
+		if value == "" || value == nil {
+			delete(data, key)
+		}
+	}
+
+	// 将自然语言时间范围解析为时间戳
+	if err := t.resolveRange(ctx, data, "createdRange", "startTime", "endTime"); err != nil {
+		return "", err
+	}
+	if err := t.resolveRange(ctx, data, "deadlineRange", "deadlineStart", "deadlineEnd"); err != nil {
+		return "", err
+	}
 
 	// 确保Host包含协议
 
@@ -112,12 +172,19 @@ func (t *TodoFind) Call(ctx context.Context, input string) (string, error) {
 	return t.formatTodoList(res)
 }
 
-// conversionTime 转换时间字段格式，将float64转换为int64
-func conversionTime(filed string, data map[string]any) {
-	if v, ok := data[filed]; ok {
-		tmp := v.(float64)
-		data[filed] = int64(tmp)
+// resolveRange 将 field 中的时间范围表达式解析为 startKey、endKey 两个查询参数
+func (t *TodoFind) resolveRange(ctx context.Context, data map[string]any, field, startKey, endKey string) error {
+	expr, _ := data[field].(string)
+	delete(data, field)
+	start, end, err := resolveTimeRange(ctx, t.svc, expr)
+	if err != nil {
+		return err
+	}
+	if start > 0 {
+		data[startKey] = start
+		data[endKey] = end
 	}
+	return nil
 }
 
 // formatTodoList 格式化待办列表输出
@@ -163,6 +230,13 @@ func (t *TodoFind) formatTodoList(res []byte) (string, error) {
 		// 格式化截止时间
 		result.WriteString(fmt.Sprintf("   截止时间: %s\n", formatTodoTimestamp(todo.DeadlineAt)))
 
+		if todo.Priority > 0 {
+			result.WriteString(fmt.Sprintf("   优先级: %s\n", getTodoPriorityName(todo.Priority)))
+		}
+		if len(todo.Labels) > 0 {
+			result.WriteString(fmt.Sprintf("   标签: %s\n", strings.Join(todo.Labels, "、")))
+		}
+
 		// 如果有描述则显示
 		if todo.Desc != "" {
 			result.WriteString(fmt.Sprintf("   描述: %s\n", todo.Desc))
@@ -174,27 +248,38 @@ func (t *TodoFind) formatTodoList(res []byte) (string, error) {
 	return result.String(), nil
 }
 
-// getTodoStatusName 获取待办状态名称（对标Java版本TodoStatus枚举）
-// Java版本: TodoStatus.java
-// PENDING(1, "待处理"), IN_PROGRESS(2, "进行中"), FINISHED(3, "已完成"),
-// CANCELLED(4, "已取消"), TIMEOUT(5, "已超时")
+// getTodoStatusName 获取待办状态名称，与 model 中的待办状态一致
 func getTodoStatusName(status int) string {
 	switch status {
-	case 1:
+	case model.TodoPending:
 		return "待处理"
-	case 2:
+	case model.TodoInProgress:
 		return "进行中"
-	case 3:
+	case model.TodoFinished:
 		return "已完成"
-	case 4:
-		return "已取消"
-	case 5:
-		return "已超时"
+	case model.TodoOverdue:
+		return "已逾期"
 	default:
 		return "未知状态"
 	}
 }
 
+// getTodoPriorityName 获取待办优先级名称
+func getTodoPriorityName(priority int) string {
+	switch priority {
+	case model.TodoPriorityLow:
+		return "低"
+	case model.TodoPriorityMedium:
+		return "中"
+	case model.TodoPriorityHigh:
+		return "高"
+	case model.TodoPriorityUrgent:
+		return "紧急"
+	default:
+		return "无"
+	}
+}
+
 // formatTodoTimestamp 格式化待办时间戳（对标Java版本第349-358行）
 func formatTodoTimestamp(timestamp int64) string {
 	if timestamp == 0 {
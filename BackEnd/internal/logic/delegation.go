@@ -0,0 +1,356 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"BackEnd/internal/domain"
+	"BackEnd/internal/model"
+	"BackEnd/internal/svc"
+	"BackEnd/pkg/token"
+	"BackEnd/pkg/util"
+	"BackEnd/pkg/xerr"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// maxDelegationDepth 委托链的最大长度，防止代理人之间互相委托
+const maxDelegationDepth = 5
+
+// Delegation 审批委托（出差、休假期间由代理人审批）
+type Delegation interface {
+	// List 查询我设置的委托和委托给我的审批
+	List(ctx context.Context, req *domain.DelegationListReq) (resp *domain.DelegationListResp, err error)
+	// Create 设置委托，已生效的委托会立即转交当前待我审批的步骤
+	Create(ctx context.Context, req *domain.Delegation) (resp *domain.IdResp, err error)
+	// Cancel 取消委托，尚未处理的步骤退回给委托人
+	Cancel(ctx context.Context, req *domain.IdPathReq) (err error)
+	// Reclaim 委托人收回已转交的待审批步骤，ApprovalId 为空时收回全部
+	Reclaim(ctx context.Context, req *domain.ApprovalActionReq) (err error)
+	// Apply 将生效中的委托应用到待审批步骤，由后台任务定时执行
+	Apply(ctx context.Context) (err error)
+}
+
+type delegation struct {
+	svcCtx *svc.ServiceContext
+}
+
+func NewDelegation(svcCtx *svc.ServiceContext) Delegation {
+	return &delegation{
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *delegation) List(ctx context.Context, req *domain.DelegationListReq) (resp *domain.DelegationListResp, err error) {
+	uid, err := token.GetUserID(ctx)
+	if err != nil {
+		return nil, xerr.New(err)
+	}
+	pagination := util.NormalizePagination(req.Page, req.Count)
+
+	db := l.svcCtx.DB.WithContext(ctx).Model(&model.ApprovalDelegation{}).Where("user_id = ? OR delegate_id = ?", uid, uid)
+	if req.Active {
+		now := time.Now()
+		db = db.Where("start_at <= ? AND end_at >= ?", now, now)
+	}
+
+	var total int64
+	if err = db.Count(&total).Error; err != nil {
+		log.Error().Err(err).Msg("failed to count delegations")
+		return nil, xerr.New(err)
+	}
+
+	var list []*model.ApprovalDelegation
+	if err = db.Preload("User").Preload("Delegate").Order("id desc").
+		Offset(pagination.Offset).Limit(pagination.Count).Find(&list).Error; err != nil {
+		log.Error().Err(err).Msg("failed to list delegations")
+		return nil, xerr.New(err)
+	}
+
+	now := time.Now()
+	resp = &domain.DelegationListResp{Count: total, List: make([]*domain.Delegation, 0, len(list))}
+	for _, d := range list {
+		resp.List = append(resp.List, &domain.Delegation{
+			Id:           util.UintToString(d.ID),
+			UserId:       util.UintToString(d.UserID),
+			UserName:     d.User.Name,
+			DelegateId:   util.UintToString(d.DelegateID),
+			DelegateName: d.Delegate.Name,
+			Type:         int(d.Type),
+			StartTime:    d.StartAt.Unix(),
+			EndTime:      d.EndAt.Unix(),
+			Reason:       d.Reason,
+			Active:       d.Active(now),
+		})
+	}
+	return resp, nil
+}
+
+func (l *delegation) Create(ctx context.Context, req *domain.Delegation) (resp *domain.IdResp, err error) {
+	uid, err := token.GetUserID(ctx)
+	if err != nil {
+		return nil, xerr.New(err)
+	}
+
+	delegateID, err := util.StringToUint(req.DelegateId)
+	if err != nil || delegateID == 0 {
+		return nil, xerr.New(errors.New("invalid delegate id"))
+	}
+	if delegateID == uid {
+		return nil, xerr.New(errors.New("cannot delegate approvals to yourself"))
+	}
+	if req.EndTime <= req.StartTime {
+		return nil, xerr.New(errors.New("end time must be after start time"))
+	}
+	if req.Type != 0 && model.ApprovalType(req.Type).ToString() == "未知" {
+		return nil, xerr.New(errors.New("unknown approval type"))
+	}
+	if err := l.svcCtx.DB.WithContext(ctx).First(&model.User{}, delegateID).Error; err != nil {
+		return nil, xerr.New(errors.New("delegate not found"))
+	}
+
+	d := &model.ApprovalDelegation{
+		UserID:     uid,
+		DelegateID: delegateID,
+		Type:       model.ApprovalType(req.Type),
+		StartAt:    time.Unix(req.StartTime, 0),
+		EndAt:      time.Unix(req.EndTime, 0),
+		Reason:     req.Reason,
+	}
+
+	// 同一时间段内同一类型只能有一个代理人
+	var overlap int64
+	if err := l.svcCtx.DB.WithContext(ctx).Model(&model.ApprovalDelegation{}).
+		Where("user_id = ? AND start_at <= ? AND end_at >= ?", uid, d.EndAt, d.StartAt).
+		Where("type = 0 OR ? = 0 OR type = ?", d.Type, d.Type).
+		Count(&overlap).Error; err != nil {
+		return nil, xerr.New(err)
+	}
+	if overlap > 0 {
+		return nil, xerr.New(errors.New("an overlapping delegation already exists"))
+	}
+
+	if err := l.svcCtx.DB.WithContext(ctx).Create(d).Error; err != nil {
+		log.Error().Err(err).Msg("failed to create delegation")
+		return nil, xerr.New(err)
+	}
+
+	if d.Active(time.Now()) {
+		if err := l.Apply(ctx); err != nil {
+			log.Error().Err(err).Msg("failed to apply delegation")
+		}
+	}
+	return &domain.IdResp{Id: util.UintToString(d.ID)}, nil
+}
+
+func (l *delegation) Cancel(ctx context.Context, req *domain.IdPathReq) (err error) {
+	uid, err := token.GetUserID(ctx)
+	if err != nil {
+		return xerr.New(err)
+	}
+
+	id, err := util.StringToUint(req.Id)
+	if err != nil {
+		return xerr.New(errors.New("invalid delegation id"))
+	}
+
+	var d model.ApprovalDelegation
+	if err := l.svcCtx.DB.WithContext(ctx).First(&d, id).Error; err != nil {
+		return xerr.New(err)
+	}
+	if d.UserID != uid {
+		return xerr.New(errors.New("only the delegator can cancel this delegation"))
+	}
+
+	return l.svcCtx.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&d).Error; err != nil {
+			return xerr.New(err)
+		}
+		// 退回尚未处理的步骤
+		if err := returnDelegated(tx, uid, d.DelegateID, 0); err != nil {
+			log.Error().Err(err).Msg("failed to return delegated approvers")
+			return xerr.New(err)
+		}
+		return nil
+	})
+}
+
+func (l *delegation) Reclaim(ctx context.Context, req *domain.ApprovalActionReq) (err error) {
+	uid, err := token.GetUserID(ctx)
+	if err != nil {
+		return xerr.New(err)
+	}
+
+	var approvalID uint
+	if req.ApprovalId != "" {
+		if approvalID, err = util.StringToUint(req.ApprovalId); err != nil {
+			return xerr.New(errors.New("invalid approval id"))
+		}
+	}
+	err = l.svcCtx.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return returnDelegated(tx, uid, 0, approvalID)
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("failed to reclaim delegated approvers")
+		return xerr.New(err)
+	}
+	return nil
+}
+
+// returnDelegated 将委托出去且尚未处理的审批步骤退回给原审批人，并标记为不再自动委托，超时转交上级的步骤不退回
+// delegateID、approvalID 为 0 时不限代理人和审批单
+func returnDelegated(tx *gorm.DB, originalID, delegateID, approvalID uint) error {
+	q := tx.Model(&model.Approver{}).
+		Where("original_user_id = ? AND status = ? AND escalated_at IS NULL", originalID, model.Processed).
+		Where("approval_id IN (?)", tx.Model(&model.Approval{}).Select("id").Where("status = ?", model.Processed))
+	if delegateID > 0 {
+		q = q.Where("user_id = ?", delegateID)
+	}
+	if approvalID > 0 {
+		q = q.Where("approval_id = ?", approvalID)
+	}
+	var approvers []model.Approver
+	if err := q.Find(&approvers).Error; err != nil {
+		return err
+	}
+
+	for _, a := range approvers {
+		res := tx.Model(&model.Approver{}).Where("id = ? AND user_id = ? AND status = ?", a.ID, a.UserID, model.Processed).
+			Updates(map[string]any{
+				"user_id":          originalID,
+				"original_user_id": 0,
+				"reclaimed":        true,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			continue
+		}
+		if err := tx.Create(&model.ApprovalLog{
+			ApprovalID: a.ApprovalID,
+			ApproverID: a.ID,
+			UserID:     originalID,
+			TargetID:   a.UserID,
+			Action:     model.ActionReclaim,
+			Content:    "收回委托，由原审批人审批",
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addDelegationLog 在审批时间线上记录审批步骤按委托转交给了代理人
+func addDelegationLog(tx *gorm.DB, a *model.Approver) error {
+	return tx.Create(&model.ApprovalLog{
+		ApprovalID: a.ApprovalID,
+		ApproverID: a.ID,
+		UserID:     a.OriginalUserID,
+		TargetID:   a.UserID,
+		Action:     model.ActionDelegate,
+		Content:    "审批人委托代理人审批",
+	}).Error
+}
+
+func (l *delegation) Apply(ctx context.Context) (err error) {
+	db := l.svcCtx.DB.WithContext(ctx)
+	now := time.Now()
+
+	var delegators []uint
+	if err := db.Model(&model.ApprovalDelegation{}).
+		Where("start_at <= ? AND end_at >= ?", now, now).
+		Distinct("user_id").Pluck("user_id", &delegators).Error; err != nil {
+		return err
+	}
+	if len(delegators) == 0 {
+		return nil
+	}
+
+	// 委托人尚未处理、也没有被收回的审批步骤
+	var approvers []model.Approver
+	if err := db.Model(&model.Approver{}).
+		Joins("JOIN approvals ON approvals.id = approvers.approval_id AND approvals.deleted_at IS NULL").
+		Where("approvers.user_id IN ? AND approvers.status = ? AND approvers.reclaimed = ?", delegators, model.Processed, false).
+		Where("approvals.status = ?", model.Processed).
+		Select("approvers.*").
+		Find(&approvers).Error; err != nil {
+		return err
+	}
+
+	for _, a := range approvers {
+		var approval model.Approval
+		if err := db.Select("id", "type", "user_id").First(&approval, a.ApprovalID).Error; err != nil {
+			continue
+		}
+		delegate := resolveDelegate(db, a.UserID, approval.Type, approval.UserID, now)
+		if delegate == a.UserID {
+			continue
+		}
+		// 代理人在该审批单上已有待处理的步骤时不再委托，避免同一人占用多个审批节点
+		var pending int64
+		if err := db.Model(&model.Approver{}).Where("approval_id = ? AND user_id = ? AND status = ?", a.ApprovalID, delegate, model.Processed).
+			Count(&pending).Error; err != nil {
+			return err
+		}
+		if pending > 0 {
+			continue
+		}
+		original := a.UserID
+		if a.OriginalUserID > 0 {
+			original = a.OriginalUserID // 代理人再次委托时保留最初的审批人
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			res := tx.Model(&model.Approver{}).Where("id = ? AND user_id = ? AND status = ?", a.ID, a.UserID, model.Processed).
+				Updates(map[string]any{"user_id": delegate, "original_user_id": original})
+			if res.Error != nil || res.RowsAffected == 0 {
+				return res.Error
+			}
+			a.UserID, a.OriginalUserID = delegate, original
+			return addDelegationLog(tx, &a)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveDelegate 沿委托链查找 t 时刻实际处理 userID 审批的人，没有委托时返回 userID
+// 委托链回到申请人本人时不委托，避免自己审批自己的申请
+func resolveDelegate(db *gorm.DB, userID uint, typ model.ApprovalType, applicantID uint, t time.Time) uint {
+	current := userID
+	visited := map[uint]bool{userID: true}
+	for i := 0; i < maxDelegationDepth; i++ {
+		var d model.ApprovalDelegation
+		err := db.Where("user_id = ? AND start_at <= ? AND end_at >= ?", current, t, t).
+			Where("type = 0 OR type = ?", typ).
+			Order("type desc, id desc").
+			First(&d).Error
+		if err != nil || visited[d.DelegateID] {
+			break
+		}
+		visited[d.DelegateID] = true
+		current = d.DelegateID
+	}
+	if current == applicantID {
+		return userID
+	}
+	return current
+}
+
+// applyDelegations 提交审批时将审批人替换为生效中的代理人，代理人已是该审批单的审批人时不替换
+func applyDelegations(db *gorm.DB, approval *model.Approval, approvers []model.Approver) {
+	now := time.Now()
+	for i := range approvers {
+		delegate := resolveDelegate(db, approvers[i].UserID, approval.Type, approval.UserID, now)
+		if delegate != approvers[i].UserID &&
+			!slices.ContainsFunc(approvers, func(a model.Approver) bool { return a.UserID == delegate }) {
+			approvers[i].OriginalUserID = approvers[i].UserID
+			approvers[i].UserID = delegate
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"BackEnd/internal/domain"
+	"BackEnd/internal/model"
+	"BackEnd/internal/svc"
+	"BackEnd/pkg/formx"
+	"BackEnd/pkg/util"
+	"BackEnd/pkg/xerr"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+var formCodePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,31}$`)
+
+// Form 自定义审批表单，仅管理员可修改
+type Form interface {
+	// List 查询表单，普通用户只能看到已启用的表单
+	List(ctx context.Context, req *domain.ApprovalFormListReq) (resp *domain.ApprovalFormListResp, err error)
+	// Info 查询表单详情，普通用户只能查看已启用的表单
+	Info(ctx context.Context, req *domain.IdPathReq) (resp *domain.ApprovalForm, err error)
+	Create(ctx context.Context, req *domain.ApprovalForm) (resp *domain.IdResp, err error)
+	Edit(ctx context.Context, req *domain.ApprovalForm) (err error)
+	// Delete 删除表单，已提交的审批单保留提交时的表单定义
+	Delete(ctx context.Context, req *domain.IdPathReq) (err error)
+}
+
+type form struct {
+	svcCtx *svc.ServiceContext
+}
+
+func NewForm(svcCtx *svc.ServiceContext) Form {
+	return &form{
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *form) List(ctx context.Context, req *domain.ApprovalFormListReq) (resp *domain.ApprovalFormListResp, err error) {
+	pagination := util.NormalizePagination(req.Page, req.Count)
+
+	db := l.svcCtx.DB.WithContext(ctx).Model(&model.ApprovalForm{})
+	if !req.All || checkAdmin(ctx, l.svcCtx) != nil {
+		db = db.Where("enabled = ?", true)
+	}
+
+	var total int64
+	if err = db.Count(&total).Error; err != nil {
+		log.Error().Err(err).Msg("failed to count approval forms")
+		return nil, xerr.New(err)
+	}
+	var forms []*model.ApprovalForm
+	if err = db.Order("id").Offset(pagination.Offset).Limit(pagination.Count).Find(&forms).Error; err != nil {
+		log.Error().Err(err).Msg("failed to list approval forms")
+		return nil, xerr.New(err)
+	}
+
+	list := make([]*domain.ApprovalForm, 0, len(forms))
+	for _, f := range forms {
+		list = append(list, toDomainForm(f))
+	}
+	return &domain.ApprovalFormListResp{Count: total, List: list}, nil
+}
+
+func (l *form) Info(ctx context.Context, req *domain.IdPathReq) (resp *domain.ApprovalForm, err error) {
+	var f model.ApprovalForm
+	if err := l.svcCtx.DB.WithContext(ctx).First(&f, req.Id).Error; err != nil {
+		log.Error().Err(err).Str("id", req.Id).Msg("failed to find approval form")
+		return nil, xerr.New(err)
+	}
+	if !f.Enabled && checkAdmin(ctx, l.svcCtx) != nil {
+		return nil, xerr.New(errors.New("form not found or disabled"))
+	}
+	return toDomainForm(&f), nil
+}
+
+func (l *form) Create(ctx context.Context, req *domain.ApprovalForm) (resp *domain.IdResp, err error) {
+	if err := checkAdmin(ctx, l.svcCtx); err != nil {
+		return nil, err
+	}
+
+	f, err := toModelForm(req)
+	if err != nil {
+		return nil, xerr.New(err)
+	}
+	if err := l.checkCode(ctx, f.Code, 0); err != nil {
+		return nil, err
+	}
+	if err := l.svcCtx.DB.WithContext(ctx).Create(f).Error; err != nil {
+		log.Error().Err(err).Msg("failed to create approval form")
+		return nil, xerr.New(err)
+	}
+	return &domain.IdResp{Id: util.UintToString(f.ID)}, nil
+}
+
+func (l *form) Edit(ctx context.Context, req *domain.ApprovalForm) (err error) {
+	if err := checkAdmin(ctx, l.svcCtx); err != nil {
+		return err
+	}
+
+	id, err := util.StringToUint(req.Id)
+	if err != nil {
+		return xerr.New(errors.New("invalid form id"))
+	}
+	var old model.ApprovalForm
+	if err := l.svcCtx.DB.WithContext(ctx).First(&old, id).Error; err != nil {
+		return xerr.New(err)
+	}
+
+	f, err := toModelForm(req)
+	if err != nil {
+		return xerr.New(err)
+	}
+	if err := l.checkCode(ctx, f.Code, id); err != nil {
+		return err
+	}
+	f.Model = old.Model
+	if err := l.svcCtx.DB.WithContext(ctx).Save(f).Error; err != nil {
+		log.Error().Err(err).Msg("failed to update approval form")
+		return xerr.New(err)
+	}
+	return nil
+}
+
+func (l *form) Delete(ctx context.Context, req *domain.IdPathReq) (err error) {
+	if err := checkAdmin(ctx, l.svcCtx); err != nil {
+		return err
+	}
+	if err := l.svcCtx.DB.WithContext(ctx).Delete(&model.ApprovalForm{}, req.Id).Error; err != nil {
+		log.Error().Err(err).Str("id", req.Id).Msg("failed to delete approval form")
+		return xerr.New(err)
+	}
+	return nil
+}
+
+// checkCode 表单编码不能与其他表单重复，包括已删除的表单
+func (l *form) checkCode(ctx context.Context, code string, id uint) error {
+	var count int64
+	if err := l.svcCtx.DB.WithContext(ctx).Unscoped().Model(&model.ApprovalForm{}).
+		Where("code = ? AND id <> ?", code, id).Count(&count).Error; err != nil {
+		return xerr.New(err)
+	}
+	if count > 0 {
+		return xerr.New(fmt.Errorf("form code %q already exists", code))
+	}
+	return nil
+}
+
+// toModelForm 校验并转换表单定义
+func toModelForm(req *domain.ApprovalForm) (*model.ApprovalForm, error) {
+	f := &model.ApprovalForm{
+		Code:             strings.TrimSpace(req.Code),
+		Name:             strings.TrimSpace(req.Name),
+		Desc:             req.Desc,
+		AbstractTemplate: req.AbstractTemplate,
+		Enabled:          req.Enabled,
+	}
+	if !formCodePattern.MatchString(f.Code) {
+		return nil, fmt.Errorf("invalid form code %q, use lowercase letters, digits, - and _", req.Code)
+	}
+	if f.Name == "" {
+		return nil, errors.New("form name is required")
+	}
+	for _, field := range req.Fields {
+		if field == nil {
+			continue
+		}
+		f.Fields = append(f.Fields, model.FormField{
+			Key:       strings.TrimSpace(field.Key),
+			Label:     strings.TrimSpace(field.Label),
+			Type:      field.Type,
+			Required:  field.Required,
+			Options:   field.Options,
+			Min:       field.Min,
+			Max:       field.Max,
+			MaxLength: field.MaxLength,
+		})
+	}
+	if err := formx.CheckFields(toFormxFields(f.Fields)); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func toDomainForm(f *model.ApprovalForm) *domain.ApprovalForm {
+	return &domain.ApprovalForm{
+		Id:               util.UintToString(f.ID),
+		Code:             f.Code,
+		Name:             f.Name,
+		Desc:             f.Desc,
+		Fields:           toDomainFormFields(f.Fields),
+		AbstractTemplate: f.AbstractTemplate,
+		Enabled:          f.Enabled,
+		CreateAt:         f.CreatedAt.Unix(),
+		UpdateAt:         f.UpdatedAt.Unix(),
+	}
+}
+
+func toDomainFormFields(fields []model.FormField) []*domain.FormField {
+	res := make([]*domain.FormField, 0, len(fields))
+	for _, f := range fields {
+		res = append(res, &domain.FormField{
+			Key:       f.Key,
+			Label:     f.Label,
+			Type:      f.Type,
+			Required:  f.Required,
+			Options:   f.Options,
+			Min:       f.Min,
+			Max:       f.Max,
+			MaxLength: f.MaxLength,
+		})
+	}
+	return res
+}
+
+func toFormxFields(fields []model.FormField) []formx.Field {
+	res := make([]formx.Field, 0, len(fields))
+	for _, f := range fields {
+		res = append(res, formx.Field{
+			Key:       f.Key,
+			Label:     f.Label,
+			Type:      formx.FieldType(f.Type),
+			Required:  f.Required,
+			Options:   f.Options,
+			Min:       f.Min,
+			Max:       f.Max,
+			MaxLength: f.MaxLength,
+		})
+	}
+	return res
+}
+
+// findForm 按 ID 或编码查找已启用的表单
+func findForm(ctx context.Context, db *gorm.DB, id, code string) (*model.ApprovalForm, error) {
+	db = db.WithContext(ctx).Where("enabled = ?", true)
+	switch {
+	case id != "":
+		formID, err := util.StringToUint(id)
+		if err != nil {
+			return nil, errors.New("invalid form id")
+		}
+		db = db.Where("id = ?", formID)
+	case code != "":
+		db = db.Where("code = ?", code)
+	default:
+		return nil, errors.New("form id or code is required")
+	}
+	var f model.ApprovalForm
+	if err := db.First(&f).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("form not found or disabled")
+		}
+		return nil, err
+	}
+	return &f, nil
+}
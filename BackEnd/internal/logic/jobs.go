@@ -0,0 +1,66 @@
+package logic
+
+import (
+	"context"
+	"time"
+
+	"BackEnd/internal/svc"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Job 后台定时任务
+type Job struct {
+	Name      string
+	Interval  time.Duration
+	Immediate bool // 启动时先执行一次，再按间隔执行
+	Run       func(ctx context.Context) error
+}
+
+// Jobs 返回需要在 API 服务中定时执行的后台任务
+func Jobs(svcCtx *svc.ServiceContext) []Job {
+	return []Job{
+		{Name: "approval-delegation", Interval: time.Minute, Run: NewDelegation(svcCtx).Apply},
+		{Name: "approval-sla", Interval: 10 * time.Minute, Run: NewApproval(svcCtx).CheckSLA},
+		{Name: "todo-recurrence", Interval: 10 * time.Minute, Run: NewTodo(svcCtx).Recur},
+		{Name: "todo-remind", Interval: time.Minute, Run: NewTodo(svcCtx).Remind},
+		{Name: "todo-digest", Interval: 10 * time.Minute, Run: NewTodo(svcCtx).Digest},
+		{Name: "knowledge-index-sync", Interval: time.Minute, Run: NewKnowledge(svcCtx).SyncIndex},
+		{Name: "approval-start-backfill", Interval: time.Hour, Immediate: true, Run: NewReport(svcCtx).BackfillStartAt},
+	}
+}
+
+// RunJobs 按各自的间隔执行后台任务，直到 ctx 结束
+func RunJobs(ctx context.Context, jobs []Job) {
+	for _, job := range jobs {
+		go runJob(ctx, job)
+	}
+}
+
+func runJob(ctx context.Context, job Job) {
+	if job.Immediate {
+		runOnce(ctx, job)
+	}
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			runOnce(ctx, job)
+		}
+	}
+}
+
+// runOnce 执行一次任务，记录错误并从 panic 中恢复
+func runOnce(ctx context.Context, job Job) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error().Interface("panic", r).Str("job", job.Name).Msg("job panicked")
+		}
+	}()
+	if err := job.Run(ctx); err != nil {
+		log.Error().Err(err).Str("job", job.Name).Msg("job failed")
+	}
+}
@@ -0,0 +1,252 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"BackEnd/internal/domain"
+	"BackEnd/internal/logic/chatinternal/toolx"
+	"BackEnd/internal/model"
+	"BackEnd/internal/svc"
+	"BackEnd/pkg/search"
+	"BackEnd/pkg/xerr"
+
+	"github.com/rs/zerolog/log"
+	"github.com/segmentio/ksuid"
+)
+
+// snippetWidth 检索结果片段的最大字符数
+const snippetWidth = 200
+
+// 重新索引任务状态
+const (
+	ReindexRunning  = "running"
+	ReindexFinished = "finished"
+)
+
+type Knowledge interface {
+	// Search 知识库混合检索，直接返回排序后的片段，不经过大模型
+	Search(ctx context.Context, req *domain.KnowledgeSearchReq) (resp *domain.KnowledgeSearchResp, err error)
+	// Reindex 启动后台重新索引任务，同一时间只允许一个任务运行
+	Reindex(ctx context.Context, req *domain.KnowledgeReindexReq) (resp *domain.KnowledgeReindexJob, err error)
+	// ReindexStatus 查询重新索引任务进度
+	ReindexStatus(ctx context.Context, req *domain.IdPathReq) (resp *domain.KnowledgeReindexJob, err error)
+	// SyncIndex 将关键词索引与数据库中的文档块同步，加载其他实例或评测程序写入的文档块
+	SyncIndex(ctx context.Context) error
+}
+
+type knowledge struct {
+	svcCtx   *svc.ServiceContext
+	searcher *toolx.KnowledgeSearcher
+	indexer  *toolx.KnowledgeIndexer
+
+	mu      sync.RWMutex
+	jobs    map[string]*domain.KnowledgeReindexJob // 任务ID -> 任务进度（仅保存在内存中）
+	running string                                 // 正在运行的任务ID
+}
+
+func NewKnowledge(svcCtx *svc.ServiceContext) Knowledge {
+	return &knowledge{
+		svcCtx:   svcCtx,
+		searcher: toolx.NewKnowledgeSearcher(svcCtx),
+		indexer:  toolx.NewKnowledgeIndexer(svcCtx),
+		jobs:     make(map[string]*domain.KnowledgeReindexJob),
+	}
+}
+
+func (l *knowledge) Search(ctx context.Context, req *domain.KnowledgeSearchReq) (resp *domain.KnowledgeSearchResp, err error) {
+	query := strings.TrimSpace(req.Query)
+	if query == "" {
+		return nil, xerr.New(errors.New("query is required"))
+	}
+
+	hits, err := l.searcher.Search(ctx, query, req.Count)
+	if err != nil {
+		log.Error().Err(err).Str("query", query).Msg("failed to search knowledge")
+		return nil, xerr.New(err)
+	}
+
+	list := make([]*domain.KnowledgeSnippet, 0, len(hits))
+	for _, h := range hits {
+		list = append(list, &domain.KnowledgeSnippet{
+			ChunkId:     h.ChunkID,
+			Source:      h.Source,
+			Snippet:     search.Snippet(h.Content, query, snippetWidth),
+			Score:       h.Score,
+			KeywordRank: h.KeywordRank,
+			VectorRank:  h.VectorRank,
+		})
+	}
+
+	return &domain.KnowledgeSearchResp{
+		Count: int64(len(list)),
+		List:  list,
+	}, nil
+}
+
+func (l *knowledge) Reindex(ctx context.Context, req *domain.KnowledgeReindexReq) (resp *domain.KnowledgeReindexJob, err error) {
+	if err := checkAdmin(ctx, l.svcCtx); err != nil {
+		return nil, err
+	}
+
+	// 1. 确定需要重新索引的文档及其类型
+	docTypes := make(map[string]string)
+	var paths []string
+	if len(req.Paths) > 0 {
+		// 只允许已经索引过的文档和上传目录下的文件
+		for _, p := range req.Paths {
+			var count int64
+			if err := l.svcCtx.DB.WithContext(ctx).Model(&model.KnowledgeDocument{}).
+				Where("source = ?", p).Count(&count).Error; err != nil {
+				return nil, xerr.New(err)
+			}
+			if count == 0 {
+				if p, err = resolveUploadPath(l.svcCtx.Config.Upload.SavePath, p); err != nil {
+					return nil, xerr.New(err)
+				}
+			}
+			paths = append(paths, p)
+			docTypes[p] = req.DocType
+		}
+	} else {
+		var docs []model.KnowledgeDocument
+		if err := l.svcCtx.DB.WithContext(ctx).Find(&docs).Error; err != nil {
+			log.Error().Err(err).Msg("failed to list knowledge documents")
+			return nil, xerr.New(err)
+		}
+		for _, d := range docs {
+			paths = append(paths, d.Source)
+			docTypes[d.Source] = d.DocType
+			if req.DocType != "" {
+				docTypes[d.Source] = req.DocType
+			}
+		}
+	}
+
+	// 2. 登记任务
+	l.mu.Lock()
+	if l.running != "" {
+		l.mu.Unlock()
+		return nil, xerr.New(errors.New("a re-index job is already running: " + l.running))
+	}
+	job := &domain.KnowledgeReindexJob{
+		Id:      ksuid.New().String(),
+		Status:  ReindexRunning,
+		Total:   len(paths),
+		StartAt: time.Now().Unix(),
+	}
+	l.jobs[job.Id] = job
+	l.running = job.Id
+	snapshot := *job
+	l.mu.Unlock()
+
+	// 3. 后台执行，请求结束不影响任务
+	go l.runReindex(job, paths, docTypes, req.Force)
+
+	return &snapshot, nil
+}
+
+// runReindex 逐个文档执行增量索引并更新任务进度
+func (l *knowledge) runReindex(job *domain.KnowledgeReindexJob, paths []string, docTypes map[string]string, force bool) {
+	ctx := context.Background()
+
+	for _, p := range paths {
+		res, err := l.indexer.IndexFile(ctx, p, docTypes[p], force)
+
+		l.mu.Lock()
+		job.Processed++
+		if err != nil {
+			job.Failed++
+			job.Errors = append(job.Errors, p+": "+err.Error())
+		} else {
+			switch res.Status {
+			case toolx.IndexSkipped:
+				job.Skipped++
+			case toolx.IndexCreated:
+				job.Created++
+			case toolx.IndexUpdated:
+				job.Updated++
+			}
+		}
+		l.mu.Unlock()
+
+		if err != nil {
+			log.Error().Err(err).Str("job", job.Id).Str("source", p).Msg("failed to re-index knowledge document")
+		}
+	}
+
+	l.mu.Lock()
+	job.Status = ReindexFinished
+	job.FinishAt = time.Now().Unix()
+	l.running = ""
+	l.mu.Unlock()
+
+	log.Info().Str("job", job.Id).Int("total", job.Total).Int("failed", job.Failed).Msg("knowledge re-index job finished")
+}
+
+func (l *knowledge) ReindexStatus(ctx context.Context, req *domain.IdPathReq) (resp *domain.KnowledgeReindexJob, err error) {
+	if err := checkAdmin(ctx, l.svcCtx); err != nil {
+		return nil, err
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	job, ok := l.jobs[req.Id]
+	if !ok {
+		return nil, xerr.New(errors.New("re-index job not found"))
+	}
+
+	snapshot := *job
+	snapshot.Errors = append([]string(nil), job.Errors...)
+	return &snapshot, nil
+}
+
+// syncBatch 同步关键词索引时每批加载的文档块数量
+const syncBatch = 500
+
+func (l *knowledge) SyncIndex(ctx context.Context) error {
+	idx := l.svcCtx.KnowledgeIndex
+	if idx == nil {
+		return nil
+	}
+	var ids []uint
+	if err := l.svcCtx.DB.WithContext(ctx).Model(&model.KnowledgeChunk{}).Order("id").Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	current := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		current[strconv.Itoa(int(id))] = true
+	}
+
+	indexed := make(map[string]bool)
+	for _, id := range idx.IDs() {
+		indexed[id] = true
+		if !current[id] {
+			idx.Remove(id)
+		}
+	}
+	var missing []uint
+	for _, id := range ids {
+		if !indexed[strconv.Itoa(int(id))] {
+			missing = append(missing, id)
+		}
+	}
+
+	for start := 0; start < len(missing); start += syncBatch {
+		end := min(start+syncBatch, len(missing))
+		var chunks []model.KnowledgeChunk
+		if err := l.svcCtx.DB.WithContext(ctx).Select("id", "content").
+			Where("id IN ?", missing[start:end]).Find(&chunks).Error; err != nil {
+			return err
+		}
+		for _, c := range chunks {
+			idx.Add(strconv.Itoa(int(c.ID)), c.Content)
+		}
+	}
+	return nil
+}
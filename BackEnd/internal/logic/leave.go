@@ -0,0 +1,454 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"BackEnd/internal/config"
+	"BackEnd/internal/domain"
+	"BackEnd/internal/model"
+	"BackEnd/internal/svc"
+	"BackEnd/pkg/token"
+	"BackEnd/pkg/util"
+	"BackEnd/pkg/workcal"
+	"BackEnd/pkg/xerr"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Leave 假期余额与流水
+type Leave interface {
+	// Balance 查询假期余额，只返回配置了额度规则的假期类型
+	Balance(ctx context.Context, req *domain.LeaveBalanceReq) (resp *domain.LeaveBalanceResp, err error)
+	// Ledger 查询假期余额流水
+	Ledger(ctx context.Context, req *domain.LeaveLedgerListReq) (resp *domain.LeaveLedgerListResp, err error)
+	// Adjust 管理员调整假期额度
+	Adjust(ctx context.Context, req *domain.LeaveAdjustReq) (err error)
+}
+
+type leave struct {
+	svcCtx *svc.ServiceContext
+}
+
+func NewLeave(svcCtx *svc.ServiceContext) Leave {
+	return &leave{
+		svcCtx: svcCtx,
+	}
+}
+
+// leaveTarget 解析要查询的用户，查询他人时需要管理员权限
+func (l *leave) leaveTarget(ctx context.Context, userId string) (uint, error) {
+	uid, err := token.GetUserID(ctx)
+	if err != nil {
+		return 0, xerr.New(err)
+	}
+	if userId == "" || userId == util.UintToString(uid) {
+		return uid, nil
+	}
+	if err := checkAdmin(ctx, l.svcCtx); err != nil {
+		return 0, err
+	}
+	target, err := util.StringToUint(userId)
+	if err != nil {
+		return 0, xerr.New(errors.New("invalid user id"))
+	}
+	return target, nil
+}
+
+func (l *leave) Balance(ctx context.Context, req *domain.LeaveBalanceReq) (resp *domain.LeaveBalanceResp, err error) {
+	uid, err := l.leaveTarget(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	year := req.Year
+	if year == 0 {
+		year = time.Now().Year()
+	}
+
+	resp = &domain.LeaveBalanceResp{List: make([]*domain.LeaveBalance, 0)}
+	for _, rule := range l.svcCtx.Config.Leave.Rules {
+		if req.Type > 0 && rule.Type != req.Type {
+			continue
+		}
+		var b *model.LeaveBalance
+		err := l.svcCtx.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) (err error) {
+			b, err = lockLeaveBalance(tx, uid, model.LeaveType(rule.Type), year, rule)
+			return err
+		})
+		if err != nil {
+			log.Error().Err(err).Uint("userID", uid).Msg("failed to load leave balance")
+			return nil, xerr.New(err)
+		}
+		resp.List = append(resp.List, toDomainLeaveBalance(b))
+	}
+	return resp, nil
+}
+
+func (l *leave) Ledger(ctx context.Context, req *domain.LeaveLedgerListReq) (resp *domain.LeaveLedgerListResp, err error) {
+	uid, err := l.leaveTarget(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	pagination := util.NormalizePagination(req.Page, req.Count)
+
+	db := l.svcCtx.DB.WithContext(ctx).Model(&model.LeaveLedger{}).Where("user_id = ?", uid)
+	if req.Type > 0 {
+		db = db.Where("type = ?", req.Type)
+	}
+	if req.Year > 0 {
+		db = db.Where("year = ?", req.Year)
+	}
+
+	var total int64
+	if err = db.Count(&total).Error; err != nil {
+		log.Error().Err(err).Msg("failed to count leave ledger")
+		return nil, xerr.New(err)
+	}
+
+	var list []*model.LeaveLedger
+	if err = db.Order("id desc").Offset(pagination.Offset).Limit(pagination.Count).Find(&list).Error; err != nil {
+		log.Error().Err(err).Msg("failed to list leave ledger")
+		return nil, xerr.New(err)
+	}
+
+	resp = &domain.LeaveLedgerListResp{Count: total, List: make([]*domain.LeaveLedger, 0, len(list))}
+	for _, e := range list {
+		item := &domain.LeaveLedger{
+			Id:        util.UintToString(e.ID),
+			Type:      int(e.Type),
+			TypeName:  e.Type.ToString(),
+			Year:      e.Year,
+			Kind:      string(e.Kind),
+			Days:      e.Days,
+			Available: e.Available,
+			Remark:    e.Remark,
+			CreateAt:  e.CreatedAt.Unix(),
+		}
+		if e.ApprovalID > 0 {
+			item.ApprovalId = util.UintToString(e.ApprovalID)
+		}
+		resp.List = append(resp.List, item)
+	}
+	return resp, nil
+}
+
+func (l *leave) Adjust(ctx context.Context, req *domain.LeaveAdjustReq) (err error) {
+	if err := checkAdmin(ctx, l.svcCtx); err != nil {
+		return err
+	}
+	operator, _ := token.GetUserID(ctx)
+
+	uid, err := util.StringToUint(req.UserId)
+	if err != nil || uid == 0 {
+		return xerr.New(errors.New("invalid user id"))
+	}
+	rule, ok := leaveRule(l.svcCtx.Config, model.LeaveType(req.Type))
+	if !ok {
+		return xerr.New(fmt.Errorf("%s has no balance rule", model.LeaveType(req.Type).ToString()))
+	}
+	if req.Days == 0 {
+		return xerr.New(errors.New("days must not be zero"))
+	}
+	year := req.Year
+	if year == 0 {
+		year = time.Now().Year()
+	}
+
+	return l.svcCtx.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		b, err := lockLeaveBalance(tx, uid, model.LeaveType(req.Type), year, rule)
+		if err != nil {
+			return xerr.New(err)
+		}
+		if b.Total+req.Days < b.Used+b.Frozen {
+			return xerr.New(errors.New("adjustment would make the balance negative"))
+		}
+		b.Total += req.Days
+		if err := saveLeaveBalance(tx, b, model.LedgerAdjust, req.Days, 0, operator, req.Remark); err != nil {
+			log.Error().Err(err).Msg("failed to adjust leave balance")
+			return xerr.New(err)
+		}
+		return nil
+	})
+}
+
+func toDomainLeaveBalance(b *model.LeaveBalance) *domain.LeaveBalance {
+	return &domain.LeaveBalance{
+		Type:      int(b.Type),
+		TypeName:  b.Type.ToString(),
+		Year:      b.Year,
+		Total:     b.Total,
+		Used:      b.Used,
+		Frozen:    b.Frozen,
+		Available: b.Available(),
+	}
+}
+
+// leaveRule 查找假期类型的额度规则，没有规则的假期不限额
+func leaveRule(c config.Config, typ model.LeaveType) (config.LeaveRuleConfig, bool) {
+	for _, r := range c.Leave.Rules {
+		if model.LeaveType(r.Type) == typ {
+			return r, true
+		}
+	}
+	return config.LeaveRuleConfig{}, false
+}
+
+// leaveEntitlement 计算用户在 year 年的额度，工龄按入职（注册）时间到当年1月1日的整年数计算
+func leaveEntitlement(rule config.LeaveRuleConfig, joinedAt time.Time, year int) float64 {
+	days := rule.Days
+	if rule.IncreasePerYear > 0 && !joinedAt.IsZero() {
+		if years := year - joinedAt.Year() - 1; years > 0 {
+			days += float64(years) * rule.IncreasePerYear
+		}
+	}
+	if rule.MaxDays > 0 && days > rule.MaxDays {
+		days = rule.MaxDays
+	}
+	return days
+}
+
+// lockLeaveBalance 加锁读取假期余额，当年首次使用时按规则发放额度
+func lockLeaveBalance(tx *gorm.DB, uid uint, typ model.LeaveType, year int, rule config.LeaveRuleConfig) (*model.LeaveBalance, error) {
+	var b model.LeaveBalance
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND type = ? AND year = ?", uid, typ, year).First(&b).Error
+	if err == nil {
+		return &b, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	var user model.User
+	if err := tx.First(&user, uid).Error; err != nil {
+		return nil, err
+	}
+	b = model.LeaveBalance{UserID: uid, Type: typ, Year: year, Total: leaveEntitlement(rule, user.CreatedAt, year)}
+	res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&b)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected > 0 {
+		if err := tx.Create(&model.LeaveLedger{
+			UserID: uid, Type: typ, Year: year, Kind: model.LedgerAccrual,
+			Days: b.Total, Available: b.Available(), Remark: fmt.Sprintf("%d年度额度", year),
+		}).Error; err != nil {
+			return nil, err
+		}
+	}
+	// 并发发放时以已写入的记录为准
+	b = model.LeaveBalance{}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND type = ? AND year = ?", uid, typ, year).First(&b).Error; err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// saveLeaveBalance 保存余额并记录流水
+func saveLeaveBalance(tx *gorm.DB, b *model.LeaveBalance, kind model.LedgerKind, days float64, approvalID, operatorID uint, remark string) error {
+	if err := tx.Model(b).Select("total", "used", "frozen").Updates(b).Error; err != nil {
+		return err
+	}
+	return tx.Create(&model.LeaveLedger{
+		UserID:     b.UserID,
+		Type:       b.Type,
+		Year:       b.Year,
+		Kind:       kind,
+		Days:       days,
+		Available:  b.Available(),
+		ApprovalID: approvalID,
+		OperatorID: operatorID,
+		Remark:     remark,
+	}).Error
+}
+
+// leaveDays 将请假时长折算为天，保留两位小数
+func leaveDays(c config.Config, lv *model.Leave) float64 {
+	days := float64(lv.Duration)
+	if lv.TimeType == model.HourTimeFormatType {
+		days /= hoursPerDay(c)
+	}
+	return math.Round(days*100) / 100
+}
+
+// yearDays 某一年度的请假天数
+type yearDays struct {
+	year int
+	days float64
+}
+
+// leaveYearDays 将请假天数按年度拆分，跨年的请假按各年内的工作时长分摊
+func leaveYearDays(cal *workcal.Calendar, days float64, lv *model.Leave) []yearDays {
+	loc := cal.Location()
+	start, end := time.Unix(lv.StartTime, 0).In(loc), time.Unix(lv.EndTime, 0).In(loc)
+	if end.Year() <= start.Year() {
+		return []yearDays{{year: start.Year(), days: days}}
+	}
+
+	weights := make([]float64, 0, end.Year()-start.Year()+1)
+	var total float64
+	for y := start.Year(); y <= end.Year(); y++ {
+		from := time.Date(y, 1, 1, 0, 0, 0, 0, loc)
+		to := from.AddDate(1, 0, 0)
+		if from.Before(start) {
+			from = start
+		}
+		if to.After(end) {
+			to = end
+		}
+		w := cal.WorkHours(from, to)
+		weights = append(weights, w)
+		total += w
+	}
+	if total == 0 {
+		return []yearDays{{year: start.Year(), days: days}}
+	}
+
+	// 按比例分摊并保留两位小数，最后一年取剩余天数，保证合计不变
+	var res []yearDays
+	left := days
+	for i, w := range weights {
+		d := math.Round(days*w/total*100) / 100
+		if i == len(weights)-1 {
+			d = math.Round(left*100) / 100
+		}
+		left -= d
+		if d > 0 {
+			res = append(res, yearDays{year: start.Year() + i, days: d})
+		}
+	}
+	return res
+}
+
+// freezeLeave 提交请假时校验并冻结余额，跨年的请假分别冻结各年度的余额，没有额度规则的假期不冻结
+func freezeLeave(tx *gorm.DB, c config.Config, cal *workcal.Calendar, approval *model.Approval) error {
+	if approval.Type != model.LeaveApproval || approval.Leave == nil {
+		return nil
+	}
+	rule, ok := leaveRule(c, approval.Leave.Type)
+	if !ok {
+		return nil
+	}
+	days := leaveDays(c, approval.Leave)
+	if days <= 0 {
+		return nil
+	}
+	for _, yd := range leaveYearDays(cal, days, approval.Leave) {
+		b, err := lockLeaveBalance(tx, approval.UserID, approval.Leave.Type, yd.year, rule)
+		if err != nil {
+			return err
+		}
+		if b.Available() < yd.days {
+			return fmt.Errorf("insufficient %s balance for %d: %.2f days left, %.2f days requested",
+				b.Type.ToString(), yd.year, b.Available(), yd.days)
+		}
+		b.Frozen += yd.days
+		if err := saveLeaveBalance(tx, b, model.LedgerFreeze, -yd.days, approval.ID, approval.UserID, approval.No); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// settleLeave 请假审批结束后结算冻结的余额：通过时扣减，驳回或撤回时解冻，已通过的请假撤销时返还
+// 按审批单的余额流水结算，与冻结时记录的年度和天数一致，不受之后配置变化的影响；没有冻结记录时不结算
+func settleLeave(tx *gorm.DB, approval *model.Approval, from, to model.ApprovalStatus) error {
+	if approval.Type != model.LeaveApproval || approval.Leave == nil {
+		return nil
+	}
+	var entries []model.LeaveLedger
+	if err := tx.Where("approval_id = ? AND kind IN ?", approval.ID,
+		[]model.LedgerKind{model.LedgerFreeze, model.LedgerUnfreeze, model.LedgerDeduct, model.LedgerRestore}).
+		Order("id").Find(&entries).Error; err != nil {
+		return err
+	}
+
+	// 各年度当前仍冻结和已扣减的天数，流水中冻结、扣减为负数，解冻、返还为正数
+	frozen := make(map[int]float64)
+	used := make(map[int]float64)
+	var years []int
+	for _, e := range entries {
+		if _, ok := frozen[e.Year]; !ok {
+			years = append(years, e.Year)
+			frozen[e.Year] = 0
+		}
+		switch e.Kind {
+		case model.LedgerFreeze, model.LedgerUnfreeze:
+			frozen[e.Year] -= e.Days
+		case model.LedgerDeduct:
+			frozen[e.Year] += e.Days
+			used[e.Year] -= e.Days
+		case model.LedgerRestore:
+			used[e.Year] -= e.Days
+		}
+	}
+
+	for _, year := range years {
+		days := math.Round(frozen[year]*100) / 100
+		var (
+			kind     model.LedgerKind
+			operator uint
+		)
+		switch {
+		case from == model.Processed && to == model.Pass:
+			kind = model.LedgerDeduct
+		case from == model.Processed && (to == model.Refuse || to == model.Cancel):
+			kind = model.LedgerUnfreeze
+		case from == model.Pass && to == model.Cancel:
+			kind, days, operator = model.LedgerRestore, math.Round(used[year]*100)/100, approval.UserID
+		default:
+			return nil
+		}
+		if days <= 0 {
+			continue
+		}
+
+		var b model.LeaveBalance
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND type = ? AND year = ?", approval.UserID, approval.Leave.Type, year).
+			First(&b).Error; err != nil {
+			return err
+		}
+		switch kind {
+		case model.LedgerDeduct:
+			b.Frozen -= days
+			b.Used += days
+			days = -days
+		case model.LedgerUnfreeze:
+			b.Frozen -= days
+		case model.LedgerRestore:
+			b.Used -= days
+		}
+		if err := saveLeaveBalance(tx, &b, kind, days, approval.ID, operator, approval.No); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// creditOvertime 选择调休补偿的加班审批通过后，将加班时长折算为天计入当年的调休余额
+func creditOvertime(tx *gorm.DB, c config.Config, approval *model.Approval) error {
+	if approval.Status != model.Pass || approval.Type != model.OvertimeApproval || approval.Overtime == nil ||
+		approval.Overtime.Compensation != model.RestCompensation {
+		return nil
+	}
+	days := math.Round(float64(approval.Overtime.Duration)/hoursPerDay(c)*100) / 100
+	if days <= 0 {
+		return nil
+	}
+	// 调休未配置额度规则时从 0 开始累计
+	rule, _ := leaveRule(c, model.Rest)
+	year := time.Unix(approval.Overtime.StartTime, 0).Year()
+	b, err := lockLeaveBalance(tx, approval.UserID, model.Rest, year, rule)
+	if err != nil {
+		return err
+	}
+	b.Total += days
+	return saveLeaveBalance(tx, b, model.LedgerOvertime, days, approval.ID, 0, approval.No)
+}
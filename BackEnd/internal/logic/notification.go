@@ -0,0 +1,120 @@
+package logic
+
+import (
+	"context"
+	"time"
+
+	"BackEnd/internal/domain"
+	"BackEnd/internal/model"
+	"BackEnd/internal/svc"
+	"BackEnd/pkg/token"
+	"BackEnd/pkg/util"
+	"BackEnd/pkg/xerr"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// Notification 站内通知
+type Notification interface {
+	List(ctx context.Context, req *domain.NotificationListReq) (resp *domain.NotificationListResp, err error)
+	// Read 标记通知为已读，Ids 为空时标记全部
+	Read(ctx context.Context, req *domain.NotificationReadReq) (err error)
+}
+
+type notification struct {
+	svcCtx *svc.ServiceContext
+}
+
+func NewNotification(svcCtx *svc.ServiceContext) Notification {
+	return &notification{
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *notification) List(ctx context.Context, req *domain.NotificationListReq) (resp *domain.NotificationListResp, err error) {
+	uid, err := token.GetUserID(ctx)
+	if err != nil {
+		return nil, xerr.New(err)
+	}
+	pagination := util.NormalizePagination(req.Page, req.Count)
+
+	db := l.svcCtx.DB.WithContext(ctx).Model(&model.Notification{}).Where("user_id = ?", uid)
+	if req.Unread {
+		db = db.Where("read_at IS NULL")
+	}
+	if req.Type != "" {
+		db = db.Where("type = ?", req.Type)
+	}
+
+	var total int64
+	if err = db.Count(&total).Error; err != nil {
+		log.Error().Err(err).Msg("failed to count notifications")
+		return nil, xerr.New(err)
+	}
+
+	var unread int64
+	if err = l.svcCtx.DB.WithContext(ctx).Model(&model.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", uid).Count(&unread).Error; err != nil {
+		return nil, xerr.New(err)
+	}
+
+	var list []*model.Notification
+	if err = db.Order("id desc").Offset(pagination.Offset).Limit(pagination.Count).Find(&list).Error; err != nil {
+		log.Error().Err(err).Msg("failed to list notifications")
+		return nil, xerr.New(err)
+	}
+
+	resp = &domain.NotificationListResp{
+		Count:  total,
+		Unread: unread,
+		List:   make([]*domain.Notification, 0, len(list)),
+	}
+	for _, n := range list {
+		item := &domain.Notification{
+			Id:       util.UintToString(n.ID),
+			Type:     string(n.Type),
+			Title:    n.Title,
+			Content:  n.Content,
+			BizId:    util.UintToString(n.BizID),
+			CreateAt: n.CreatedAt.Unix(),
+		}
+		if n.ReadAt != nil {
+			item.ReadAt = n.ReadAt.Unix()
+		}
+		resp.List = append(resp.List, item)
+	}
+	return resp, nil
+}
+
+func (l *notification) Read(ctx context.Context, req *domain.NotificationReadReq) (err error) {
+	uid, err := token.GetUserID(ctx)
+	if err != nil {
+		return xerr.New(err)
+	}
+
+	db := l.svcCtx.DB.WithContext(ctx).Model(&model.Notification{}).Where("user_id = ? AND read_at IS NULL", uid)
+	if len(req.Ids) > 0 {
+		db = db.Where("id IN ?", util.StringToUintSlice(req.Ids))
+	}
+	if err := db.Update("read_at", time.Now()).Error; err != nil {
+		log.Error().Err(err).Msg("failed to mark notifications read")
+		return xerr.New(err)
+	}
+	return nil
+}
+
+// notify 给用户发送站内通知，tx 可以是事务，使通知与业务数据一起提交
+func notify(tx *gorm.DB, n model.Notification, uids ...uint) error {
+	uids = uniqueIDs(uids)
+	if len(uids) == 0 {
+		return nil
+	}
+	list := make([]model.Notification, 0, len(uids))
+	for _, uid := range uids {
+		item := n
+		item.UserID = uid
+		list = append(list, item)
+	}
+	return tx.Create(&list).Error
+}
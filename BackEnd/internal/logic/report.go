@@ -0,0 +1,417 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"BackEnd/internal/domain"
+	"BackEnd/internal/model"
+	"BackEnd/internal/svc"
+	"BackEnd/pkg/util"
+	"BackEnd/pkg/workcal"
+	"BackEnd/pkg/xerr"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// 报表分组方式和导出的报表
+const (
+	reportGroupByUser       = "user"
+	reportGroupByDepartment = "department"
+
+	reportAttendance = "attendance"
+	reportTurnaround = "turnaround"
+)
+
+// maxReportMonths 一次最多统计的月数
+const maxReportMonths = 12
+
+// leaveTypes 报表中按顺序列出的请假类型
+var leaveTypes = []model.LeaveType{
+	model.Matter, model.Rest, model.Fall, model.Annual, model.Maternity,
+	model.Paternity, model.Marriage, model.Funeral, model.Breastfeeding,
+}
+
+// Report 审批统计报表（管理员），数据来自已通过的审批单
+type Report interface {
+	// Attendance 考勤异常统计：按月份和人员或部门汇总请假天数、外出小时数、补卡次数、加班小时数和出差天数
+	Attendance(ctx context.Context, req *domain.ReportReq) (resp *domain.AttendanceReportResp, err error)
+	// Turnaround 审批时效统计：按审批人汇总处理数量和平均处理时长
+	Turnaround(ctx context.Context, req *domain.ReportReq) (resp *domain.TurnaroundReportResp, err error)
+	// Export 导出报表为 CSV 或 XLSX 文件
+	Export(ctx context.Context, req *domain.ReportExportReq) (filename string, data []byte, err error)
+	// BackfillStartAt 为记录业务开始时间之前提交的审批单补充业务开始时间
+	BackfillStartAt(ctx context.Context) error
+}
+
+type report struct {
+	svcCtx *svc.ServiceContext
+}
+
+func NewReport(svcCtx *svc.ServiceContext) Report {
+	return &report{
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *report) Attendance(ctx context.Context, req *domain.ReportReq) (resp *domain.AttendanceReportResp, err error) {
+	if err := checkAdmin(ctx, l.svcCtx); err != nil {
+		return nil, err
+	}
+	start, end, err := l.monthRange(req)
+	if err != nil {
+		return nil, xerr.New(err)
+	}
+	users, err := l.reportUsers(ctx, req.DepartmentId)
+	if err != nil {
+		return nil, xerr.New(err)
+	}
+
+	// 按业务开始时间统计，没有业务时间的审批单（如自定义表单）按完成时间筛选，历史审批单由 BackfillStartAt 补充业务时间
+	db := l.svcCtx.DB.WithContext(ctx).Preload("User").
+		Where("status = ?", model.Pass).
+		Where("(start_at >= ? AND start_at < ?) OR (start_at = 0 AND finish_at >= ? AND finish_at < ?)",
+			start.Unix(), end.Unix(), start, end)
+	if users != nil {
+		db = db.Where("user_id IN ?", users)
+	}
+	var approvals []*model.Approval
+	if err := db.Order("start_at").Order("finish_at").Find(&approvals).Error; err != nil {
+		log.Error().Err(err).Msg("failed to query approvals for report")
+		return nil, xerr.New(err)
+	}
+
+	uids := make([]uint, 0, len(approvals))
+	for _, a := range approvals {
+		uids = append(uids, a.UserID)
+	}
+	depts, err := l.userDepartments(ctx, uniqueIDs(uids))
+	if err != nil {
+		return nil, xerr.New(err)
+	}
+
+	byDept := req.GroupBy == reportGroupByDepartment
+	rows := make(map[string]*domain.AttendanceReportRow)
+	resp = &domain.AttendanceReportResp{List: make([]*domain.AttendanceReportRow, 0)}
+	for _, a := range approvals {
+		month := l.startMonth(a)
+		dept := depts[a.UserID]
+		key := fmt.Sprintf("%s/%d", month, a.UserID)
+		if byDept {
+			key = fmt.Sprintf("%s/d%d", month, dept.ID)
+		}
+		row, ok := rows[key]
+		if !ok {
+			row = &domain.AttendanceReportRow{Month: month, LeaveDays: make(map[int]float64)}
+			if dept.ID > 0 {
+				row.DepartmentId, row.DepartmentName = util.UintToString(dept.ID), dept.Name
+			}
+			if !byDept {
+				row.UserId, row.UserName = util.UintToString(a.UserID), a.User.Name
+			}
+			rows[key] = row
+			resp.List = append(resp.List, row)
+		}
+
+		switch {
+		case a.Type == model.LeaveApproval && a.Leave != nil:
+			days := leaveDays(l.svcCtx.Config, a.Leave)
+			row.LeaveDays[int(a.Leave.Type)] += days
+			row.TotalLeaveDays += days
+		case a.Type == model.GoOutApproval && a.GoOut != nil:
+			row.GoOutHours += float64(a.GoOut.Duration)
+		case a.Type == model.MakeCardApproval:
+			row.MakeCardCount++
+		case a.Type == model.OvertimeApproval && a.Overtime != nil:
+			row.OvertimeHours += float64(a.Overtime.Duration)
+		case a.Type == model.TripApproval && a.BusinessTrip != nil:
+			row.TripDays += float64(a.BusinessTrip.Duration)
+		}
+	}
+
+	for _, row := range resp.List {
+		for t, v := range row.LeaveDays {
+			row.LeaveDays[t] = round2(v)
+		}
+		row.TotalLeaveDays = round2(row.TotalLeaveDays)
+		row.GoOutHours = round2(row.GoOutHours)
+		row.OvertimeHours = round2(row.OvertimeHours)
+	}
+	sort.SliceStable(resp.List, func(i, j int) bool {
+		a, b := resp.List[i], resp.List[j]
+		if a.Month != b.Month {
+			return a.Month < b.Month
+		}
+		if a.DepartmentName != b.DepartmentName {
+			return a.DepartmentName < b.DepartmentName
+		}
+		return a.UserName < b.UserName
+	})
+	return resp, nil
+}
+
+func (l *report) Turnaround(ctx context.Context, req *domain.ReportReq) (resp *domain.TurnaroundReportResp, err error) {
+	if err := checkAdmin(ctx, l.svcCtx); err != nil {
+		return nil, err
+	}
+	start, end, err := l.monthRange(req)
+	if err != nil {
+		return nil, xerr.New(err)
+	}
+	users, err := l.reportUsers(ctx, req.DepartmentId)
+	if err != nil {
+		return nil, xerr.New(err)
+	}
+
+	// 统计期间内处理过的审批节点，处理时间为节点最后更新时间
+	db := l.svcCtx.DB.WithContext(ctx).Preload("User").
+		Where("status IN ? AND updated_at >= ? AND updated_at < ?", []model.ApprovalStatus{model.Pass, model.Refuse}, start, end)
+	if users != nil {
+		db = db.Where("user_id IN ?", users)
+	}
+	var decided []*model.Approver
+	if err := db.Find(&decided).Error; err != nil {
+		log.Error().Err(err).Msg("failed to query approvers for report")
+		return nil, xerr.New(err)
+	}
+
+	// 同一审批单的全部节点，用于推算每个步骤开始等待的时间
+	approvalIDs := make([]uint, 0, len(decided))
+	uids := make([]uint, 0, len(decided))
+	for _, a := range decided {
+		approvalIDs = append(approvalIDs, a.ApprovalID)
+		uids = append(uids, a.UserID)
+	}
+	var all []model.Approver
+	if len(approvalIDs) > 0 {
+		if err := l.svcCtx.DB.WithContext(ctx).Where("approval_id IN ?", uniqueIDs(approvalIDs)).Find(&all).Error; err != nil {
+			return nil, xerr.New(err)
+		}
+	}
+	steps := make(map[uint][]model.Approver)
+	for _, a := range all {
+		steps[a.ApprovalID] = append(steps[a.ApprovalID], a)
+	}
+	depts, err := l.userDepartments(ctx, uniqueIDs(uids))
+	if err != nil {
+		return nil, xerr.New(err)
+	}
+
+	// 与超时提醒一致，配置只计工作时间时按工作日历计算
+	var cal *workcal.Calendar
+	if l.svcCtx.Config.ApprovalSLA.WorkHoursOnly {
+		if cal, err = loadWorkCalendar(ctx, l.svcCtx); err != nil {
+			return nil, xerr.New(err)
+		}
+	}
+
+	rows := make(map[uint]*domain.TurnaroundReportRow)
+	total := make(map[uint]float64)
+	resp = &domain.TurnaroundReportResp{List: make([]*domain.TurnaroundReportRow, 0)}
+	for _, a := range decided {
+		hours := elapsedHours(cal, stepStartedAt(steps[a.ApprovalID], a.Step), a.UpdatedAt)
+		row, ok := rows[a.UserID]
+		if !ok {
+			row = &domain.TurnaroundReportRow{UserId: util.UintToString(a.UserID), UserName: a.User.Name}
+			if dept := depts[a.UserID]; dept.ID > 0 {
+				row.DepartmentName = dept.Name
+			}
+			rows[a.UserID] = row
+			resp.List = append(resp.List, row)
+		}
+		row.Count++
+		if a.Status == model.Refuse {
+			row.Refused++
+		}
+		total[a.UserID] += hours
+		row.MaxHours = math.Max(row.MaxHours, round2(hours))
+	}
+	for uid, row := range rows {
+		row.AvgHours = round2(total[uid] / float64(row.Count))
+	}
+	sort.SliceStable(resp.List, func(i, j int) bool {
+		return resp.List[i].AvgHours > resp.List[j].AvgHours
+	})
+	return resp, nil
+}
+
+func (l *report) Export(ctx context.Context, req *domain.ReportExportReq) (filename string, data []byte, err error) {
+	var table *reportTable
+	switch req.Report {
+	case reportAttendance:
+		resp, err := l.Attendance(ctx, &req.ReportReq)
+		if err != nil {
+			return "", nil, err
+		}
+		table = attendanceTable(resp, req.GroupBy == reportGroupByDepartment)
+	case reportTurnaround:
+		resp, err := l.Turnaround(ctx, &req.ReportReq)
+		if err != nil {
+			return "", nil, err
+		}
+		table = turnaroundTable(resp)
+	default:
+		return "", nil, xerr.New(fmt.Errorf("unknown report %q", req.Report))
+	}
+
+	start, end, _ := l.monthRange(&req.ReportReq)
+	name := fmt.Sprintf("%s-%s", table.Sheet, start.Format("200601"))
+	if last := end.AddDate(0, -1, 0); !last.Equal(start) {
+		name += "-" + last.Format("200601")
+	}
+
+	switch req.Format {
+	case "", "csv":
+		data, err = table.CSV()
+		name += ".csv"
+	case "xlsx":
+		data, err = table.XLSX()
+		name += ".xlsx"
+	default:
+		return "", nil, xerr.New(fmt.Errorf("unsupported export format %q", req.Format))
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("failed to encode report")
+		return "", nil, xerr.New(err)
+	}
+	return name, data, nil
+}
+
+// monthRange 解析统计月份，返回 [开始月份第一天, 结束月份下个月第一天)，默认为当月
+func (l *report) monthRange(req *domain.ReportReq) (start, end time.Time, err error) {
+	loc := l.svcCtx.Location()
+	now := time.Now().In(loc)
+	start = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+	if req.StartMonth != "" {
+		if start, err = time.ParseInLocation("2006-01", req.StartMonth, loc); err != nil {
+			return start, end, fmt.Errorf("invalid start month %q, expected format 2006-01", req.StartMonth)
+		}
+	}
+	last := start
+	if req.EndMonth != "" {
+		if last, err = time.ParseInLocation("2006-01", req.EndMonth, loc); err != nil {
+			return start, end, fmt.Errorf("invalid end month %q, expected format 2006-01", req.EndMonth)
+		}
+	}
+	if last.Before(start) {
+		return start, end, errors.New("end month must not be before start month")
+	}
+	end = last.AddDate(0, 1, 0)
+	if end.After(start.AddDate(0, maxReportMonths, 0)) {
+		return start, end, fmt.Errorf("at most %d months can be reported at once", maxReportMonths)
+	}
+	return start, end, nil
+}
+
+// startMonth 审批单业务开始时间所在的月份，格式为 2006-01，没有业务时间的审批单按完成的月份统计
+// 与查询条件一致：start_at 有值时按业务时间筛选，为 0 时按完成时间筛选
+func (l *report) startMonth(a *model.Approval) string {
+	if a.StartAt > 0 {
+		return time.Unix(a.StartAt, 0).In(l.svcCtx.Location()).Format("2006-01")
+	}
+	return l.finishMonth(a)
+}
+
+// finishMonth 审批单完成的月份，格式为 2006-01
+func (l *report) finishMonth(a *model.Approval) string {
+	if a.FinishMonth > 0 {
+		return fmt.Sprintf("%d-%02d", a.FinishMonth/100, a.FinishMonth%100)
+	}
+	return a.FinishAt.In(l.svcCtx.Location()).Format("2006-01")
+}
+
+// reportUsers 部门及其下级部门的全部成员，不限部门时返回 nil
+func (l *report) reportUsers(ctx context.Context, departmentId string) ([]uint, error) {
+	if departmentId == "" {
+		return nil, nil
+	}
+	deptID, err := util.StringToUint(departmentId)
+	if err != nil {
+		return nil, errors.New("invalid department id")
+	}
+	var dept model.Department
+	if err := l.svcCtx.DB.WithContext(ctx).First(&dept, deptID).Error; err != nil {
+		return nil, err
+	}
+
+	path := util.UintToString(dept.ID)
+	if dept.ParentPath != "" {
+		path = dept.ParentPath + "-" + path
+	}
+	sub := l.svcCtx.DB.Model(&model.Department{}).Select("id").
+		Where("id = ? OR parent_path = ? OR parent_path LIKE ?", dept.ID, path, path+"-%")
+	uids := make([]uint, 0)
+	if err := l.svcCtx.DB.WithContext(ctx).Model(&model.DepartmentUser{}).
+		Where("department_id IN (?)", sub).Distinct().Pluck("user_id", &uids).Error; err != nil {
+		return nil, err
+	}
+	return uids, nil
+}
+
+// userDepartments 查询用户所属的最下级部门，未关联部门的用户不在结果中
+func (l *report) userDepartments(ctx context.Context, uids []uint) (map[uint]model.Department, error) {
+	res := make(map[uint]model.Department, len(uids))
+	if len(uids) == 0 {
+		return res, nil
+	}
+	var deptUsers []model.DepartmentUser
+	if err := l.svcCtx.DB.WithContext(ctx).Where("user_id IN ?", uids).Find(&deptUsers).Error; err != nil {
+		return nil, err
+	}
+	deptIDs := make([]uint, 0, len(deptUsers))
+	for _, du := range deptUsers {
+		deptIDs = append(deptIDs, du.DepartmentID)
+	}
+	var depts []model.Department
+	if err := l.svcCtx.DB.WithContext(ctx).Where("id IN ?", uniqueIDs(deptIDs)).Find(&depts).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]model.Department, len(depts))
+	for _, d := range depts {
+		byID[d.ID] = d
+	}
+	for _, du := range deptUsers {
+		d, ok := byID[du.DepartmentID]
+		if !ok {
+			continue
+		}
+		if cur, ok := res[du.UserID]; !ok || len(d.ParentPath) > len(cur.ParentPath) {
+			res[du.UserID] = d
+		}
+	}
+	return res, nil
+}
+
+// round2 保留两位小数
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// backfillBatch 补充业务开始时间时每批处理的审批单数量
+const backfillBatch = 200
+
+func (l *report) BackfillStartAt(ctx context.Context) error {
+	types := []model.ApprovalType{
+		model.LeaveApproval, model.GoOutApproval, model.MakeCardApproval, model.OvertimeApproval, model.TripApproval,
+	}
+	var approvals []*model.Approval
+	return l.svcCtx.DB.WithContext(ctx).Where("start_at = 0 AND type IN ?", types).
+		FindInBatches(&approvals, backfillBatch, func(tx *gorm.DB, _ int) error {
+			for _, a := range approvals {
+				at := approvalStartAt(a)
+				if at == 0 {
+					continue
+				}
+				if err := l.svcCtx.DB.WithContext(ctx).Model(&model.Approval{}).Where("id = ? AND start_at = 0", a.ID).
+					UpdateColumn("start_at", at).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+}
@@ -0,0 +1,115 @@
+package logic
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+
+	"BackEnd/internal/domain"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// reportTable 导出报表的表格数据
+type reportTable struct {
+	Sheet  string
+	Header []string
+	Rows   [][]any
+}
+
+// CSV 编码为带 BOM 的 UTF-8 CSV，Excel 打开时中文不会乱码
+func (t *reportTable) CSV() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("\ufeff")
+	w := csv.NewWriter(&buf)
+	if err := w.Write(t.Header); err != nil {
+		return nil, err
+	}
+	for _, row := range t.Rows {
+		record := make([]string, len(row))
+		for i, v := range row {
+			record[i] = fmt.Sprint(v)
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// XLSX 编码为 Excel 工作簿，数值列保持数字类型便于汇总
+func (t *reportTable) XLSX() ([]byte, error) {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	if err := f.SetSheetName("Sheet1", t.Sheet); err != nil {
+		return nil, err
+	}
+	sw, err := f.NewStreamWriter(t.Sheet)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]any, len(t.Header))
+	for i, h := range t.Header {
+		header[i] = h
+	}
+	if err := sw.SetRow("A1", header); err != nil {
+		return nil, err
+	}
+	for i, row := range t.Rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return nil, err
+		}
+		if err := sw.SetRow(cell, row); err != nil {
+			return nil, err
+		}
+	}
+	if err := sw.Flush(); err != nil {
+		return nil, err
+	}
+
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// attendanceTable 考勤异常报表，每种请假类型一列
+func attendanceTable(resp *domain.AttendanceReportResp, byDept bool) *reportTable {
+	t := &reportTable{Sheet: "考勤异常统计", Header: []string{"月份", "部门"}}
+	if !byDept {
+		t.Header = append(t.Header, "姓名")
+	}
+	for _, lt := range leaveTypes {
+		t.Header = append(t.Header, lt.ToString()+"（天）")
+	}
+	t.Header = append(t.Header, "请假合计（天）", "外出（小时）", "补卡（次）", "加班（小时）", "出差（天）")
+
+	for _, r := range resp.List {
+		row := []any{r.Month, r.DepartmentName}
+		if !byDept {
+			row = append(row, r.UserName)
+		}
+		for _, lt := range leaveTypes {
+			row = append(row, r.LeaveDays[int(lt)])
+		}
+		row = append(row, r.TotalLeaveDays, r.GoOutHours, r.MakeCardCount, r.OvertimeHours, r.TripDays)
+		t.Rows = append(t.Rows, row)
+	}
+	return t
+}
+
+// turnaroundTable 审批时效报表
+func turnaroundTable(resp *domain.TurnaroundReportResp) *reportTable {
+	t := &reportTable{
+		Sheet:  "审批时效统计",
+		Header: []string{"审批人", "部门", "处理数量", "驳回数量", "平均处理时长（小时）", "最长处理时长（小时）"},
+	}
+	for _, r := range resp.List {
+		t.Rows = append(t.Rows, []any{r.UserName, r.DepartmentName, r.Count, r.Refused, r.AvgHours, r.MaxHours})
+	}
+	return t
+}
@@ -4,12 +4,17 @@ import (
 	"BackEnd/internal/domain"
 	"BackEnd/internal/model"
 	"BackEnd/internal/svc"
+	"BackEnd/pkg/recur"
+	"BackEnd/pkg/util"
 	"BackEnd/pkg/xerr"
 	"context"
+	"errors"
 	"strconv"
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TodoLogic interface {
@@ -18,8 +23,22 @@ type TodoLogic interface {
 	Delete(ctx context.Context, userID uint, id string) error
 	Get(ctx context.Context, userID uint, id string) (*domain.TodoInfoResp, error)
 	List(ctx context.Context, userID uint, req *domain.TodoListReq) (*domain.TodoListResp, error)
+	// Labels 查询我的待办中使用过的标签
+	Labels(ctx context.Context, userID uint) (*domain.TodoLabelListResp, error)
 	Finish(ctx context.Context, userID uint, req *domain.FinishedTodoReq) error
+	// UpdateStatus 执行人更新自己的完成状态
+	UpdateStatus(ctx context.Context, userID uint, req *domain.TodoStatusReq) error
+	// Reassign 创建人将执行人的待办改派给其他人
+	Reassign(ctx context.Context, userID uint, req *domain.TodoReassignReq) error
+	// Handoff 执行人将自己的待办转交给其他人
+	Handoff(ctx context.Context, userID uint, req *domain.TodoHandoffReq) error
 	CreateRecord(ctx context.Context, userID uint, req *domain.TodoRecord) error
+	// Recur 为重复待办生成下一次实例，由后台任务定时调用
+	Recur(ctx context.Context) error
+	// Remind 截止提醒和逾期标记，由后台任务定时调用
+	Remind(ctx context.Context) error
+	// Digest 每日待办汇总，由后台任务定时调用
+	Digest(ctx context.Context) error
 }
 
 type todoLogic struct {
@@ -33,12 +52,44 @@ func NewTodo(svcCtx *svc.ServiceContext) TodoLogic {
 }
 
 func (l *todoLogic) Create(ctx context.Context, userID uint, req *domain.Todo) (*domain.IdResp, error) {
+	recurrence, err := parseRecurrence(req.Recurrence, req.DeadlineAt)
+	if err != nil {
+		return nil, xerr.New(err)
+	}
+	if err := checkPriority(req.Priority); err != nil {
+		return nil, xerr.New(err)
+	}
+	labels, err := normalizeLabels(req.Labels)
+	if err != nil {
+		return nil, xerr.New(err)
+	}
+	var parentID uint
+	if req.ParentId != "" {
+		id, err := strconv.ParseUint(req.ParentId, 10, 64)
+		if err != nil {
+			return nil, xerr.New(errors.New("invalid parent todo id"))
+		}
+		if recurrence != "" {
+			return nil, xerr.New(errors.New("subtasks cannot recur"))
+		}
+		if err := checkParent(l.svcCtx.DB.WithContext(ctx), uint(id), userID); err != nil {
+			return nil, xerr.New(err)
+		}
+		parentID = uint(id)
+	}
 	todo := &model.Todo{
-		CreatorID:  userID,
-		Title:      req.Title,
-		Desc:       req.Desc,
-		DeadlineAt: time.Unix(req.DeadlineAt, 0),
-		Status:     0,
+		CreatorID:    userID,
+		Title:        req.Title,
+		Desc:         req.Desc,
+		DeadlineAt:   time.Unix(req.DeadlineAt, 0),
+		Status:       0,
+		Recurrence:   recurrence,
+		ParentID:     parentID,
+		AutoComplete: req.AutoComplete,
+		Priority:     req.Priority,
+	}
+	if recurrence != "" {
+		todo.Occurrence = 1
 	}
 
 	tx := l.svcCtx.DB.WithContext(ctx).Begin()
@@ -47,6 +98,18 @@ func (l *todoLogic) Create(ctx context.Context, userID uint, req *domain.Todo) (
 		log.Error().Err(err).Msg("failed to create todo")
 		return nil, xerr.New(err)
 	}
+	if err := setTodoLabels(tx, todo.ID, labels); err != nil {
+		tx.Rollback()
+		log.Error().Err(err).Msg("failed to save todo labels")
+		return nil, xerr.New(err)
+	}
+	if recurrence != "" {
+		// 重复待办以首个实例的ID作为系列ID
+		if err := tx.Model(todo).Update("series_id", todo.ID).Error; err != nil {
+			tx.Rollback()
+			return nil, xerr.New(err)
+		}
+	}
 
 	// Add executors
 	if len(req.ExecuteIds) > 0 {
@@ -91,74 +154,127 @@ func (l *todoLogic) Create(ctx context.Context, userID uint, req *domain.Todo) (
 }
 
 func (l *todoLogic) Update(ctx context.Context, userID uint, req *domain.Todo) error {
-	todo := &model.Todo{}
-	if err := l.svcCtx.DB.WithContext(ctx).First(todo, req.ID).Error; err != nil {
+	recurrence, err := parseRecurrence(req.Recurrence, req.DeadlineAt)
+	if err != nil {
 		return xerr.New(err)
 	}
-
-	// Only creator can update
-	// if todo.CreatorID != userID { return errors.New("permission denied") }
-
-	todo.Title = req.Title
-	todo.Desc = req.Desc
-	todo.DeadlineAt = time.Unix(req.DeadlineAt, 0)
-
-	tx := l.svcCtx.DB.WithContext(ctx).Begin()
-	if err := tx.Save(todo).Error; err != nil {
-		tx.Rollback()
-		log.Error().Err(err).Msg("failed to update todo")
+	if err := checkPriority(req.Priority); err != nil {
+		return xerr.New(err)
+	}
+	labels, err := normalizeLabels(req.Labels)
+	if err != nil {
 		return xerr.New(err)
 	}
+	executorIDs := make([]uint, 0, len(req.ExecuteIds))
+	for _, s := range req.ExecuteIds {
+		id, err := util.StringToUint(s)
+		if err != nil {
+			return xerr.New(errors.New("invalid executor id"))
+		}
+		executorIDs = append(executorIDs, id)
+	}
 
-	// Update executors
-	if len(req.ExecuteIds) > 0 {
-		// 1. Get existing executors to preserve status
-		var existing []model.UserTodo
-		if err := tx.Where("todo_id = ?", todo.ID).Find(&existing).Error; err != nil {
-			tx.Rollback()
-			return xerr.New(err)
+	// 加锁读取待办，只更新编辑的字段，避免覆盖执行人同时完成待办时写入的状态
+	err = l.svcCtx.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		todo := &model.Todo{}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(todo, req.ID).Error; err != nil {
+			return err
 		}
-		statusMap := make(map[uint]int)
-		for _, e := range existing {
-			statusMap[e.UserID] = e.TodoStatus
+		if todo.CreatorID != userID {
+			return errTodoCreatorOnly
+		}
+		if err := tx.Where("todo_id = ?", todo.ID).Find(&todo.Labels).Error; err != nil {
+			return err
+		}
+		before := *todo
+
+		// 截止时间变更后重新提醒，延期到未来的逾期待办恢复为待处理
+		columns := []string{"title", "desc", "deadline_at", "auto_complete", "priority", "recurrence", "series_id", "occurrence"}
+		deadline := time.Unix(req.DeadlineAt, 0)
+		reopen := false
+		if !deadline.Equal(todo.DeadlineAt) {
+			todo.RemindOffset = 0
+			columns = append(columns, "remind_offset")
+			if todo.TodoStatus == model.TodoOverdue && deadline.After(time.Now()) {
+				todo.TodoStatus = model.TodoPending
+				columns = append(columns, "todo_status")
+				reopen = true
+			}
 		}
 
-		// 2. Delete all
-		if err := tx.Where("todo_id = ?", todo.ID).Delete(&model.UserTodo{}).Error; err != nil {
-			tx.Rollback()
-			return xerr.New(err)
+		todo.Title = req.Title
+		todo.Desc = req.Desc
+		todo.DeadlineAt = deadline
+		todo.AutoComplete = req.AutoComplete
+		todo.Priority = req.Priority
+		if recurrence != "" && todo.ParentID > 0 {
+			return errors.New("subtasks cannot recur")
+		}
+		if recurrence != "" && todo.SeriesID == 0 {
+			todo.SeriesID, todo.Occurrence = todo.ID, 1
 		}
+		todo.Recurrence = recurrence
 
-		// 3. Re-add with preserved status
-		var userTodos []model.UserTodo
-		for _, eidStr := range req.ExecuteIds {
-			eid, _ := strconv.ParseUint(eidStr, 10, 64)
-			uid := uint(eid)
-			status := 0 // Default pending
-			if s, ok := statusMap[uid]; ok {
-				status = s
+		if err := tx.Model(todo).Select(columns).Omit(clause.Associations).Updates(todo).Error; err != nil {
+			return err
+		}
+		for _, c := range l.todoChanges(&before, todo) {
+			if err := addTodoHistory(tx, todo.ID, userID, model.TodoActionUpdate, c.field, c.old, c.new, ""); err != nil {
+				return err
 			}
-			userTodos = append(userTodos, model.UserTodo{
-				TodoID:     todo.ID,
-				UserID:     uid,
-				TodoStatus: status,
-			})
 		}
-		if err := tx.Create(&userTodos).Error; err != nil {
-			tx.Rollback()
-			log.Error().Err(err).Msg("failed to update todo executors")
-			return xerr.New(err)
+		// 没有传入标签时不修改
+		if req.Labels != nil {
+			oldLabels := make([]string, 0, len(before.Labels))
+			for _, label := range before.Labels {
+				oldLabels = append(oldLabels, label.Name)
+			}
+			if err := setTodoLabels(tx, todo.ID, labels); err != nil {
+				return err
+			}
+			if old, cur := joinLabels(oldLabels), joinLabels(labels); old != cur {
+				if err := addTodoHistory(tx, todo.ID, userID, model.TodoActionUpdate, "labels", old, cur, ""); err != nil {
+					return err
+				}
+			}
 		}
-	}
-
-	if err := tx.Commit().Error; err != nil {
+		if reopen {
+			if err := tx.Model(&model.UserTodo{}).Where("todo_id = ? AND todo_status = ?", todo.ID, model.TodoOverdue).
+				Update("todo_status", model.TodoPending).Error; err != nil {
+				return err
+			}
+		}
+		// 没有传入执行人时不修改
+		if len(executorIDs) > 0 {
+			return l.setExecutors(tx, todo, executorIDs, userID)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error().Err(err).Str("id", req.ID).Msg("failed to update todo")
 		return xerr.New(err)
 	}
 	return nil
 }
 
 func (l *todoLogic) Delete(ctx context.Context, userID uint, id string) error {
-	if err := l.svcCtx.DB.WithContext(ctx).Delete(&model.Todo{}, id).Error; err != nil {
+	todoID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return xerr.New(errors.New("invalid todo id"))
+	}
+	var todo model.Todo
+	if err := l.svcCtx.DB.WithContext(ctx).Select("id", "creator_id").First(&todo, todoID).Error; err != nil {
+		return xerr.New(err)
+	}
+	if todo.CreatorID != userID {
+		return xerr.New(errTodoCreatorOnly)
+	}
+	// 删除待办时一并删除其子待办
+	ids, err := subtreeIDs(l.svcCtx.DB.WithContext(ctx), uint(todoID))
+	if err != nil {
+		return xerr.New(err)
+	}
+	if err := l.svcCtx.DB.WithContext(ctx).Delete(&model.Todo{}, append(ids, uint(todoID))).Error; err != nil {
 		log.Error().Err(err).Str("id", id).Msg("failed to delete todo")
 		return xerr.New(err)
 	}
@@ -167,20 +283,54 @@ func (l *todoLogic) Delete(ctx context.Context, userID uint, id string) error {
 
 func (l *todoLogic) Get(ctx context.Context, userID uint, id string) (*domain.TodoInfoResp, error) {
 	todo := &model.Todo{}
-	if err := l.svcCtx.DB.WithContext(ctx).Preload("Creator").Preload("Executors").Preload("Records").Preload("Records.User").First(todo, id).Error; err != nil {
+	if err := l.svcCtx.DB.WithContext(ctx).Preload("Creator").Preload("Executors").Preload("Records").Preload("Records.User").Preload("Labels").
+		Preload("History", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).Preload("History.User").First(todo, id).Error; err != nil {
 		log.Error().Err(err).Str("id", id).Msg("failed to find todo info")
 		return nil, xerr.New(err)
 	}
+	// 只有创建人和执行人可以查看待办及其变更记录
+	ok, err := isTodoMember(l.svcCtx.DB.WithContext(ctx), todo, userID)
+	if err != nil {
+		return nil, xerr.New(err)
+	}
+	if !ok {
+		return nil, xerr.New(errTodoMemberOnly)
+	}
 
 	resp := &domain.TodoInfoResp{
-		ID:          strconv.Itoa(int(todo.ID)),
-		CreatorId:   strconv.Itoa(int(todo.CreatorID)),
-		CreatorName: todo.Creator.Name,
-		Title:       todo.Title,
-		Desc:        todo.Desc,
-		DeadlineAt:  todo.DeadlineAt.Unix(),
-		Status:      todo.Status,
-		TodoStatus:  todo.TodoStatus,
+		ID:           strconv.Itoa(int(todo.ID)),
+		CreatorId:    strconv.Itoa(int(todo.CreatorID)),
+		CreatorName:  todo.Creator.Name,
+		Title:        todo.Title,
+		Desc:         todo.Desc,
+		DeadlineAt:   todo.DeadlineAt.Unix(),
+		Status:       todo.Status,
+		TodoStatus:   todo.TodoStatus,
+		Recurrence:   todo.Recurrence,
+		Occurrence:   todo.Occurrence,
+		AutoComplete: todo.AutoComplete,
+		Priority:     todo.Priority,
+	}
+	for _, label := range todo.Labels {
+		resp.Labels = append(resp.Labels, label.Name)
+	}
+	if todo.SeriesID > 0 {
+		resp.SeriesId = strconv.Itoa(int(todo.SeriesID))
+	}
+	if todo.ParentID > 0 {
+		resp.ParentId = strconv.Itoa(int(todo.ParentID))
+	}
+	children, err := l.subtaskTree(ctx, todo.ID)
+	if err != nil {
+		log.Error().Err(err).Str("id", id).Msg("failed to find subtasks")
+		return nil, xerr.New(err)
+	}
+	resp.Children = children
+	if progress, err := todoProgress(l.svcCtx.DB.WithContext(ctx), []uint{todo.ID}); err == nil {
+		resp.Progress = progress[todo.ID]
+	}
+	if rule, err := recur.Parse(todo.Recurrence); err == nil {
+		resp.RepeatText = rule.Describe()
 	}
 
 	for _, rec := range todo.Records {
@@ -194,6 +344,22 @@ func (l *todoLogic) Get(ctx context.Context, userID uint, id string) (*domain.To
 		})
 	}
 
+	for _, h := range todo.History {
+		item := &domain.TodoHistory{
+			UserName: h.User.Name,
+			Action:   string(h.Action),
+			Field:    h.Field,
+			OldValue: h.OldValue,
+			NewValue: h.NewValue,
+			Remark:   h.Remark,
+			CreateAt: h.CreatedAt.Unix(),
+		}
+		if h.UserID > 0 {
+			item.UserId = strconv.Itoa(int(h.UserID))
+		}
+		resp.History = append(resp.History, item)
+	}
+
 	for _, exec := range todo.Executors {
 		resp.ExecuteIds = append(resp.ExecuteIds, &domain.UserTodo{
 			UserId:   strconv.Itoa(int(exec.ID)),
@@ -205,104 +371,96 @@ func (l *todoLogic) Get(ctx context.Context, userID uint, id string) (*domain.To
 }
 
 func (l *todoLogic) List(ctx context.Context, userID uint, req *domain.TodoListReq) (*domain.TodoListResp, error) {
-	var todos []model.Todo
-	// 1. Find IDs
-	var ids []uint
-	db := l.svcCtx.DB.WithContext(ctx).Model(&model.Todo{}).
-		Joins("LEFT JOIN user_todos ON user_todos.todo_id = todos.id").
-		Where("todos.creator_id = ? OR user_todos.user_id = ?", userID, userID)
-
-	if req.StartTime > 0 {
-		db = db.Where("todos.created_at >= ?", time.Unix(req.StartTime, 0))
+	db, err := l.listQuery(ctx, userID, req)
+	if err != nil {
+		return nil, xerr.New(err)
 	}
-	if req.EndTime > 0 {
-		db = db.Where("todos.created_at <= ?", time.Unix(req.EndTime, 0))
+	order, err := listOrder(req)
+	if err != nil {
+		return nil, xerr.New(err)
 	}
 
-	if err := db.Distinct("todos.id").Pluck("todos.id", &ids).Error; err != nil {
-		log.Error().Err(err).Msg("failed to find todo ids")
+	var count int64
+	if err := db.Count(&count).Error; err != nil {
+		log.Error().Err(err).Msg("failed to count todos")
 		return nil, xerr.New(err)
 	}
 
-	// 2. Count
-	count := int64(len(ids))
+	pagination := util.NormalizePagination(req.Page, req.Count)
+	var todos []model.Todo
+	if err := db.Preload("Creator").Order(order).
+		Offset(pagination.Offset).Limit(pagination.Count).
+		Find(&todos).Error; err != nil {
+		log.Error().Err(err).Msg("failed to find todos")
+		return nil, xerr.New(err)
+	}
 
-	// Set default pagination
-	page := req.Page
-	if page <= 0 {
-		page = 1
+	pageIds := make([]uint, 0, len(todos))
+	for _, t := range todos {
+		pageIds = append(pageIds, t.ID)
 	}
-	limit := req.Count
-	if limit <= 0 {
-		limit = 10
+	progress, err := todoProgress(l.svcCtx.DB.WithContext(ctx), pageIds)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to count subtasks")
+		return nil, xerr.New(err)
 	}
-
-	// 3. Find Todos with pagination
-	start := (page - 1) * limit
-	end := start + limit
-	if start < len(ids) {
-		if end > len(ids) {
-			end = len(ids)
-		}
-		pageIds := ids[start:end]
-		if err := l.svcCtx.DB.WithContext(ctx).Model(&model.Todo{}).
-			Preload("Creator").
-			Where("id IN ?", pageIds).
-			Find(&todos).Error; err != nil {
-			log.Error().Err(err).Msg("failed to find todos")
-			return nil, xerr.New(err)
-		}
+	labels, err := todoLabels(l.svcCtx.DB.WithContext(ctx), pageIds)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to find todo labels")
+		return nil, xerr.New(err)
 	}
 
 	list := make([]*domain.Todo, 0, len(todos))
 	for _, t := range todos {
-		list = append(list, &domain.Todo{
-			ID:          strconv.Itoa(int(t.ID)),
-			CreatorId:   strconv.Itoa(int(t.CreatorID)),
-			CreatorName: t.Creator.Name,
-			Title:       t.Title,
-			Desc:        t.Desc,
-			DeadlineAt:  t.DeadlineAt.Unix(),
-			Status:      t.Status,
-			TodoStatus:  t.TodoStatus,
-		})
+		item := &domain.Todo{
+			ID:           strconv.Itoa(int(t.ID)),
+			CreatorId:    strconv.Itoa(int(t.CreatorID)),
+			CreatorName:  t.Creator.Name,
+			Title:        t.Title,
+			Desc:         t.Desc,
+			DeadlineAt:   t.DeadlineAt.Unix(),
+			Status:       t.Status,
+			TodoStatus:   t.TodoStatus,
+			Recurrence:   t.Recurrence,
+			AutoComplete: t.AutoComplete,
+			Progress:     progress[t.ID],
+			Priority:     t.Priority,
+			Labels:       labels[t.ID],
+		}
+		if t.SeriesID > 0 {
+			item.SeriesId = strconv.Itoa(int(t.SeriesID))
+		}
+		if t.ParentID > 0 {
+			item.ParentId = strconv.Itoa(int(t.ParentID))
+		}
+		list = append(list, item)
 	}
 
 	return &domain.TodoListResp{Count: count, List: list}, nil
 }
 
+// Finish 执行人完成自己的待办
 func (l *todoLogic) Finish(ctx context.Context, userID uint, req *domain.FinishedTodoReq) error {
-	// 1. Update UserTodo status
-	if err := l.svcCtx.DB.WithContext(ctx).Model(&model.UserTodo{}).
-		Where("todo_id = ? AND user_id = ?", req.TodoId, userID).
-		Update("todo_status", 2).Error; err != nil {
-		log.Error().Err(err).Msg("failed to update todo status")
-		return err
-	}
+	return l.UpdateStatus(ctx, userID, &domain.TodoStatusReq{TodoId: req.TodoId, TodoStatus: model.TodoFinished})
+}
 
-	// 2. Check if all executors finished
-	var count int64
-	if err := l.svcCtx.DB.WithContext(ctx).Model(&model.UserTodo{}).
-		Where("todo_id = ? AND todo_status != 2", req.TodoId).
-		Count(&count).Error; err != nil {
+func (l *todoLogic) CreateRecord(ctx context.Context, userID uint, req *domain.TodoRecord) error {
+	todoID, err := strconv.ParseUint(req.TodoId, 10, 64)
+	if err != nil {
+		return xerr.New(errors.New("invalid todo id"))
+	}
+	var todo model.Todo
+	if err := l.svcCtx.DB.WithContext(ctx).Select("id", "creator_id").First(&todo, todoID).Error; err != nil {
 		return xerr.New(err)
 	}
-
-	// 3. If all finished (count == 0), update Todo status
-	if count == 0 {
-		if err := l.svcCtx.DB.WithContext(ctx).Model(&model.Todo{}).
-			Where("id = ?", req.TodoId).
-			Update("todo_status", 2).Error; err != nil {
-			log.Error().Err(err).Msg("failed to update todo overall status")
-			return xerr.New(err)
-		}
+	// 创建人和执行人可以添加记录
+	ok, err := isTodoMember(l.svcCtx.DB.WithContext(ctx), &todo, userID)
+	if err != nil {
+		return xerr.New(err)
+	}
+	if !ok {
+		return xerr.New(errTodoExecutorOnly)
 	}
-
-	return nil
-}
-
-func (l *todoLogic) CreateRecord(ctx context.Context, userID uint, req *domain.TodoRecord) error {
-	todoID, _ := strconv.ParseUint(req.TodoId, 10, 64)
 	record := &model.TodoRecord{
 		TodoID:  uint(todoID),
 		UserID:  userID,
@@ -0,0 +1,414 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"BackEnd/internal/domain"
+	"BackEnd/internal/model"
+	"BackEnd/pkg/util"
+	"BackEnd/pkg/xerr"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxHistoryValueLength 变更记录中值和说明的最大字符数，与 TodoHistory 的字段长度一致
+const maxHistoryValueLength = 512
+
+var (
+	errTodoCreatorOnly  = errors.New("only the creator can change this todo")
+	errTodoExecutorOnly = errors.New("only executors of this todo can do this")
+	errTodoMemberOnly   = errors.New("only the creator and executors can view this todo")
+)
+
+// addTodoHistory 在待办上追加一条变更记录，userID 为0表示系统操作
+func addTodoHistory(tx *gorm.DB, todoID, userID uint, action model.TodoAction, field, oldValue, newValue, remark string) error {
+	return tx.Create(&model.TodoHistory{
+		TodoID:   todoID,
+		UserID:   userID,
+		Action:   action,
+		Field:    field,
+		OldValue: clip(oldValue, maxHistoryValueLength),
+		NewValue: clip(newValue, maxHistoryValueLength),
+		Remark:   clip(remark, maxHistoryValueLength),
+	}).Error
+}
+
+// todoChange 待办的一个字段变更
+type todoChange struct {
+	field, old, new string
+}
+
+// todoChanges 比较修改前后的待办字段，状态和优先级记录数值，截止时间记录格式化的时间
+func (l *todoLogic) todoChanges(before, after *model.Todo) []todoChange {
+	var changes []todoChange
+	add := func(field, old, new string) {
+		if old != new {
+			changes = append(changes, todoChange{field: field, old: old, new: new})
+		}
+	}
+	add("title", before.Title, after.Title)
+	add("desc", before.Desc, after.Desc)
+	add("deadlineAt", l.historyDeadline(before.DeadlineAt), l.historyDeadline(after.DeadlineAt))
+	add("priority", strconv.Itoa(before.Priority), strconv.Itoa(after.Priority))
+	add("recurrence", before.Recurrence, after.Recurrence)
+	add("autoComplete", strconv.FormatBool(before.AutoComplete), strconv.FormatBool(after.AutoComplete))
+	add("todoStatus", strconv.Itoa(before.TodoStatus), strconv.Itoa(after.TodoStatus))
+	return changes
+}
+
+func (l *todoLogic) historyDeadline(t time.Time) string {
+	if !t.After(noDeadline) {
+		return ""
+	}
+	return l.formatDeadline(t)
+}
+
+// joinLabels 拼接标签用于比较和记录
+func joinLabels(labels []string) string {
+	sorted := slices.Clone(labels)
+	slices.Sort(sorted)
+	return strings.Join(sorted, "、")
+}
+
+// UpdateStatus 执行人更新自己的完成状态，全部执行人完成后待办完成
+func (l *todoLogic) UpdateStatus(ctx context.Context, userID uint, req *domain.TodoStatusReq) error {
+	todoID, err := util.StringToUint(req.TodoId)
+	if err != nil {
+		return xerr.New(errors.New("invalid todo id"))
+	}
+	switch req.TodoStatus {
+	case model.TodoPending, model.TodoInProgress, model.TodoFinished:
+	default:
+		return xerr.New(fmt.Errorf("invalid todo status %d", req.TodoStatus))
+	}
+
+	err = l.svcCtx.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var todo model.Todo
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&todo, todoID).Error; err != nil {
+			return err
+		}
+		var executor model.UserTodo
+		if err := tx.Where("todo_id = ? AND user_id = ?", todoID, userID).First(&executor).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errTodoExecutorOnly
+			}
+			return err
+		}
+		if executor.TodoStatus == req.TodoStatus {
+			return nil
+		}
+		if todo.TodoStatus == model.TodoFinished {
+			return errors.New("todo is already finished")
+		}
+
+		if err := tx.Model(&model.UserTodo{}).Where("todo_id = ? AND user_id = ?", todoID, userID).
+			Update("todo_status", req.TodoStatus).Error; err != nil {
+			return err
+		}
+		if err := addTodoHistory(tx, todoID, userID, model.TodoActionStatus, "executorStatus",
+			strconv.Itoa(executor.TodoStatus), strconv.Itoa(req.TodoStatus), ""); err != nil {
+			return err
+		}
+		// 有执行人开始处理时待办进入进行中
+		if req.TodoStatus == model.TodoInProgress && todo.TodoStatus == model.TodoPending {
+			if err := tx.Model(&todo).Update("todo_status", model.TodoInProgress).Error; err != nil {
+				return err
+			}
+			return addTodoHistory(tx, todoID, userID, model.TodoActionStatus, "todoStatus",
+				strconv.Itoa(model.TodoPending), strconv.Itoa(model.TodoInProgress), "")
+		}
+		return l.finishIfDone(tx, &todo, userID)
+	})
+	if err != nil {
+		log.Error().Err(err).Str("todoID", req.TodoId).Msg("failed to update todo status")
+		return xerr.New(err)
+	}
+	return nil
+}
+
+// finishIfDone 执行人全部完成后完成待办，重复待办生成下一次，子待办检查父待办是否自动完成
+func (l *todoLogic) finishIfDone(tx *gorm.DB, todo *model.Todo, userID uint) error {
+	if todo.TodoStatus == model.TodoFinished {
+		return nil
+	}
+	var unfinished int64
+	if err := tx.Model(&model.UserTodo{}).Where("todo_id = ? AND todo_status <> ?", todo.ID, model.TodoFinished).
+		Count(&unfinished).Error; err != nil {
+		return err
+	}
+	if unfinished > 0 {
+		return nil
+	}
+	if err := tx.Model(&model.Todo{}).Where("id = ?", todo.ID).Update("todo_status", model.TodoFinished).Error; err != nil {
+		return err
+	}
+	if err := addTodoHistory(tx, todo.ID, userID, model.TodoActionStatus, "todoStatus",
+		strconv.Itoa(todo.TodoStatus), strconv.Itoa(model.TodoFinished), ""); err != nil {
+		return err
+	}
+	if err := l.createNextTodo(tx, todo.ID, time.Now()); err != nil {
+		return err
+	}
+	return completeParents(tx, todo.ParentID)
+}
+
+// Reassign 创建人将某个执行人的待办改派给其他人，新执行人从头开始处理
+func (l *todoLogic) Reassign(ctx context.Context, userID uint, req *domain.TodoReassignReq) error {
+	todoID, err := util.StringToUint(req.TodoId)
+	if err != nil {
+		return xerr.New(errors.New("invalid todo id"))
+	}
+	fromID, err := util.StringToUint(req.FromId)
+	if err != nil {
+		return xerr.New(errors.New("invalid executor id"))
+	}
+	toID, err := util.StringToUint(req.ToId)
+	if err != nil {
+		return xerr.New(errors.New("invalid executor id"))
+	}
+
+	err = l.svcCtx.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var todo model.Todo
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&todo, todoID).Error; err != nil {
+			return err
+		}
+		if todo.CreatorID != userID {
+			return errTodoCreatorOnly
+		}
+		return l.transferTodo(tx, &todo, fromID, toID, userID, model.TodoActionReassign, "")
+	})
+	if err != nil {
+		log.Error().Err(err).Str("todoID", req.TodoId).Msg("failed to reassign todo")
+		return xerr.New(err)
+	}
+	return nil
+}
+
+// Handoff 执行人将自己未完成的待办转交给其他人
+func (l *todoLogic) Handoff(ctx context.Context, userID uint, req *domain.TodoHandoffReq) error {
+	todoID, err := util.StringToUint(req.TodoId)
+	if err != nil {
+		return xerr.New(errors.New("invalid todo id"))
+	}
+	toID, err := util.StringToUint(req.ToId)
+	if err != nil {
+		return xerr.New(errors.New("invalid executor id"))
+	}
+	reason := strings.TrimSpace(req.Reason)
+
+	err = l.svcCtx.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var todo model.Todo
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&todo, todoID).Error; err != nil {
+			return err
+		}
+		var executor model.UserTodo
+		if err := tx.Where("todo_id = ? AND user_id = ?", todoID, userID).First(&executor).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errTodoExecutorOnly
+			}
+			return err
+		}
+		if executor.TodoStatus == model.TodoFinished {
+			return errors.New("finished todo cannot be handed off")
+		}
+		return l.transferTodo(tx, &todo, userID, toID, userID, model.TodoActionHandoff, reason)
+	})
+	if err != nil {
+		log.Error().Err(err).Str("todoID", req.TodoId).Msg("failed to hand off todo")
+		return xerr.New(err)
+	}
+	return nil
+}
+
+// transferTodo 将执行人 fromID 替换为 toID，记录变更并通知新执行人，转交时同时通知创建人
+func (l *todoLogic) transferTodo(tx *gorm.DB, todo *model.Todo, fromID, toID, userID uint, action model.TodoAction, reason string) error {
+	if todo.TodoStatus == model.TodoFinished {
+		return errors.New("todo is already finished")
+	}
+	if fromID == toID {
+		return errors.New("new executor is the same as the current one")
+	}
+	var executors []uint
+	if err := tx.Model(&model.UserTodo{}).Where("todo_id = ?", todo.ID).Pluck("user_id", &executors).Error; err != nil {
+		return err
+	}
+	if !slices.Contains(executors, fromID) {
+		return errors.New("user is not an executor of this todo")
+	}
+	if slices.Contains(executors, toID) {
+		return errors.New("user is already an executor of this todo")
+	}
+	if err := checkUsers(tx, []uint{toID}); err != nil {
+		return err
+	}
+
+	if err := tx.Where("todo_id = ? AND user_id = ?", todo.ID, fromID).Delete(&model.UserTodo{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Create(&model.UserTodo{TodoID: todo.ID, UserID: toID, TodoStatus: executorStatus(todo)}).Error; err != nil {
+		return err
+	}
+
+	names, err := userNames(tx, []uint{fromID, toID, userID})
+	if err != nil {
+		return err
+	}
+	if err := addTodoHistory(tx, todo.ID, userID, action, "executors", names[fromID], names[toID], reason); err != nil {
+		return err
+	}
+
+	if err := notify(tx, model.Notification{
+		Type:    model.TodoAssignNotification,
+		Title:   clip("你有新的待办："+todo.Title, 128),
+		Content: clip(fmt.Sprintf("%s 将「%s」交给你处理", names[userID], todo.Title), 512),
+		BizID:   todo.ID,
+	}, toID); err != nil {
+		return err
+	}
+	if action == model.TodoActionHandoff && todo.CreatorID != userID {
+		content := fmt.Sprintf("%s 将「%s」转交给了 %s", names[fromID], todo.Title, names[toID])
+		if reason != "" {
+			content += "，原因：" + reason
+		}
+		return notify(tx, model.Notification{
+			Type:    model.TodoAssignNotification,
+			Title:   clip("待办已转交："+todo.Title, 128),
+			Content: clip(content, 512),
+			BizID:   todo.ID,
+		}, todo.CreatorID)
+	}
+	return nil
+}
+
+// setExecutors 按差异更新执行人：保留的执行人维持原状态，移除的删除，新增的从头开始处理
+func (l *todoLogic) setExecutors(tx *gorm.DB, todo *model.Todo, ids []uint, userID uint) error {
+	var existing []uint
+	if err := tx.Model(&model.UserTodo{}).Where("todo_id = ?", todo.ID).Order("created_at").
+		Pluck("user_id", &existing).Error; err != nil {
+		return err
+	}
+
+	var target, added, removed []uint
+	for _, id := range ids {
+		if slices.Contains(target, id) {
+			continue
+		}
+		target = append(target, id)
+		if !slices.Contains(existing, id) {
+			added = append(added, id)
+		}
+	}
+	for _, id := range existing {
+		if !slices.Contains(target, id) {
+			removed = append(removed, id)
+		}
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+
+	if len(added) > 0 {
+		if err := checkUsers(tx, added); err != nil {
+			return err
+		}
+		rows := make([]model.UserTodo, 0, len(added))
+		for _, id := range added {
+			rows = append(rows, model.UserTodo{TodoID: todo.ID, UserID: id, TodoStatus: executorStatus(todo)})
+		}
+		if err := tx.Create(&rows).Error; err != nil {
+			return err
+		}
+	}
+	if len(removed) > 0 {
+		if err := tx.Where("todo_id = ? AND user_id IN ?", todo.ID, removed).Delete(&model.UserTodo{}).Error; err != nil {
+			return err
+		}
+	}
+
+	names, err := userNames(tx, append(append(existing, added...), userID))
+	if err != nil {
+		return err
+	}
+	if err := addTodoHistory(tx, todo.ID, userID, model.TodoActionAssign, "executors",
+		joinNames(names, existing), joinNames(names, target), ""); err != nil {
+		return err
+	}
+	for _, id := range added {
+		if id == userID {
+			continue
+		}
+		if err := notify(tx, model.Notification{
+			Type:    model.TodoAssignNotification,
+			Title:   clip("你有新的待办："+todo.Title, 128),
+			Content: clip(fmt.Sprintf("%s 将「%s」交给你处理", names[userID], todo.Title), 512),
+			BizID:   todo.ID,
+		}, id); err != nil {
+			return err
+		}
+	}
+	// 移除未完成的执行人后，剩下的可能已经全部完成
+	return l.finishIfDone(tx, todo, userID)
+}
+
+// executorStatus 新加入的执行人的状态，已逾期的待办直接为逾期
+func executorStatus(todo *model.Todo) int {
+	if todo.TodoStatus == model.TodoOverdue {
+		return model.TodoOverdue
+	}
+	return model.TodoPending
+}
+
+// isTodoMember 用户是否为待办的创建人或执行人
+func isTodoMember(db *gorm.DB, todo *model.Todo, userID uint) (bool, error) {
+	if todo.CreatorID == userID {
+		return true, nil
+	}
+	var count int64
+	if err := db.Model(&model.UserTodo{}).Where("todo_id = ? AND user_id = ?", todo.ID, userID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// checkUsers 校验用户都存在
+func checkUsers(db *gorm.DB, ids []uint) error {
+	var count int64
+	if err := db.Model(&model.User{}).Where("id IN ?", ids).Count(&count).Error; err != nil {
+		return err
+	}
+	if int(count) != len(ids) {
+		return errors.New("user not found")
+	}
+	return nil
+}
+
+// userNames 查询用户名
+func userNames(db *gorm.DB, ids []uint) (map[uint]string, error) {
+	var users []model.User
+	if err := db.Select("id", "name").Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	res := make(map[uint]string, len(users))
+	for _, u := range users {
+		res[u.ID] = u.Name
+	}
+	return res, nil
+}
+
+// joinNames 按 ids 的顺序拼接用户名
+func joinNames(names map[uint]string, ids []uint) string {
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, names[id])
+	}
+	return strings.Join(parts, "、")
+}
@@ -0,0 +1,190 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"BackEnd/internal/domain"
+	"BackEnd/internal/model"
+	"BackEnd/pkg/xerr"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 待办标签的数量和长度限制
+const (
+	maxTodoLabels      = 10
+	maxTodoLabelLength = 32
+)
+
+// Labels 查询我创建的和分配给我的待办中使用过的标签及其待办数
+func (l *todoLogic) Labels(ctx context.Context, userID uint) (*domain.TodoLabelListResp, error) {
+	visible := l.svcCtx.DB.Model(&model.Todo{}).Select("id").
+		Where("creator_id = ? OR id IN (?)", userID, l.assignedTo(userID))
+
+	var rows []*domain.TodoLabelCount
+	if err := l.svcCtx.DB.WithContext(ctx).Model(&model.TodoLabel{}).
+		Select("name, COUNT(*) AS count").
+		Where("todo_id IN (?)", visible).
+		Group("name").Order("count DESC, name").Scan(&rows).Error; err != nil {
+		log.Error().Err(err).Msg("failed to list todo labels")
+		return nil, xerr.New(err)
+	}
+	return &domain.TodoLabelListResp{List: rows}, nil
+}
+
+// assignedTo 分配给用户的待办ID子查询
+func (l *todoLogic) assignedTo(userID uint) *gorm.DB {
+	return l.svcCtx.DB.Model(&model.UserTodo{}).Select("todo_id").Where("user_id = ?", userID)
+}
+
+// listQuery 按列表条件构造查询，只包括我创建的和分配给我的待办
+func (l *todoLogic) listQuery(ctx context.Context, userID uint, req *domain.TodoListReq) (*gorm.DB, error) {
+	db := l.svcCtx.DB.WithContext(ctx).Model(&model.Todo{})
+
+	switch req.Role {
+	case "":
+		db = db.Where("todos.creator_id = ? OR todos.id IN (?)", userID, l.assignedTo(userID))
+	case domain.TodoRoleCreated:
+		db = db.Where("todos.creator_id = ?", userID)
+	case domain.TodoRoleAssigned:
+		db = db.Where("todos.id IN (?)", l.assignedTo(userID))
+	default:
+		return nil, fmt.Errorf("unknown todo role %q", req.Role)
+	}
+
+	if req.StartTime > 0 {
+		db = db.Where("todos.created_at >= ?", time.Unix(req.StartTime, 0))
+	}
+	if req.EndTime > 0 {
+		db = db.Where("todos.created_at <= ?", time.Unix(req.EndTime, 0))
+	}
+	if req.DeadlineStart > 0 {
+		db = db.Where("todos.deadline_at >= ?", time.Unix(req.DeadlineStart, 0))
+	}
+	if req.DeadlineEnd > 0 {
+		db = db.Where("todos.deadline_at <= ?", time.Unix(req.DeadlineEnd, 0))
+	}
+	if req.Overdue {
+		// 已过截止时间仍未完成的，包括后台任务尚未标记的
+		db = db.Where("todos.deadline_at > ? AND todos.deadline_at <= ? AND todos.todo_status <> ?",
+			noDeadline, time.Now(), model.TodoFinished)
+	}
+	if len(req.TodoStatus) > 0 {
+		db = db.Where("todos.todo_status IN ?", req.TodoStatus)
+	}
+	if len(req.MyStatus) > 0 {
+		db = db.Where("todos.id IN (?)", l.assignedTo(userID).Where("todo_status IN ?", req.MyStatus))
+	}
+	if label := strings.TrimSpace(req.Label); label != "" {
+		db = db.Where("todos.id IN (?)", l.svcCtx.DB.Model(&model.TodoLabel{}).Select("todo_id").Where("name = ?", label))
+	}
+	if len(req.Priority) > 0 {
+		db = db.Where("todos.priority IN ?", req.Priority)
+	}
+	if keyword := strings.TrimSpace(req.Keyword); keyword != "" {
+		like := "%" + escapeLike(keyword) + "%"
+		db = db.Where("todos.title LIKE ? OR todos.`desc` LIKE ?", like, like)
+	}
+	return db, nil
+}
+
+// listOrder 列表排序，截止时间排序时没有截止时间的排在最后，相同时按创建顺序
+func listOrder(req *domain.TodoListReq) (clause.OrderBy, error) {
+	order := strings.ToLower(req.Order)
+	if order != "" && order != "asc" && order != "desc" {
+		return clause.OrderBy{}, fmt.Errorf("unknown order %q", req.Order)
+	}
+	switch req.Sort {
+	case "", "created":
+		if order == "" {
+			order = "asc"
+		}
+		return orderBy("todos.id " + order), nil
+	case "deadline":
+		if order == "" {
+			order = "asc"
+		}
+		return orderBy("todos.deadline_at <= ?, todos.deadline_at "+order+", todos.id", noDeadline), nil
+	case "priority":
+		if order == "" {
+			order = "desc"
+		}
+		return orderBy("todos.priority "+order+", todos.deadline_at <= ?, todos.deadline_at, todos.id", noDeadline), nil
+	}
+	return clause.OrderBy{}, fmt.Errorf("unknown sort %q", req.Sort)
+}
+
+func orderBy(sql string, vars ...any) clause.OrderBy {
+	return clause.OrderBy{Expression: clause.Expr{SQL: sql, Vars: vars, WithoutParentheses: true}}
+}
+
+// escapeLike 转义 LIKE 中的通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// checkPriority 校验优先级
+func checkPriority(p int) error {
+	if p < model.TodoPriorityNone || p > model.TodoPriorityUrgent {
+		return fmt.Errorf("invalid priority %d", p)
+	}
+	return nil
+}
+
+// normalizeLabels 去除空白和重复的标签，并检查数量和长度
+func normalizeLabels(labels []string) ([]string, error) {
+	res := make([]string, 0, len(labels))
+	seen := make(map[string]bool, len(labels))
+	for _, name := range labels {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		if utf8.RuneCountInString(name) > maxTodoLabelLength {
+			return nil, fmt.Errorf("label %q is longer than %d characters", name, maxTodoLabelLength)
+		}
+		seen[name] = true
+		res = append(res, name)
+	}
+	if len(res) > maxTodoLabels {
+		return nil, fmt.Errorf("a todo can have at most %d labels", maxTodoLabels)
+	}
+	return res, nil
+}
+
+// setTodoLabels 替换待办的标签
+func setTodoLabels(tx *gorm.DB, todoID uint, labels []string) error {
+	if err := tx.Where("todo_id = ?", todoID).Delete(&model.TodoLabel{}).Error; err != nil {
+		return err
+	}
+	if len(labels) == 0 {
+		return nil
+	}
+	rows := make([]model.TodoLabel, 0, len(labels))
+	for _, name := range labels {
+		rows = append(rows, model.TodoLabel{TodoID: todoID, Name: name})
+	}
+	return tx.Create(&rows).Error
+}
+
+// todoLabels 查询待办的标签
+func todoLabels(db *gorm.DB, ids []uint) (map[uint][]string, error) {
+	res := make(map[uint][]string)
+	if len(ids) == 0 {
+		return res, nil
+	}
+	var rows []model.TodoLabel
+	if err := db.Where("todo_id IN ?", ids).Order("name").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		res[r.TodoID] = append(res[r.TodoID], r.Name)
+	}
+	return res, nil
+}
@@ -0,0 +1,118 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"BackEnd/internal/model"
+	"BackEnd/pkg/recur"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Recur 为已到截止时间的重复待办生成下一次实例，已完成的待办在完成时生成
+func (l *todoLogic) Recur(ctx context.Context) error {
+	var ids []uint
+	if err := l.svcCtx.DB.WithContext(ctx).Model(&model.Todo{}).
+		Where("recurrence <> '' AND next_created = ? AND deadline_at <= ?", false, time.Now()).
+		Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, id := range ids {
+		err := l.svcCtx.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return l.createNextTodo(tx, id, now)
+		})
+		if err != nil {
+			log.Error().Err(err).Uint("todoID", id).Msg("failed to create next recurring todo")
+		}
+	}
+	return nil
+}
+
+// parseRecurrence 校验重复规则，返回规范化的 RRULE，空规则表示不重复
+func parseRecurrence(s string, deadline int64) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	if deadline <= 0 {
+		return "", errors.New("recurring todo needs a deadline")
+	}
+	rule, err := recur.Parse(s)
+	if err != nil {
+		return "", err
+	}
+	return rule.String(), nil
+}
+
+// createNextTodo 生成重复待办的下一次实例，保留执行人，每个实例只生成一次
+// 下一次的截止时间按规则从本次截止时间推算，跳过已经过去的时间
+func (l *todoLogic) createNextTodo(tx *gorm.DB, id uint, now time.Time) error {
+	var todo model.Todo
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&todo, id).Error; err != nil {
+		return err
+	}
+	if todo.Recurrence == "" || todo.NextCreated {
+		return nil
+	}
+	if err := tx.Model(&todo).Update("next_created", true).Error; err != nil {
+		return err
+	}
+
+	rule, err := recur.Parse(todo.Recurrence)
+	if err != nil {
+		return err
+	}
+	if rule.Count > 0 && todo.Occurrence >= rule.Count {
+		return nil
+	}
+	next := rule.Next(todo.DeadlineAt.In(l.svcCtx.Location()))
+	for !next.IsZero() && !next.After(now) {
+		next = rule.Next(next)
+	}
+	if next.IsZero() {
+		return nil
+	}
+
+	seriesID := todo.SeriesID
+	if seriesID == 0 {
+		seriesID = todo.ID
+	}
+	nextTodo := &model.Todo{
+		CreatorID:  todo.CreatorID,
+		Title:      todo.Title,
+		Desc:       todo.Desc,
+		DeadlineAt: next,
+		Recurrence: todo.Recurrence,
+		SeriesID:   seriesID,
+		Occurrence: todo.Occurrence + 1,
+		Priority:   todo.Priority,
+	}
+	if err := tx.Create(nextTodo).Error; err != nil {
+		return err
+	}
+	var labels []string
+	if err := tx.Model(&model.TodoLabel{}).Where("todo_id = ?", todo.ID).Pluck("name", &labels).Error; err != nil {
+		return err
+	}
+	if err := setTodoLabels(tx, nextTodo.ID, labels); err != nil {
+		return err
+	}
+
+	var executors []model.UserTodo
+	if err := tx.Where("todo_id = ?", todo.ID).Find(&executors).Error; err != nil {
+		return err
+	}
+	if len(executors) == 0 {
+		return nil
+	}
+	userTodos := make([]model.UserTodo, 0, len(executors))
+	for _, e := range executors {
+		userTodos = append(userTodos, model.UserTodo{TodoID: nextTodo.ID, UserID: e.UserID})
+	}
+	return tx.Create(&userTodos).Error
+}
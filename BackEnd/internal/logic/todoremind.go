@@ -0,0 +1,237 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"BackEnd/internal/model"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// noDeadline 未设置截止时间的待办 DeadlineAt 为 Unix 零点
+var noDeadline = time.Unix(0, 0)
+
+// digestTitles 每日汇总中每类最多列出的待办数
+const digestTitles = 5
+
+// Remind 标记已逾期的待办，并在截止前按配置的提前量提醒执行人
+func (l *todoLogic) Remind(ctx context.Context) error {
+	now := time.Now()
+	if err := l.markOverdue(ctx, now); err != nil {
+		return err
+	}
+
+	offsets := l.svcCtx.Config.TodoReminder.Offsets
+	if len(offsets) == 0 {
+		return nil
+	}
+	var todos []model.Todo
+	if err := l.svcCtx.DB.WithContext(ctx).
+		Where("deadline_at > ? AND deadline_at <= ? AND todo_status NOT IN ?",
+			now, now.Add(time.Duration(slices.Max(offsets))*time.Minute), []int{model.TodoFinished, model.TodoOverdue}).
+		Find(&todos).Error; err != nil {
+		return err
+	}
+
+	for i := range todos {
+		todo := &todos[i]
+		// 已经到达的提前量中最小的一个，比上次提醒的更近时再提醒
+		offset := 0
+		for _, o := range offsets {
+			if o > 0 && !todo.DeadlineAt.Add(-time.Duration(o)*time.Minute).After(now) && (offset == 0 || o < offset) {
+				offset = o
+			}
+		}
+		if offset == 0 || (todo.RemindOffset > 0 && offset >= todo.RemindOffset) {
+			continue
+		}
+		err := l.svcCtx.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return l.remindTodo(tx, todo, offset)
+		})
+		if err != nil {
+			log.Error().Err(err).Uint("todoID", todo.ID).Msg("failed to remind todo executors")
+		}
+	}
+	return nil
+}
+
+func (l *todoLogic) remindTodo(tx *gorm.DB, todo *model.Todo, offset int) error {
+	res := tx.Model(&model.Todo{}).Where("id = ? AND remind_offset = ?", todo.ID, todo.RemindOffset).
+		Update("remind_offset", offset)
+	if res.Error != nil || res.RowsAffected == 0 {
+		return res.Error
+	}
+
+	var uids []uint
+	if err := tx.Model(&model.UserTodo{}).Where("todo_id = ? AND todo_status <> ?", todo.ID, model.TodoFinished).
+		Pluck("user_id", &uids).Error; err != nil {
+		return err
+	}
+	return notify(tx, model.Notification{
+		Type:    model.TodoRemindNotification,
+		Title:   clip("待办即将到期："+todo.Title, 128),
+		Content: fmt.Sprintf("「%s」将于 %s 截止，请及时处理", todo.Title, l.formatDeadline(todo.DeadlineAt)),
+		BizID:   todo.ID,
+	}, uids...)
+}
+
+// markOverdue 将超过截止时间仍未完成的待办及其未完成的执行人标记为已逾期，并通知执行人和创建人
+func (l *todoLogic) markOverdue(ctx context.Context, now time.Time) error {
+	var todos []model.Todo
+	if err := l.svcCtx.DB.WithContext(ctx).
+		Where("deadline_at > ? AND deadline_at <= ? AND todo_status NOT IN ?",
+			noDeadline, now, []int{model.TodoFinished, model.TodoOverdue}).
+		Find(&todos).Error; err != nil {
+		return err
+	}
+
+	for i := range todos {
+		todo := &todos[i]
+		err := l.svcCtx.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			res := tx.Model(&model.Todo{}).
+				Where("id = ? AND todo_status NOT IN ?", todo.ID, []int{model.TodoFinished, model.TodoOverdue}).
+				Update("todo_status", model.TodoOverdue)
+			if res.Error != nil || res.RowsAffected == 0 {
+				return res.Error
+			}
+			if err := addTodoHistory(tx, todo.ID, 0, model.TodoActionStatus, "todoStatus",
+				strconv.Itoa(todo.TodoStatus), strconv.Itoa(model.TodoOverdue), ""); err != nil {
+				return err
+			}
+			if err := tx.Model(&model.UserTodo{}).Where("todo_id = ? AND todo_status <> ?", todo.ID, model.TodoFinished).
+				Update("todo_status", model.TodoOverdue).Error; err != nil {
+				return err
+			}
+
+			var uids []uint
+			if err := tx.Model(&model.UserTodo{}).Where("todo_id = ? AND todo_status = ?", todo.ID, model.TodoOverdue).
+				Pluck("user_id", &uids).Error; err != nil {
+				return err
+			}
+			return notify(tx, model.Notification{
+				Type:    model.TodoOverdueNotification,
+				Title:   clip("待办已逾期："+todo.Title, 128),
+				Content: fmt.Sprintf("「%s」已于 %s 截止，仍未完成", todo.Title, l.formatDeadline(todo.DeadlineAt)),
+				BizID:   todo.ID,
+			}, append(uids, todo.CreatorID)...)
+		})
+		if err != nil {
+			log.Error().Err(err).Uint("todoID", todo.ID).Msg("failed to mark todo overdue")
+		}
+	}
+	return nil
+}
+
+// Digest 每天在配置的时间后给有未完成待办的用户发送一次汇总：今天到期的和已逾期的
+func (l *todoLogic) Digest(ctx context.Context) error {
+	hour := l.svcCtx.Config.TodoReminder.DigestHour
+	if hour <= 0 {
+		return nil
+	}
+	loc := l.svcCtx.Location()
+	now := time.Now().In(loc)
+	if now.Hour() < hour {
+		return nil
+	}
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	dayEnd := dayStart.AddDate(0, 0, 1)
+	day := int64(now.Year()*10000 + int(now.Month())*100 + now.Day())
+
+	var rows []struct {
+		UserID     uint
+		Title      string
+		DeadlineAt time.Time
+	}
+	if err := l.svcCtx.DB.WithContext(ctx).Table("user_todos").
+		Select("user_todos.user_id, todos.title, todos.deadline_at").
+		Joins("JOIN todos ON todos.id = user_todos.todo_id AND todos.deleted_at IS NULL").
+		Where("user_todos.todo_status <> ? AND todos.todo_status <> ? AND todos.deadline_at > ? AND todos.deadline_at < ?",
+			model.TodoFinished, model.TodoFinished, noDeadline, dayEnd).
+		Order("todos.deadline_at").Scan(&rows).Error; err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	// 今天已经发送过汇总的用户
+	var sent []uint
+	if err := l.svcCtx.DB.WithContext(ctx).Model(&model.TodoDigest{}).
+		Where("day = ?", day).Pluck("user_id", &sent).Error; err != nil {
+		return err
+	}
+
+	dueToday := make(map[uint][]string)
+	overdue := make(map[uint][]string)
+	var uids []uint
+	for _, r := range rows {
+		if slices.Contains(sent, r.UserID) {
+			continue
+		}
+		if _, ok := dueToday[r.UserID]; !ok {
+			if _, ok := overdue[r.UserID]; !ok {
+				uids = append(uids, r.UserID)
+			}
+		}
+		if r.DeadlineAt.After(now) {
+			dueToday[r.UserID] = append(dueToday[r.UserID], r.Title)
+		} else {
+			overdue[r.UserID] = append(overdue[r.UserID], r.Title)
+		}
+	}
+
+	for _, uid := range uids {
+		today, late := dueToday[uid], overdue[uid]
+		var parts []string
+		if len(today) > 0 {
+			parts = append(parts, fmt.Sprintf("今天到期 %d 项：%s", len(today), digestList(today)))
+		}
+		if len(late) > 0 {
+			parts = append(parts, fmt.Sprintf("已逾期 %d 项：%s", len(late), digestList(late)))
+		}
+		// 先写入当天的发送记录，其他实例已经写入时跳过，避免重复发送
+		err := l.svcCtx.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.TodoDigest{UserID: uid, Day: day})
+			if res.Error != nil || res.RowsAffected == 0 {
+				return res.Error
+			}
+			return notify(tx, model.Notification{
+				Type:    model.TodoDigestNotification,
+				Title:   fmt.Sprintf("今日待办：%d 项今天到期，%d 项已逾期", len(today), len(late)),
+				Content: clip(strings.Join(parts, "；"), 512),
+			}, uid)
+		})
+		if err != nil {
+			log.Error().Err(err).Uint("userID", uid).Msg("failed to send todo digest")
+		}
+	}
+	return nil
+}
+
+// digestList 列出前几个待办的标题
+func digestList(titles []string) string {
+	if len(titles) <= digestTitles {
+		return strings.Join(titles, "、")
+	}
+	return strings.Join(titles[:digestTitles], "、") + " 等"
+}
+
+// clip 截断超过 n 个字符的文本，用于写入定长的通知标题和内容
+func clip(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+func (l *todoLogic) formatDeadline(t time.Time) string {
+	return t.In(l.svcCtx.Location()).Format("2006-01-02 15:04")
+}
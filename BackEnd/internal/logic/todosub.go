@@ -0,0 +1,183 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"BackEnd/internal/domain"
+	"BackEnd/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxTodoDepth 待办最多的层数，包括顶层待办
+const maxTodoDepth = 3
+
+// checkParent 校验父待办存在、由 userID 创建且子待办的层数不超过 maxTodoDepth
+func checkParent(db *gorm.DB, parentID, userID uint) error {
+	depth := 1
+	for id := parentID; id > 0; depth++ {
+		if depth >= maxTodoDepth {
+			return fmt.Errorf("subtasks can be nested at most %d levels", maxTodoDepth)
+		}
+		var parent model.Todo
+		if err := db.Select("id", "parent_id", "creator_id").First(&parent, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("parent todo not found")
+			}
+			return err
+		}
+		// 添加子待办会改变父待办的进度和自动完成，只有父待办的创建人可以添加
+		if id == parentID && parent.CreatorID != userID {
+			return errTodoCreatorOnly
+		}
+		id = parent.ParentID
+	}
+	return nil
+}
+
+// completeParents 子待办完成后，向上逐级检查开启了自动完成的父待办，子待办全部完成时将父待办及其执行人标记为已完成
+func completeParents(tx *gorm.DB, parentID uint) error {
+	for parentID > 0 {
+		var parent model.Todo
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&parent, parentID).Error; err != nil {
+			return err
+		}
+		if !parent.AutoComplete || parent.TodoStatus == model.TodoFinished {
+			return nil
+		}
+		var unfinished int64
+		if err := tx.Model(&model.Todo{}).Where("parent_id = ? AND todo_status <> ?", parent.ID, model.TodoFinished).
+			Count(&unfinished).Error; err != nil {
+			return err
+		}
+		if unfinished > 0 {
+			return nil
+		}
+		if err := tx.Model(&model.UserTodo{}).Where("todo_id = ?", parent.ID).
+			Update("todo_status", model.TodoFinished).Error; err != nil {
+			return err
+		}
+		if err := addTodoHistory(tx, parent.ID, 0, model.TodoActionStatus, "todoStatus",
+			strconv.Itoa(parent.TodoStatus), strconv.Itoa(model.TodoFinished), "子待办全部完成"); err != nil {
+			return err
+		}
+		if err := tx.Model(&parent).Update("todo_status", model.TodoFinished).Error; err != nil {
+			return err
+		}
+		parentID = parent.ParentID
+	}
+	return nil
+}
+
+// subtreeIDs 返回待办的全部子孙待办ID，不包括自身
+func subtreeIDs(db *gorm.DB, id uint) ([]uint, error) {
+	var res []uint
+	level := []uint{id}
+	for depth := 1; depth < maxTodoDepth && len(level) > 0; depth++ {
+		var children []uint
+		if err := db.Model(&model.Todo{}).Where("parent_id IN ?", level).Pluck("id", &children).Error; err != nil {
+			return nil, err
+		}
+		res = append(res, children...)
+		level = children
+	}
+	return res, nil
+}
+
+// todoProgress 统计待办的直接子待办完成进度，没有子待办的不在结果中
+func todoProgress(db *gorm.DB, ids []uint) (map[uint]*domain.TodoProgress, error) {
+	res := make(map[uint]*domain.TodoProgress)
+	if len(ids) == 0 {
+		return res, nil
+	}
+	var rows []struct {
+		ParentID uint
+		Total    int
+		Done     int
+	}
+	if err := db.Model(&model.Todo{}).
+		Select("parent_id, COUNT(*) AS total, SUM(CASE WHEN todo_status = ? THEN 1 ELSE 0 END) AS done", model.TodoFinished).
+		Where("parent_id IN ?", ids).Group("parent_id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		res[r.ParentID] = &domain.TodoProgress{Done: r.Done, Total: r.Total, Percent: r.Done * 100 / r.Total}
+	}
+	return res, nil
+}
+
+// todoExecutors 查询待办的执行人及各自的完成状态
+func todoExecutors(db *gorm.DB, ids []uint) (map[uint][]*domain.UserTodo, error) {
+	res := make(map[uint][]*domain.UserTodo)
+	if len(ids) == 0 {
+		return res, nil
+	}
+	var rows []struct {
+		TodoID     uint
+		UserID     uint
+		Name       string
+		TodoStatus int
+	}
+	if err := db.Table("user_todos").
+		Select("user_todos.todo_id, user_todos.user_id, users.name, user_todos.todo_status").
+		Joins("JOIN users ON users.id = user_todos.user_id").
+		Where("user_todos.todo_id IN ?", ids).Order("user_todos.created_at").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		res[r.TodoID] = append(res[r.TodoID], &domain.UserTodo{
+			UserId:     strconv.Itoa(int(r.UserID)),
+			UserName:   r.Name,
+			TodoId:     strconv.Itoa(int(r.TodoID)),
+			TodoStatus: r.TodoStatus,
+		})
+	}
+	return res, nil
+}
+
+// subtaskTree 逐层加载待办的子待办树
+func (l *todoLogic) subtaskTree(ctx context.Context, rootID uint) ([]*domain.TodoNode, error) {
+	db := l.svcCtx.DB.WithContext(ctx)
+	nodes := map[uint]*domain.TodoNode{rootID: {}}
+	level := []uint{rootID}
+	for depth := 1; depth < maxTodoDepth && len(level) > 0; depth++ {
+		var children []model.Todo
+		if err := db.Where("parent_id IN ?", level).Order("id").Find(&children).Error; err != nil {
+			return nil, err
+		}
+		ids := make([]uint, 0, len(children))
+		for _, c := range children {
+			ids = append(ids, c.ID)
+		}
+		executors, err := todoExecutors(db, ids)
+		if err != nil {
+			return nil, err
+		}
+		progress, err := todoProgress(db, ids)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, c := range children {
+			node := &domain.TodoNode{
+				ID:         strconv.Itoa(int(c.ID)),
+				Title:      c.Title,
+				TodoStatus: c.TodoStatus,
+				Executors:  executors[c.ID],
+				Progress:   progress[c.ID],
+			}
+			if c.DeadlineAt.After(noDeadline) {
+				node.DeadlineAt = c.DeadlineAt.Unix()
+			}
+			nodes[c.ID] = node
+			parent := nodes[c.ParentID]
+			parent.Children = append(parent.Children, node)
+		}
+		level = ids
+	}
+	return nodes[rootID].Children, nil
+}
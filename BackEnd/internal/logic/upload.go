@@ -0,0 +1,28 @@
+package logic
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+)
+
+// resolveUploadPath 将文件路径解析为上传目录下的绝对路径，上传目录未配置或路径在上传目录之外时返回错误
+func resolveUploadPath(savePath, file string) (string, error) {
+	if savePath == "" {
+		return "", errors.New("upload directory is not configured")
+	}
+	dir, err := filepath.Abs(savePath)
+	if err != nil {
+		return "", err
+	}
+	// 上传接口返回的路径以 SavePath 开头，相对路径按工作目录解析
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(dir, abs)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.New("file is outside the upload directory")
+	}
+	return abs, nil
+}
@@ -0,0 +1,375 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"BackEnd/internal/domain"
+	"BackEnd/internal/model"
+	"BackEnd/internal/svc"
+	"BackEnd/pkg/token"
+	"BackEnd/pkg/util"
+	"BackEnd/pkg/xerr"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// Workflow 审批流程模板管理，仅管理员可修改
+type Workflow interface {
+	List(ctx context.Context, req *domain.ApprovalWorkflowListReq) (resp *domain.ApprovalWorkflowListResp, err error)
+	Info(ctx context.Context, req *domain.IdPathReq) (resp *domain.ApprovalWorkflow, err error)
+	Create(ctx context.Context, req *domain.ApprovalWorkflow) (resp *domain.IdResp, err error)
+	Edit(ctx context.Context, req *domain.ApprovalWorkflow) (err error)
+	Delete(ctx context.Context, req *domain.IdPathReq) (err error)
+	// RoleList 查询自定义审批角色及其成员
+	RoleList(ctx context.Context) (resp *domain.UserRoleListResp, err error)
+	// SetRole 设置审批角色的成员，UserIds 为空时删除该角色
+	SetRole(ctx context.Context, req *domain.UserRole) (err error)
+}
+
+type workflow struct {
+	svcCtx *svc.ServiceContext
+}
+
+func NewWorkflow(svcCtx *svc.ServiceContext) Workflow {
+	return &workflow{
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *workflow) List(ctx context.Context, req *domain.ApprovalWorkflowListReq) (resp *domain.ApprovalWorkflowListResp, err error) {
+	pagination := util.NormalizePagination(req.Page, req.Count)
+
+	db := l.svcCtx.DB.WithContext(ctx).Model(&model.ApprovalWorkflow{})
+	if req.Type > 0 {
+		db = db.Where("type = ?", req.Type)
+	}
+
+	var total int64
+	if err = db.Count(&total).Error; err != nil {
+		log.Error().Err(err).Msg("failed to count approval workflows")
+		return nil, xerr.New(err)
+	}
+
+	var workflows []*model.ApprovalWorkflow
+	if err = db.Order("type, priority desc, id").Offset(pagination.Offset).Limit(pagination.Count).Find(&workflows).Error; err != nil {
+		log.Error().Err(err).Msg("failed to list approval workflows")
+		return nil, xerr.New(err)
+	}
+
+	list := make([]*domain.ApprovalWorkflow, 0, len(workflows))
+	for _, w := range workflows {
+		list = append(list, toDomainWorkflow(w))
+	}
+	return &domain.ApprovalWorkflowListResp{Count: total, List: list}, nil
+}
+
+func (l *workflow) Info(ctx context.Context, req *domain.IdPathReq) (resp *domain.ApprovalWorkflow, err error) {
+	var w model.ApprovalWorkflow
+	if err := l.svcCtx.DB.WithContext(ctx).First(&w, req.Id).Error; err != nil {
+		log.Error().Err(err).Str("id", req.Id).Msg("failed to find approval workflow")
+		return nil, xerr.New(err)
+	}
+	return toDomainWorkflow(&w), nil
+}
+
+func (l *workflow) Create(ctx context.Context, req *domain.ApprovalWorkflow) (resp *domain.IdResp, err error) {
+	if err := checkAdmin(ctx, l.svcCtx); err != nil {
+		return nil, err
+	}
+
+	w, err := toModelWorkflow(req)
+	if err != nil {
+		return nil, xerr.New(err)
+	}
+	if err := l.svcCtx.DB.WithContext(ctx).Create(w).Error; err != nil {
+		log.Error().Err(err).Msg("failed to create approval workflow")
+		return nil, xerr.New(err)
+	}
+	return &domain.IdResp{Id: util.UintToString(w.ID)}, nil
+}
+
+func (l *workflow) Edit(ctx context.Context, req *domain.ApprovalWorkflow) (err error) {
+	if err := checkAdmin(ctx, l.svcCtx); err != nil {
+		return err
+	}
+
+	id, err := util.StringToUint(req.Id)
+	if err != nil {
+		return xerr.New(errors.New("invalid workflow id"))
+	}
+	var old model.ApprovalWorkflow
+	if err := l.svcCtx.DB.WithContext(ctx).First(&old, id).Error; err != nil {
+		return xerr.New(err)
+	}
+
+	w, err := toModelWorkflow(req)
+	if err != nil {
+		return xerr.New(err)
+	}
+	w.Model = old.Model
+	if err := l.svcCtx.DB.WithContext(ctx).Save(w).Error; err != nil {
+		log.Error().Err(err).Msg("failed to update approval workflow")
+		return xerr.New(err)
+	}
+	return nil
+}
+
+func (l *workflow) Delete(ctx context.Context, req *domain.IdPathReq) (err error) {
+	if err := checkAdmin(ctx, l.svcCtx); err != nil {
+		return err
+	}
+	if err := l.svcCtx.DB.WithContext(ctx).Delete(&model.ApprovalWorkflow{}, req.Id).Error; err != nil {
+		log.Error().Err(err).Str("id", req.Id).Msg("failed to delete approval workflow")
+		return xerr.New(err)
+	}
+	return nil
+}
+
+func (l *workflow) RoleList(ctx context.Context) (resp *domain.UserRoleListResp, err error) {
+	var roles []model.UserRole
+	if err := l.svcCtx.DB.WithContext(ctx).Order("role, user_id").Find(&roles).Error; err != nil {
+		log.Error().Err(err).Msg("failed to list user roles")
+		return nil, xerr.New(err)
+	}
+
+	resp = &domain.UserRoleListResp{List: make([]*domain.UserRole, 0)}
+	for _, r := range roles {
+		n := len(resp.List)
+		if n == 0 || resp.List[n-1].Role != r.Role {
+			resp.List = append(resp.List, &domain.UserRole{Role: r.Role})
+			n++
+		}
+		resp.List[n-1].UserIds = append(resp.List[n-1].UserIds, util.UintToString(r.UserID))
+	}
+	return resp, nil
+}
+
+func (l *workflow) SetRole(ctx context.Context, req *domain.UserRole) (err error) {
+	if err := checkAdmin(ctx, l.svcCtx); err != nil {
+		return err
+	}
+
+	role := strings.TrimSpace(req.Role)
+	if role == "" {
+		return xerr.New(errors.New("role is required"))
+	}
+	if role == model.RoleLeader || role == model.RoleSuperior {
+		return xerr.New(fmt.Errorf("role %q is built in and resolved from departments", role))
+	}
+
+	return l.svcCtx.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("role = ?", role).Delete(&model.UserRole{}).Error; err != nil {
+			return xerr.New(err)
+		}
+		var roles []model.UserRole
+		for _, uid := range util.StringToUintSlice(req.UserIds) {
+			roles = append(roles, model.UserRole{Role: role, UserID: uid})
+		}
+		if len(roles) == 0 {
+			return nil
+		}
+		if err := tx.Create(&roles).Error; err != nil {
+			log.Error().Err(err).Str("role", role).Msg("failed to set user role")
+			return xerr.New(err)
+		}
+		return nil
+	})
+}
+
+// checkAdmin 校验当前登录用户是否为管理员
+func checkAdmin(ctx context.Context, svcCtx *svc.ServiceContext) error {
+	uid, err := token.GetUserID(ctx)
+	if err != nil {
+		return xerr.New(err)
+	}
+	var user model.User
+	if err := svcCtx.DB.WithContext(ctx).First(&user, uid).Error; err != nil {
+		return xerr.New(err)
+	}
+	if !user.IsAdmin {
+		return xerr.New(errors.New("permission denied: admin only"))
+	}
+	return nil
+}
+
+// matchWorkflow 查找适用于审批单的流程模板，指定表单的模板优先，没有匹配时返回 nil
+func matchWorkflow(ctx context.Context, db *gorm.DB, approval *model.Approval) (*model.ApprovalWorkflow, error) {
+	var workflows []*model.ApprovalWorkflow
+	if err := db.WithContext(ctx).
+		Where("type = ? AND enabled = ? AND (form_id = 0 OR form_id = ?)", approval.Type, true, approval.FormID).
+		Order("form_id desc, priority desc, id").
+		Find(&workflows).Error; err != nil {
+		return nil, err
+	}
+	for _, w := range workflows {
+		if w.Match(approval) {
+			return w, nil
+		}
+	}
+	return nil, nil
+}
+
+// toModelWorkflow 校验并转换流程模板
+func toModelWorkflow(req *domain.ApprovalWorkflow) (*model.ApprovalWorkflow, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("workflow name is required")
+	}
+	if model.ApprovalType(req.Type).ToString() == "未知" {
+		return nil, fmt.Errorf("unknown approval type %d", req.Type)
+	}
+	if len(req.Steps) == 0 {
+		return nil, errors.New("workflow needs at least one step")
+	}
+
+	w := &model.ApprovalWorkflow{
+		Name:              name,
+		Type:              model.ApprovalType(req.Type),
+		Priority:          req.Priority,
+		Enabled:           req.Enabled,
+		RequireAttachment: req.RequireAttachment,
+		Desc:              req.Desc,
+		CopyUserIds:       util.StringToUintSlice(req.CopyUserIds),
+	}
+	if req.FormId != "" {
+		if w.Type != model.FormApproval {
+			return nil, errors.New("form id is only supported for custom form approvals")
+		}
+		formID, err := util.StringToUint(req.FormId)
+		if err != nil {
+			return nil, errors.New("invalid form id")
+		}
+		w.FormID = formID
+	}
+	for _, r := range req.CopyRoles {
+		if r = strings.TrimSpace(r); r != "" {
+			w.CopyRoles = append(w.CopyRoles, r)
+		}
+	}
+
+	for _, c := range req.Conditions {
+		if c == nil {
+			continue
+		}
+		cond := model.WorkflowCondition{Field: c.Field, Op: c.Op, Value: c.Value, Values: c.Values}
+		if t, ok := workflowFields[c.Field]; (!ok || t != w.Type) && !isFormField(w.Type, c.Field) {
+			return nil, fmt.Errorf("condition field %q is not supported for %s approvals", c.Field, w.Type.ToString())
+		}
+		if !validConditionOp(c.Op) {
+			return nil, fmt.Errorf("unknown condition op %q", c.Op)
+		}
+		if c.Op == model.ConditionIn && len(c.Values) == 0 {
+			return nil, fmt.Errorf("condition %s in needs values", c.Field)
+		}
+		w.Conditions = append(w.Conditions, cond)
+	}
+
+	for i, s := range req.Steps {
+		if s == nil {
+			continue
+		}
+		step := model.WorkflowStep{
+			Name:    s.Name,
+			Mode:    model.StepMode(s.Mode),
+			UserIds: util.StringToUintSlice(s.UserIds),
+		}
+		if step.Mode == "" {
+			step.Mode = model.SequentialStep
+		}
+		if step.Mode != model.SequentialStep && step.Mode != model.AllOfStep && step.Mode != model.AnyOfStep {
+			return nil, fmt.Errorf("step %d: unknown mode %q", i+1, s.Mode)
+		}
+		for _, r := range s.Roles {
+			if r = strings.TrimSpace(r); r != "" {
+				step.Roles = append(step.Roles, r)
+			}
+		}
+		if len(step.UserIds) == 0 && len(step.Roles) == 0 {
+			return nil, fmt.Errorf("step %d needs approvers or roles", i+1)
+		}
+		w.Steps = append(w.Steps, step)
+	}
+	return w, nil
+}
+
+// workflowFields 模板条件支持的字段及其所属的审批类型
+var workflowFields = map[string]model.ApprovalType{
+	model.FieldLeaveType:            model.LeaveApproval,
+	model.FieldLeaveDuration:        model.LeaveApproval,
+	model.FieldLeaveTimeType:        model.LeaveApproval,
+	model.FieldGoOutDuration:        model.GoOutApproval,
+	model.FieldMakeCardCheckType:    model.MakeCardApproval,
+	model.FieldOvertimeDuration:     model.OvertimeApproval,
+	model.FieldOvertimeCompensation: model.OvertimeApproval,
+	model.FieldTripDuration:         model.TripApproval,
+	model.FieldTripCost:             model.TripApproval,
+}
+
+// isFormField 自定义表单审批可以使用 form.<字段名> 作为条件字段
+func isFormField(typ model.ApprovalType, field string) bool {
+	return typ == model.FormApproval && len(field) > len(model.FieldFormPrefix) &&
+		strings.HasPrefix(field, model.FieldFormPrefix)
+}
+
+func validConditionOp(op string) bool {
+	switch op {
+	case model.ConditionEq, model.ConditionNe, model.ConditionGt, model.ConditionGte,
+		model.ConditionLt, model.ConditionLte, model.ConditionIn:
+		return true
+	}
+	return false
+}
+
+func toDomainWorkflow(w *model.ApprovalWorkflow) *domain.ApprovalWorkflow {
+	res := &domain.ApprovalWorkflow{
+		Id:                util.UintToString(w.ID),
+		Name:              w.Name,
+		Type:              int(w.Type),
+		Priority:          w.Priority,
+		Enabled:           w.Enabled,
+		RequireAttachment: w.RequireAttachment,
+		Desc:              w.Desc,
+		Conditions:        make([]*domain.WorkflowCondition, 0, len(w.Conditions)),
+		Steps:             make([]*domain.WorkflowStep, 0, len(w.Steps)),
+		CopyRoles:         w.CopyRoles,
+		CreateAt:          w.CreatedAt.Unix(),
+		UpdateAt:          w.UpdatedAt.Unix(),
+	}
+	for _, c := range w.Conditions {
+		res.Conditions = append(res.Conditions, &domain.WorkflowCondition{
+			Field: c.Field, Op: c.Op, Value: c.Value, Values: c.Values,
+		})
+	}
+	if w.FormID > 0 {
+		res.FormId = util.UintToString(w.FormID)
+	}
+	for _, uid := range w.CopyUserIds {
+		res.CopyUserIds = append(res.CopyUserIds, util.UintToString(uid))
+	}
+	for _, s := range w.Steps {
+		step := &domain.WorkflowStep{Name: s.Name, Mode: string(s.Mode), Roles: s.Roles}
+		for _, uid := range s.UserIds {
+			step.UserIds = append(step.UserIds, util.UintToString(uid))
+		}
+		res.Steps = append(res.Steps, step)
+	}
+	return res
+}
+
+// uniqueIDs 去重并保持首次出现的顺序
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	res := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if id == 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		res = append(res, id)
+	}
+	return res
+}
@@ -12,24 +12,38 @@ type Approval struct {
 	No       string         `gorm:"type:varchar(32);uniqueIndex"` // 审批单号
 	Title    string         `gorm:"type:varchar(128)"`
 	Reason   string         `gorm:"type:varchar(255)"`
-	Type     ApprovalType   // 1:请假, 2:外出, 3:补卡
+	Type     ApprovalType   // 1:请假, 2:外出, 3:补卡, 4:加班, 5:出差, 6:自定义表单
 	Status   ApprovalStatus // 0:待审批, 1:通过, 2:驳回, 3:撤销, 4:草稿
 	Abstract string         `gorm:"type:varchar(255)"` // 摘要
 
+	// 重新提交的审批单关联原审批单，Revision 从1开始递增
+	ParentID uint `gorm:"index;comment:原审批单ID"`
+	Revision int  `gorm:"default:1;comment:修订版本"`
+
 	// 申请人关联
 	UserID uint
 	User   User `gorm:"foreignKey:UserID"`
 
 	// 审批流程关联
-	Approvers []Approver `gorm:"foreignKey:ApprovalID"`
+	Approvers   []Approver     `gorm:"foreignKey:ApprovalID"`
+	CopyPersons []ApprovalCopy `gorm:"foreignKey:ApprovalID"` // 抄送人
 
 	// 业务详情数据 (JSON存储)
 	// GORM v2 支持 serializer:json，会自动将结构体序列化为 JSON 字符串存入数据库
 	MakeCard *MakeCard `gorm:"serializer:json"`
 	Leave    *Leave    `gorm:"serializer:json"`
 	GoOut    *GoOut    `gorm:"serializer:json"`
+	Overtime *Overtime `gorm:"serializer:json"`
+	// 出差详情
+	BusinessTrip *BusinessTrip `gorm:"serializer:json"`
+	// 自定义表单
+	FormID uint      `gorm:"index;default:0;comment:自定义表单ID"`
+	Form   *FormData `gorm:"serializer:json"`
+
+	Attachments []string `gorm:"serializer:json"` // 附件地址，如病假证明
 
 	// 时间字段
+	StartAt     int64 `gorm:"index;default:0;comment:业务开始时间"` // 请假、外出、加班、出差的开始时间或补卡时间，用于按业务时间统计
 	FinishAt    *time.Time
 	FinishDay   int64
 	FinishMonth int64
@@ -43,8 +57,47 @@ type Approver struct {
 	UserID     uint // 审批人 ID
 	User       User `gorm:"foreignKey:UserID"` // 关联 User 表获取名字
 
-	Status ApprovalStatus `gorm:"default:0"`         // 0:待审批, 1:已通过, 2:已驳回
-	Reason string         `gorm:"type:varchar(255)"` // 审批意见
+	Status ApprovalStatus `gorm:"default:0"`                           // 0:待审批, 1:已通过, 2:已驳回
+	Reason string         `gorm:"type:varchar(255)"`                   // 审批意见
+	Step   int            `gorm:"default:0;comment:审批步骤"`              // 审批顺序，从0开始
+	Mode   StepMode       `gorm:"type:varchar(16);comment:同一步骤多人审批方式"` // and=会签 or=或签，为空时按会签处理
+
+	// 委托审批：UserID 为代理人，OriginalUserID 为原审批人
+	OriginalUserID uint `gorm:"index;default:0;comment:委托前的原审批人ID"`
+	OriginalUser   User `gorm:"foreignKey:OriginalUserID"`
+	Reclaimed      bool `gorm:"default:false;comment:原审批人已收回，不再自动委托"`
+
+	// 超时提醒和升级
+	RemindedAt  *time.Time `gorm:"comment:最近一次超时提醒时间"`
+	EscalatedAt *time.Time `gorm:"comment:超时升级时间"`
+}
+
+// CurrentStep 返回当前待审批的步骤，即尚未完成的最小步骤
+// 会签步骤需全部通过、或签步骤任一人通过即完成，前面的步骤完成后才会轮到下一步，全部完成时 ok 为 false
+func CurrentStep(approvers []Approver) (step int, ok bool) {
+	passed := make(map[int]bool) // 已有人通过的或签步骤
+	for _, a := range approvers {
+		if a.Mode == AnyOfStep && a.Status == Pass {
+			passed[a.Step] = true
+		}
+	}
+	for _, a := range approvers {
+		if a.Status != Processed || passed[a.Step] {
+			continue
+		}
+		if !ok || a.Step < step {
+			step, ok = a.Step, true
+		}
+	}
+	return step, ok
+}
+
+// ApprovalCopy 审批抄送人，审批完成后通知
+type ApprovalCopy struct {
+	gorm.Model
+	ApprovalID uint `gorm:"index;comment:审批单ID"`
+	UserID     uint `gorm:"index;comment:抄送人ID"`
+	User       User `gorm:"foreignKey:UserID"`
 }
 
 // 下面是 JSON 结构体定义，不需要 gorm.Model
@@ -71,6 +124,32 @@ type GoOut struct {
 	Reason    string  `json:"reason,omitempty"`    //请假原由
 }
 
+type Overtime struct {
+	StartTime    int64                `json:"startTime,omitempty"`    //开始时间
+	EndTime      int64                `json:"endTime,omitempty"`      //结束时间
+	Duration     float32              `json:"duration,omitempty"`     //时长（小时）
+	Compensation OvertimeCompensation `json:"compensation,omitempty"` //补偿方式
+	Reason       string               `json:"reason,omitempty"`       //加班原由
+}
+
+type BusinessTrip struct {
+	StartTime     int64         `json:"startTime,omitempty"`     //出发时间
+	EndTime       int64         `json:"endTime,omitempty"`       //返回时间
+	Duration      float32       `json:"duration,omitempty"`      //天数，按自然日计算
+	Destinations  []string      `json:"destinations,omitempty"`  //目的地
+	Itinerary     []TripSegment `json:"itinerary,omitempty"`     //行程
+	EstimatedCost float64       `json:"estimatedCost,omitempty"` //预估费用（元）
+	Reason        string        `json:"reason,omitempty"`        //出差事由
+}
+
+// TripSegment 出差行程中的一段
+type TripSegment struct {
+	Date      int64  `json:"date,omitempty"`      //出发日期
+	From      string `json:"from,omitempty"`      //出发地
+	To        string `json:"to,omitempty"`        //到达地
+	Transport string `json:"transport,omitempty"` //交通方式，如飞机、火车
+}
+
 // Enums and Constants
 
 type ApprovalType int
@@ -79,6 +158,9 @@ const (
 	LeaveApproval    ApprovalType = 1 // 请假
 	GoOutApproval    ApprovalType = 2 // 外出
 	MakeCardApproval ApprovalType = 3 // 补卡
+	OvertimeApproval ApprovalType = 4 // 加班
+	TripApproval     ApprovalType = 5 // 出差
+	FormApproval     ApprovalType = 6 // 自定义表单
 )
 
 func (t ApprovalType) ToString() string {
@@ -89,6 +171,30 @@ func (t ApprovalType) ToString() string {
 		return "外出"
 	case MakeCardApproval:
 		return "补卡"
+	case OvertimeApproval:
+		return "加班"
+	case TripApproval:
+		return "出差"
+	case FormApproval:
+		return "自定义表单"
+	}
+	return "未知"
+}
+
+// OvertimeCompensation 加班补偿方式
+type OvertimeCompensation int
+
+const (
+	PayCompensation  OvertimeCompensation = 1 // 加班费
+	RestCompensation OvertimeCompensation = 2 // 调休，审批通过后计入调休余额
+)
+
+func (c OvertimeCompensation) ToString() string {
+	switch c {
+	case PayCompensation:
+		return "加班费"
+	case RestCompensation:
+		return "调休"
 	}
 	return "未知"
 }
@@ -103,6 +209,22 @@ const (
 	Draft     ApprovalStatus = 4 // 草稿
 )
 
+func (s ApprovalStatus) ToString() string {
+	switch s {
+	case Processed:
+		return "待审批"
+	case Pass:
+		return "已通过"
+	case Refuse:
+		return "已驳回"
+	case Cancel:
+		return "已撤销"
+	case Draft:
+		return "草稿"
+	}
+	return "未知"
+}
+
 type LeaveType int
 
 const (
@@ -151,9 +273,13 @@ const (
 )
 
 // 1. 小时， 2. 天，3. 半天，4. 上半天， 5. 下半天
+// 请假时长由服务端按工作日历计算，小时按工作时段计算，其余均以天为单位、最小单位为半天
 type TimeFormatType int
 
 const (
-	HourTimeFormatType TimeFormatType = iota + 1
-	DayTimeFormatType
+	HourTimeFormatType      TimeFormatType = iota + 1
+	DayTimeFormatType                      // 按天
+	HalfDayTimeFormatType                  // 按半天，与按天相同
+	MorningTimeFormatType                  // 开始日期的上半天
+	AfternoonTimeFormatType                // 开始日期的下半天
 )
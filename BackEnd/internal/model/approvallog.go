@@ -0,0 +1,39 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+// ApprovalAction 审批记录的操作类型
+type ApprovalAction string
+
+const (
+	ActionCreate     ApprovalAction = "created"     // 创建审批单（含草稿）
+	ActionSubmit     ApprovalAction = "submitted"   // 提交审批
+	ActionView       ApprovalAction = "viewed"      // 首次查看
+	ActionPass       ApprovalAction = "approved"    // 审批通过
+	ActionRefuse     ApprovalAction = "rejected"    // 审批驳回
+	ActionComment    ApprovalAction = "commented"   // 评论
+	ActionWithdraw   ApprovalAction = "withdrawn"   // 申请人撤回
+	ActionCancel     ApprovalAction = "cancelled"   // 已通过的请假撤销（销假）
+	ActionRemind     ApprovalAction = "remind"      // 超时提醒审批人
+	ActionEscalate   ApprovalAction = "escalate"    // 超时转交上级
+	ActionAutoPass   ApprovalAction = "auto_pass"   // 超时自动通过
+	ActionAutoRefuse ApprovalAction = "auto_refuse" // 超时自动驳回
+	ActionDelegate   ApprovalAction = "delegated"   // 按委托转交代理人审批
+	ActionReclaim    ApprovalAction = "reclaimed"   // 原审批人收回委托
+)
+
+// ApprovalLog 审批时间线，按时间顺序记录审批单上发生的操作
+type ApprovalLog struct {
+	gorm.Model
+	ApprovalID  uint           `gorm:"index;comment:审批单ID"`
+	ApproverID  uint           `gorm:"comment:关联的审批节点ID"`
+	UserID      uint           `gorm:"comment:操作人ID，系统操作为0"`
+	User        User           `gorm:"foreignKey:UserID"`
+	TargetID    uint           `gorm:"comment:操作对象用户ID，如被提醒或转交的审批人"`
+	Target      User           `gorm:"foreignKey:TargetID"`
+	Action      ApprovalAction `gorm:"type:varchar(32);comment:操作类型"`
+	Content     string         `gorm:"type:varchar(512);comment:说明或评论内容"`
+	Attachments []string       `gorm:"serializer:json;comment:评论附件"`
+}
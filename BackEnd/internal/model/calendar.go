@@ -0,0 +1,13 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+// CalendarDay 工作日历中的特殊日期：法定节假日放假或调休上班，其余日期按周末规则判断
+type CalendarDay struct {
+	gorm.Model
+	Date string `gorm:"type:varchar(10);uniqueIndex;comment:日期 2006-01-02"`
+	Kind int    `gorm:"comment:类型 1=放假 2=调休上班"`
+	Name string `gorm:"type:varchar(64);comment:节日名称"`
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ApprovalDelegation 审批委托：委托人在指定时间段内的审批由代理人处理，如请假期间
+type ApprovalDelegation struct {
+	gorm.Model
+	UserID     uint         `gorm:"index;comment:委托人ID"`
+	User       User         `gorm:"foreignKey:UserID"`
+	DelegateID uint         `gorm:"index;comment:代理人ID"`
+	Delegate   User         `gorm:"foreignKey:DelegateID"`
+	Type       ApprovalType `gorm:"default:0;comment:审批类型，0表示全部类型"`
+	StartAt    time.Time    `gorm:"index;comment:开始时间"`
+	EndAt      time.Time    `gorm:"index;comment:结束时间"`
+	Reason     string       `gorm:"type:varchar(255);comment:委托原因"`
+}
+
+// Active 判断委托在 t 时刻是否生效
+func (d *ApprovalDelegation) Active(t time.Time) bool {
+	return !t.Before(d.StartAt) && !t.After(d.EndAt)
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+// ApprovalForm 管理员自定义的审批表单，提交时按字段定义校验，审批类型为 FormApproval
+type ApprovalForm struct {
+	gorm.Model
+	Code             string      `gorm:"type:varchar(32);uniqueIndex;comment:表单编码"`
+	Name             string      `gorm:"type:varchar(64);not null;comment:表单名称"`
+	Desc             string      `gorm:"type:varchar(255);comment:说明"`
+	Fields           []FormField `gorm:"serializer:json;comment:字段定义"`
+	AbstractTemplate string      `gorm:"type:varchar(255);comment:摘要模板，{key} 替换为字段值"`
+	Enabled          bool        `gorm:"comment:是否启用"`
+}
+
+// FormField 表单字段定义，见 pkg/formx.Field
+type FormField struct {
+	Key       string   `json:"key"`
+	Label     string   `json:"label"`
+	Type      string   `json:"type"`
+	Required  bool     `json:"required,omitempty"`
+	Options   []string `json:"options,omitempty"`
+	Min       *float64 `json:"min,omitempty"`
+	Max       *float64 `json:"max,omitempty"`
+	MaxLength int      `json:"maxLength,omitempty"`
+}
+
+// FormData 自定义表单审批单提交的数据，保存提交时的表单定义，表单修改后仍能正确展示
+type FormData struct {
+	Name   string         `json:"name"`
+	Fields []FormField    `json:"fields"`
+	Values map[string]any `json:"values"`
+}
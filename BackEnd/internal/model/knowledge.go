@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// KnowledgeDocument 知识库文档，记录内容哈希与切分参数，用于增量更新
+type KnowledgeDocument struct {
+	gorm.Model
+	Source       string     `gorm:"type:varchar(512);uniqueIndex;comment:来源文件路径"`
+	DocType      string     `gorm:"type:varchar(32);comment:文档类型"`
+	Hash         string     `gorm:"type:char(64);comment:文件内容哈希"`
+	ChunkSize    int        `gorm:"comment:切分块大小"`
+	ChunkOverlap int        `gorm:"comment:切分重叠大小"`
+	ChunkCount   int        `gorm:"comment:文档块数量"`
+	IndexedAt    *time.Time `gorm:"comment:最近索引时间"`
+}
+
+// TableName 指定表名
+func (KnowledgeDocument) TableName() string {
+	return "knowledge_documents"
+}
+
+// KnowledgeChunk 知识库文档块，作为关键词索引的数据来源
+type KnowledgeChunk struct {
+	gorm.Model
+	DocumentID uint   `gorm:"index;comment:所属文档ID"`
+	Source     string `gorm:"type:varchar(512);index;comment:来源文件路径"`
+	ChunkIndex int    `gorm:"comment:在来源文件中的序号"`
+	Hash       string `gorm:"type:char(64);index;comment:内容哈希"`
+	Content    string `gorm:"type:text;comment:文档块内容"`
+}
+
+// TableName 指定表名
+func (KnowledgeChunk) TableName() string {
+	return "knowledge_chunks"
+}
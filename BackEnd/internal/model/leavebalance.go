@@ -0,0 +1,49 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+// LeaveBalance 假期余额，按用户、假期类型、年度记录，单位为天
+// 可用余额 = Total - Used - Frozen，审批中的请假先冻结，通过后转为已使用
+type LeaveBalance struct {
+	gorm.Model
+	UserID uint      `gorm:"uniqueIndex:idx_balance_user_type_year;comment:用户ID"`
+	Type   LeaveType `gorm:"uniqueIndex:idx_balance_user_type_year;comment:假期类型"`
+	Year   int       `gorm:"uniqueIndex:idx_balance_user_type_year;comment:年度"`
+	Total  float64   `gorm:"comment:额度（天），含年度发放和管理员调整"`
+	Used   float64   `gorm:"comment:已使用（天）"`
+	Frozen float64   `gorm:"comment:审批中冻结（天）"`
+}
+
+// Available 可用余额
+func (b *LeaveBalance) Available() float64 {
+	return b.Total - b.Used - b.Frozen
+}
+
+// LedgerKind 假期余额变动类型
+type LedgerKind string
+
+const (
+	LedgerAccrual  LedgerKind = "accrual"  // 年度发放
+	LedgerAdjust   LedgerKind = "adjust"   // 管理员调整
+	LedgerFreeze   LedgerKind = "freeze"   // 提交请假冻结
+	LedgerUnfreeze LedgerKind = "unfreeze" // 请假驳回或撤回解冻
+	LedgerDeduct   LedgerKind = "deduct"   // 请假通过扣减
+	LedgerRestore  LedgerKind = "restore"  // 已通过的请假撤销后返还
+	LedgerOvertime LedgerKind = "overtime" // 加班调休计入
+)
+
+// LeaveLedger 假期余额流水，只增不改
+type LeaveLedger struct {
+	gorm.Model
+	UserID     uint       `gorm:"index:idx_ledger_user_type_year;comment:用户ID"`
+	Type       LeaveType  `gorm:"index:idx_ledger_user_type_year;comment:假期类型"`
+	Year       int        `gorm:"index:idx_ledger_user_type_year;comment:年度"`
+	Kind       LedgerKind `gorm:"type:varchar(16);comment:变动类型"`
+	Days       float64    `gorm:"comment:变动天数"`
+	Available  float64    `gorm:"comment:变动后的可用余额"`
+	ApprovalID uint       `gorm:"index;comment:关联审批单ID"`
+	OperatorID uint       `gorm:"comment:操作人ID，系统操作为0"`
+	Remark     string     `gorm:"type:varchar(255);comment:备注"`
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// NotificationType 站内通知类型
+type NotificationType string
+
+const (
+	ApprovalCopyNotification     NotificationType = "approval_copy"     // 审批完成后通知抄送人
+	ApprovalRemindNotification   NotificationType = "approval_remind"   // 审批超时提醒审批人
+	ApprovalEscalateNotification NotificationType = "approval_escalate" // 审批超时转交给上级
+	ApprovalCommentNotification  NotificationType = "approval_comment"  // 审批单收到评论
+	TodoRemindNotification       NotificationType = "todo_remind"       // 待办即将到期提醒执行人
+	TodoOverdueNotification      NotificationType = "todo_overdue"      // 待办逾期通知执行人和创建人
+	TodoDigestNotification       NotificationType = "todo_digest"       // 每日待办汇总
+	TodoAssignNotification       NotificationType = "todo_assign"       // 待办改派或转交给新的执行人
+)
+
+// Notification 站内通知，用户通过通知列表查看
+type Notification struct {
+	gorm.Model
+	UserID  uint             `gorm:"index;comment:接收人ID"`
+	Type    NotificationType `gorm:"type:varchar(32);index;comment:通知类型"`
+	Title   string           `gorm:"type:varchar(128);comment:标题"`
+	Content string           `gorm:"type:varchar(512);comment:内容"`
+	BizID   uint             `gorm:"index;comment:关联业务ID，如审批单ID"`
+	ReadAt  *time.Time       `gorm:"comment:阅读时间，为空表示未读"`
+}
+
+// TodoDigest 每日待办汇总的发送记录，同一用户每天只有一条，用于多个实例同时运行时避免重复发送
+type TodoDigest struct {
+	ID        uint  `gorm:"primarykey"`
+	UserID    uint  `gorm:"uniqueIndex:idx_digest_user_day;comment:接收人ID"`
+	Day       int64 `gorm:"uniqueIndex:idx_digest_user_day;comment:汇总日期(20221011)"`
+	CreatedAt time.Time
+}
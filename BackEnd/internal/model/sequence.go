@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// Sequence 流水号计数器，Name 为计数键，如 QJ-20261017-
+type Sequence struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"type:varchar(64);uniqueIndex;comment:计数键"`
+	Value     int64  `gorm:"comment:当前流水号"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -6,6 +6,14 @@ import (
 "gorm.io/gorm"
 )
 
+// 待办的完成状态，用于 Todo.TodoStatus 和 UserTodo.TodoStatus
+const (
+	TodoPending    = 0 // 待处理
+	TodoInProgress = 1 // 进行中
+	TodoFinished   = 2 // 已完成
+	TodoOverdue    = 3 // 已逾期，超过截止时间仍未完成
+)
+
 // Todo 待办事项
 type Todo struct {
 	ID          uint           `gorm:"primaryKey"`
@@ -15,6 +23,14 @@ type Todo struct {
 	DeadlineAt  time.Time      `gorm:"comment:截止时间"`
 	Status      int            `gorm:"default:0;comment:状态"` // 业务状态
 	TodoStatus  int            `gorm:"default:0;comment:待办状态"` // 完成状态
+	Recurrence  string         `gorm:"type:varchar(255);comment:重复规则，RRULE 格式"`
+	SeriesID    uint           `gorm:"index;default:0;comment:重复待办的首个实例ID"`
+	Occurrence  int            `gorm:"default:0;comment:重复待办的第几次"`
+	NextCreated bool           `gorm:"default:false;comment:是否已生成下一次重复"`
+	RemindOffset int           `gorm:"default:0;comment:已发送的最近一次截止提醒，截止前的分钟数，0 表示未提醒"`
+	ParentID    uint           `gorm:"index;default:0;comment:父待办ID，0 表示顶层待办"`
+	AutoComplete bool          `gorm:"default:false;comment:子待办全部完成时自动完成"`
+	Priority    int            `gorm:"index;default:0;comment:优先级 0=无 1=低 2=中 3=高 4=紧急"`
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 	DeletedAt   gorm.DeletedAt `gorm:"index"`
@@ -23,6 +39,23 @@ type Todo struct {
 	Creator     User           `gorm:"foreignKey:CreatorID"`
 	Executors   []User         `gorm:"many2many:user_todos;"`
 	Records     []TodoRecord   `gorm:"foreignKey:TodoID"`
+	Labels      []TodoLabel    `gorm:"foreignKey:TodoID"`
+	History     []TodoHistory  `gorm:"foreignKey:TodoID"`
+}
+
+// 待办优先级
+const (
+	TodoPriorityNone   = 0 // 无
+	TodoPriorityLow    = 1 // 低
+	TodoPriorityMedium = 2 // 中
+	TodoPriorityHigh   = 3 // 高
+	TodoPriorityUrgent = 4 // 紧急
+)
+
+// TodoLabel 待办标签，标签由用户自由填写
+type TodoLabel struct {
+	TodoID uint   `gorm:"primaryKey;comment:待办ID"`
+	Name   string `gorm:"primaryKey;type:varchar(32);index;comment:标签名"`
 }
 
 // TodoRecord 待办事项操作记录
@@ -38,6 +71,33 @@ type TodoRecord struct {
 	User      User      `gorm:"foreignKey:UserID"`
 }
 
+// TodoAction 待办变更记录的操作类型
+type TodoAction string
+
+const (
+	TodoActionUpdate   TodoAction = "updated"    // 创建人修改待办字段
+	TodoActionStatus   TodoAction = "status"     // 完成状态变化，操作人为0时是系统变更
+	TodoActionAssign   TodoAction = "assigned"   // 创建人修改执行人
+	TodoActionReassign TodoAction = "reassigned" // 创建人将执行人改派给其他人
+	TodoActionHandoff  TodoAction = "handed_off" // 执行人将自己的待办转交给其他人
+)
+
+// TodoHistory 待办变更记录，只追加不修改
+type TodoHistory struct {
+	ID        uint       `gorm:"primaryKey"`
+	TodoID    uint       `gorm:"index;not null;comment:待办ID"`
+	UserID    uint       `gorm:"comment:操作人ID，系统操作为0"`
+	Action    TodoAction `gorm:"type:varchar(32);comment:操作类型"`
+	Field     string     `gorm:"type:varchar(32);comment:变更的字段"`
+	OldValue  string     `gorm:"type:varchar(512);comment:变更前的值"`
+	NewValue  string     `gorm:"type:varchar(512);comment:变更后的值"`
+	Remark    string     `gorm:"type:varchar(512);comment:说明，如转交原因"`
+	CreatedAt time.Time
+
+	// Relations
+	User      User       `gorm:"foreignKey:UserID"`
+}
+
 // UserTodo 用户-待办关联表 (多对多)
 type UserTodo struct {
 	TodoID     uint `gorm:"primaryKey"`
@@ -0,0 +1,184 @@
+package model
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ApprovalWorkflow 审批流程模板，由管理员按审批类型配置
+// 提交审批时按优先级从高到低匹配第一个满足全部条件的模板，没有匹配的模板时按部门层级逐级审批
+type ApprovalWorkflow struct {
+	gorm.Model
+	Name              string              `gorm:"type:varchar(64);not null;comment:模板名称"`
+	Type              ApprovalType        `gorm:"index;comment:审批类型"`
+	FormID            uint                `gorm:"index;default:0;comment:自定义表单ID，0 表示适用于该类型的全部审批单"`
+	Priority          int                 `gorm:"default:0;comment:优先级，数值越大越先匹配"`
+	Enabled           bool                `gorm:"comment:是否启用"`
+	RequireAttachment bool                `gorm:"default:false;comment:是否必须上传附件"`
+	Desc              string              `gorm:"type:varchar(255);comment:说明"`
+	Conditions        []WorkflowCondition `gorm:"serializer:json;comment:匹配条件"`
+	Steps             []WorkflowStep      `gorm:"serializer:json;comment:审批步骤"`
+	CopyUserIds       []uint              `gorm:"serializer:json;comment:抄送人"`
+	CopyRoles         []string            `gorm:"serializer:json;comment:抄送角色"`
+}
+
+// WorkflowCondition 模板匹配条件，多个条件同时满足时模板生效
+type WorkflowCondition struct {
+	Field  string    `json:"field"`            // 条件字段，见 Field 开头的常量
+	Op     string    `json:"op"`               // 比较方式，见 Condition 开头的常量
+	Value  float64   `json:"value,omitempty"`  // 比较值
+	Values []float64 `json:"values,omitempty"` // in 比较的取值列表
+}
+
+// WorkflowStep 审批步骤
+type WorkflowStep struct {
+	Name    string   `json:"name,omitempty"`
+	Mode    StepMode `json:"mode"`              // 审批方式
+	UserIds []uint   `json:"userIds,omitempty"` // 指定审批人
+	Roles   []string `json:"roles,omitempty"`   // 审批角色，提交时解析为具体审批人
+}
+
+// StepMode 审批步骤的审批方式
+type StepMode string
+
+const (
+	SequentialStep StepMode = "sequential" // 依次审批：步骤内的审批人按顺序逐个审批
+	AllOfStep      StepMode = "and"        // 会签：步骤内的审批人需全部通过
+	AnyOfStep      StepMode = "or"         // 或签：步骤内任一审批人通过即可
+)
+
+// 模板条件支持的字段
+const (
+	FieldLeaveType            = "leave.type"                 // 请假类型
+	FieldLeaveDuration        = "leave.duration"             // 请假时长
+	FieldLeaveTimeType        = "leave.timeType"             // 请假时长单位 1=小时 2=天
+	FieldGoOutDuration        = "goOut.duration"             // 外出时长（小时）
+	FieldMakeCardCheckType    = "makeCard.checkType"         // 补卡类型
+	FieldOvertimeDuration     = "overtime.duration"          // 加班时长（小时）
+	FieldOvertimeCompensation = "overtime.compensation"      // 加班补偿方式 1=加班费 2=调休
+	FieldTripDuration         = "businessTrip.duration"      // 出差天数
+	FieldTripCost             = "businessTrip.estimatedCost" // 出差预估费用（元）
+	FieldFormPrefix           = "form."                      // 自定义表单字段，如 form.amount，只支持数字和是否字段
+)
+
+// 模板条件支持的比较方式
+const (
+	ConditionEq  = "eq"
+	ConditionNe  = "ne"
+	ConditionGt  = "gt"
+	ConditionGte = "gte"
+	ConditionLt  = "lt"
+	ConditionLte = "lte"
+	ConditionIn  = "in"
+)
+
+// 内置审批角色，提交时根据申请人所在部门解析
+const (
+	RoleLeader   = "leader"   // 申请人直属部门负责人
+	RoleSuperior = "superior" // 申请人上级部门负责人
+)
+
+// UserRole 用户角色，用于审批模板按角色指定审批人，如 hr、finance
+type UserRole struct {
+	gorm.Model
+	Role   string `gorm:"type:varchar(32);uniqueIndex:idx_role_user;comment:角色名称"`
+	UserID uint   `gorm:"uniqueIndex:idx_role_user;comment:用户ID"`
+}
+
+// FieldValue 读取审批单上的条件字段，字段不存在时 ok 为 false
+func (a *Approval) FieldValue(field string) (v float64, ok bool) {
+	switch field {
+	case FieldLeaveType:
+		if a.Leave != nil {
+			return float64(a.Leave.Type), true
+		}
+	case FieldLeaveDuration:
+		if a.Leave != nil {
+			return float64(a.Leave.Duration), true
+		}
+	case FieldLeaveTimeType:
+		if a.Leave != nil {
+			return float64(a.Leave.TimeType), true
+		}
+	case FieldGoOutDuration:
+		if a.GoOut != nil {
+			return float64(a.GoOut.Duration), true
+		}
+	case FieldMakeCardCheckType:
+		if a.MakeCard != nil {
+			return float64(a.MakeCard.CheckType), true
+		}
+	case FieldOvertimeDuration:
+		if a.Overtime != nil {
+			return float64(a.Overtime.Duration), true
+		}
+	case FieldOvertimeCompensation:
+		if a.Overtime != nil {
+			return float64(a.Overtime.Compensation), true
+		}
+	case FieldTripDuration:
+		if a.BusinessTrip != nil {
+			return float64(a.BusinessTrip.Duration), true
+		}
+	case FieldTripCost:
+		if a.BusinessTrip != nil {
+			return a.BusinessTrip.EstimatedCost, true
+		}
+	default:
+		if key, ok := strings.CutPrefix(field, FieldFormPrefix); ok && a.Form != nil {
+			switch v := a.Form.Values[key].(type) {
+			case float64:
+				return v, true
+			case bool:
+				if v {
+					return 1, true
+				}
+				return 0, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// Match 判断审批单是否满足条件
+func (c WorkflowCondition) Match(a *Approval) bool {
+	v, ok := a.FieldValue(c.Field)
+	if !ok {
+		return false
+	}
+	switch c.Op {
+	case ConditionEq:
+		return v == c.Value
+	case ConditionNe:
+		return v != c.Value
+	case ConditionGt:
+		return v > c.Value
+	case ConditionGte:
+		return v >= c.Value
+	case ConditionLt:
+		return v < c.Value
+	case ConditionLte:
+		return v <= c.Value
+	case ConditionIn:
+		for _, x := range c.Values {
+			if v == x {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Match 判断模板是否适用于审批单
+func (w *ApprovalWorkflow) Match(a *Approval) bool {
+	if !w.Enabled || w.Type != a.Type {
+		return false
+	}
+	for _, c := range w.Conditions {
+		if !c.Match(a) {
+			return false
+		}
+	}
+	return true
+}
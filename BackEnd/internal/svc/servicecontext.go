@@ -4,23 +4,36 @@ import (
 	"BackEnd/internal/config"
 	"BackEnd/internal/middleware"
 	"BackEnd/internal/model"
+	"BackEnd/pkg/search"
 	"fmt"
+	"time"
 
 	// "log"
 
 	"github.com/tmc/langchaingo/callbacks"
+	"github.com/tmc/langchaingo/embeddings"
+	"github.com/tmc/langchaingo/llms"
 
 	"github.com/tmc/langchaingo/llms/openai"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 )
 
+// LLM 大模型客户端，需同时支持对话生成与文本向量化
+// 线上使用 openai 兼容接口，评测等场景可替换为本地或假模型
+type LLM interface {
+	llms.Model
+	embeddings.EmbedderClient
+}
+
 type ServiceContext struct {
 	Config    config.Config
 	DB        *gorm.DB
 	Jwt       *middleware.Jwt
 	Callbacks callbacks.Handler
-	LLMs      *openai.LLM
+	LLMs      LLM
+
+	KnowledgeIndex *search.Index // 知识库关键词索引，与向量索引一起用于混合检索
 }
 
 func NewServiceContext(c config.Config) *ServiceContext {
@@ -53,14 +66,36 @@ func NewServiceContext(c config.Config) *ServiceContext {
 		&model.UserTodo{},
 		&model.Approval{},
 		&model.Approver{},
-		&model.ChatLog{},      // 聊天记录表
-		&model.GroupMember{},  // 群聊成员表
-		&model.Conversation{}, // 会话表
-		&model.Participant{},  // 参与者表
+		&model.ChatLog{},            // 聊天记录表
+		&model.GroupMember{},        // 群聊成员表
+		&model.Conversation{},       // 会话表
+		&model.Participant{},        // 参与者表
+		&model.KnowledgeDocument{},  // 知识库文档表
+		&model.KnowledgeChunk{},     // 知识库文档块表
+		&model.ApprovalWorkflow{},   // 审批流程模板表
+		&model.UserRole{},           // 用户角色表
+		&model.ApprovalCopy{},       // 审批抄送人表
+		&model.Notification{},       // 站内通知表
+		&model.ApprovalDelegation{}, // 审批委托表
+		&model.LeaveBalance{},       // 假期余额表
+		&model.LeaveLedger{},        // 假期余额流水表
+		&model.CalendarDay{},        // 工作日历表
+		&model.ApprovalLog{},        // 审批记录表
+		&model.Sequence{},           // 流水号计数表
+		&model.ApprovalForm{},       // 自定义审批表单表
+		&model.TodoLabel{},          // 待办标签表
+		&model.TodoHistory{},        // 待办变更记录表
+		&model.TodoDigest{},         // 每日待办汇总发送记录表
 	); err != nil {
 		panic(err)
 	}
 
+	// 从数据库加载知识库关键词索引
+	knowledgeIndex, err := loadKnowledgeIndex(db)
+	if err != nil {
+		panic(err)
+	}
+
 	// 初始化 LLM
 	if c.AI.BaseURL == "" {
 		c.AI.BaseURL = "https://api.openai.com/v1"
@@ -85,7 +120,23 @@ func NewServiceContext(c config.Config) *ServiceContext {
 		DB:     db,
 		Jwt:    middleware.NewJwt(c.Auth.Secret),
 		LLMs:   llm,
+
+		KnowledgeIndex: knowledgeIndex,
+	}
+}
+
+// loadKnowledgeIndex 启动时将已入库的知识库文档块加载到关键词索引，之后由后台任务定期与数据库同步
+func loadKnowledgeIndex(db *gorm.DB) (*search.Index, error) {
+	idx := search.NewIndex()
+
+	var chunks []model.KnowledgeChunk
+	if err := db.Select("id", "content").Find(&chunks).Error; err != nil {
+		return nil, err
 	}
+	for _, c := range chunks {
+		idx.Add(fmt.Sprintf("%d", c.ID), c.Content)
+	}
+	return idx, nil
 }
 
 // GetBaseURL returns the base URL for internal API calls, handling the 0.0.0.0 case
@@ -96,3 +147,15 @@ func (s *ServiceContext) GetBaseURL() string {
 	}
 	return fmt.Sprintf("http://%s:%d", host, s.Config.Port)
 }
+
+// Location 返回用户默认时区，未配置或配置无效时使用服务器时区
+func (s *ServiceContext) Location() *time.Location {
+	if s.Config.TimeZone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(s.Config.TimeZone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
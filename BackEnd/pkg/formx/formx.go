@@ -0,0 +1,297 @@
+// Package formx 自定义表单：校验表单定义和提交的数据，按模板生成摘要
+package formx
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// FieldType 表单字段类型
+type FieldType string
+
+const (
+	Text        FieldType = "text"        // 单行文本
+	Textarea    FieldType = "textarea"    // 多行文本
+	Number      FieldType = "number"      // 数字
+	Date        FieldType = "date"        // 日期，值为 Unix 时间戳（秒）
+	DateTime    FieldType = "datetime"    // 日期时间，值为 Unix 时间戳（秒）
+	Select      FieldType = "select"      // 单选，值为选项之一
+	MultiSelect FieldType = "multiselect" // 多选，值为选项的列表
+	Bool        FieldType = "bool"        // 是否
+)
+
+// 文本字段默认的最大长度
+const (
+	defaultTextLength     = 128
+	defaultTextareaLength = 1000
+)
+
+var keyPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{0,31}$`)
+
+// Field 表单字段定义
+type Field struct {
+	Key       string    `json:"key"`
+	Label     string    `json:"label"`
+	Type      FieldType `json:"type"`
+	Required  bool      `json:"required,omitempty"`
+	Options   []string  `json:"options,omitempty"`   // 单选、多选的选项
+	Min       *float64  `json:"min,omitempty"`       // 数字的最小值
+	Max       *float64  `json:"max,omitempty"`       // 数字的最大值
+	MaxLength int       `json:"maxLength,omitempty"` // 文本的最大字符数，0 时使用默认值
+}
+
+// CheckFields 校验表单定义：字段名唯一且由字母、数字和下划线组成，类型有效，选项类字段必须有选项
+func CheckFields(fields []Field) error {
+	if len(fields) == 0 {
+		return errors.New("form needs at least one field")
+	}
+	seen := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		if !keyPattern.MatchString(f.Key) {
+			return fmt.Errorf("invalid field key %q, use letters, digits and underscores", f.Key)
+		}
+		if seen[f.Key] {
+			return fmt.Errorf("duplicate field key %q", f.Key)
+		}
+		seen[f.Key] = true
+		if strings.TrimSpace(f.Label) == "" {
+			return fmt.Errorf("field %s needs a label", f.Key)
+		}
+		switch f.Type {
+		case Text, Textarea, Number, Date, DateTime, Bool:
+		case Select, MultiSelect:
+			if len(f.Options) == 0 {
+				return fmt.Errorf("field %s needs options", f.Key)
+			}
+		default:
+			return fmt.Errorf("field %s has unknown type %q", f.Key, f.Type)
+		}
+		if f.Min != nil && f.Max != nil && *f.Min > *f.Max {
+			return fmt.Errorf("field %s: min must not be greater than max", f.Key)
+		}
+	}
+	return nil
+}
+
+// Validate 按表单定义校验提交的数据，返回规范化后的数据：数字为 float64，日期为 int64，多选为 []string
+// 未定义的字段和空值会被丢弃
+func Validate(fields []Field, values map[string]any) (map[string]any, error) {
+	known := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		known[f.Key] = true
+	}
+	for k := range values {
+		if !known[k] {
+			return nil, fmt.Errorf("unknown field %q", k)
+		}
+	}
+
+	res := make(map[string]any, len(fields))
+	for _, f := range fields {
+		v, ok := values[f.Key]
+		if ok && !isEmpty(v) {
+			nv, err := normalize(f, v)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", f.Label, err)
+			}
+			res[f.Key] = nv
+			continue
+		}
+		if f.Required {
+			return nil, fmt.Errorf("%s is required", f.Label)
+		}
+	}
+	return res, nil
+}
+
+func isEmpty(v any) bool {
+	switch x := v.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(x) == ""
+	case []any:
+		return len(x) == 0
+	case []string:
+		return len(x) == 0
+	}
+	return false
+}
+
+func normalize(f Field, v any) (any, error) {
+	switch f.Type {
+	case Text, Textarea:
+		s, ok := v.(string)
+		if !ok {
+			return nil, errors.New("must be text")
+		}
+		s = strings.TrimSpace(s)
+		limit := f.MaxLength
+		if limit <= 0 {
+			limit = defaultTextLength
+			if f.Type == Textarea {
+				limit = defaultTextareaLength
+			}
+		}
+		if utf8.RuneCountInString(s) > limit {
+			return nil, fmt.Errorf("must be at most %d characters", limit)
+		}
+		return s, nil
+	case Number:
+		n, err := toFloat(v)
+		if err != nil {
+			return nil, err
+		}
+		if f.Min != nil && n < *f.Min {
+			return nil, fmt.Errorf("must be at least %v", *f.Min)
+		}
+		if f.Max != nil && n > *f.Max {
+			return nil, fmt.Errorf("must be at most %v", *f.Max)
+		}
+		return n, nil
+	case Date, DateTime:
+		n, err := toFloat(v)
+		if err != nil || n <= 0 || n != math.Trunc(n) {
+			return nil, errors.New("must be a unix timestamp in seconds")
+		}
+		return int64(n), nil
+	case Select:
+		s, ok := v.(string)
+		if !ok || !slices.Contains(f.Options, s) {
+			return nil, fmt.Errorf("must be one of %s", strings.Join(f.Options, ", "))
+		}
+		return s, nil
+	case MultiSelect:
+		var items []string
+		switch x := v.(type) {
+		case []string:
+			items = x
+		case []any:
+			for _, e := range x {
+				s, ok := e.(string)
+				if !ok {
+					return nil, errors.New("must be a list of options")
+				}
+				items = append(items, s)
+			}
+		default:
+			return nil, errors.New("must be a list of options")
+		}
+		res := make([]string, 0, len(items))
+		for _, s := range items {
+			if !slices.Contains(f.Options, s) {
+				return nil, fmt.Errorf("%q is not one of %s", s, strings.Join(f.Options, ", "))
+			}
+			if !slices.Contains(res, s) {
+				res = append(res, s)
+			}
+		}
+		return res, nil
+	case Bool:
+		b, ok := v.(bool)
+		if !ok {
+			return nil, errors.New("must be true or false")
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("unknown type %q", f.Type)
+}
+
+// toFloat 将数字或数字字符串转换为 float64，NaN 和无穷大无法比较大小也无法序列化为 JSON，视为无效
+func toFloat(v any) (float64, error) {
+	var n float64
+	switch x := v.(type) {
+	case float64:
+		n = x
+	case float32:
+		n = float64(x)
+	case int:
+		n = float64(x)
+	case int64:
+		n = float64(x)
+	case string:
+		var err error
+		if n, err = strconv.ParseFloat(strings.TrimSpace(x), 64); err != nil {
+			return 0, errors.New("must be a number")
+		}
+	default:
+		return 0, errors.New("must be a number")
+	}
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, errors.New("must be a finite number")
+	}
+	return n, nil
+}
+
+// Display 返回字段值用于展示的文本，日期按 loc 时区格式化
+func Display(f Field, v any, loc *time.Location) string {
+	if v == nil {
+		return ""
+	}
+	switch f.Type {
+	case Date, DateTime:
+		n, err := toFloat(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		layout := "2006-01-02 15:04"
+		if f.Type == Date {
+			layout = time.DateOnly
+		}
+		return time.Unix(int64(n), 0).In(loc).Format(layout)
+	case Number:
+		if n, err := toFloat(v); err == nil {
+			return strconv.FormatFloat(n, 'f', -1, 64)
+		}
+	case Bool:
+		if b, ok := v.(bool); ok {
+			if b {
+				return "是"
+			}
+			return "否"
+		}
+	case MultiSelect:
+		switch x := v.(type) {
+		case []string:
+			return strings.Join(x, "、")
+		case []any:
+			items := make([]string, 0, len(x))
+			for _, e := range x {
+				items = append(items, fmt.Sprint(e))
+			}
+			return strings.Join(items, "、")
+		}
+	}
+	return fmt.Sprint(v)
+}
+
+// Render 按模板生成摘要，模板中的 {key} 替换为字段的展示值
+// 模板为空时依次列出前三个有值的字段，格式为 【标签：值】
+func Render(tmpl string, fields []Field, values map[string]any, loc *time.Location) string {
+	if tmpl == "" {
+		var b strings.Builder
+		n := 0
+		for _, f := range fields {
+			v, ok := values[f.Key]
+			if !ok || n == 3 {
+				continue
+			}
+			fmt.Fprintf(&b, "【%s：%s】", f.Label, Display(f, v, loc))
+			n++
+		}
+		return b.String()
+	}
+
+	pairs := make([]string, 0, len(fields)*2)
+	for _, f := range fields {
+		pairs = append(pairs, "{"+f.Key+"}", Display(f, values[f.Key], loc))
+	}
+	return strings.NewReplacer(pairs...).Replace(tmpl)
+}
@@ -0,0 +1,92 @@
+package formx
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func ptr(v float64) *float64 { return &v }
+
+// 采购申请表单
+var purchaseFields = []Field{
+	{Key: "item", Label: "物品", Type: Text, Required: true},
+	{Key: "quantity", Label: "数量", Type: Number, Required: true, Min: ptr(1), Max: ptr(100)},
+	{Key: "category", Label: "类别", Type: Select, Options: []string{"办公用品", "电子设备"}},
+	{Key: "tags", Label: "标签", Type: MultiSelect, Options: []string{"紧急", "长期"}},
+	{Key: "deliverAt", Label: "交付日期", Type: Date},
+	{Key: "invoice", Label: "需要发票", Type: Bool},
+}
+
+// Test_CheckFields 测试表单定义的校验
+func Test_CheckFields(t *testing.T) {
+	if err := CheckFields(purchaseFields); err != nil {
+		t.Fatalf("CheckFields() error: %v", err)
+	}
+	bad := [][]Field{
+		nil,
+		{{Key: "1item", Label: "物品", Type: Text}},
+		{{Key: "item", Label: "物品", Type: Text}, {Key: "item", Label: "物品", Type: Text}},
+		{{Key: "item", Label: "", Type: Text}},
+		{{Key: "item", Label: "物品", Type: "file"}},
+		{{Key: "category", Label: "类别", Type: Select}},
+		{{Key: "amount", Label: "金额", Type: Number, Min: ptr(10), Max: ptr(1)}},
+	}
+	for i, fields := range bad {
+		if err := CheckFields(fields); err == nil {
+			t.Errorf("case %d: CheckFields() expected error", i)
+		}
+	}
+}
+
+// Test_Validate 测试提交数据的校验和规范化
+func Test_Validate(t *testing.T) {
+	got, err := Validate(purchaseFields, map[string]any{
+		"item":      " 显示器 ",
+		"quantity":  "2",
+		"category":  "电子设备",
+		"tags":      []any{"紧急", "紧急"},
+		"deliverAt": float64(1792108800),
+		"invoice":   true,
+	})
+	if err != nil {
+		t.Fatalf("Validate() error: %v", err)
+	}
+	if got["item"] != "显示器" || got["quantity"] != float64(2) || got["deliverAt"] != int64(1792108800) {
+		t.Errorf("Validate() = %v", got)
+	}
+	if tags := got["tags"].([]string); len(tags) != 1 {
+		t.Errorf("tags = %v, want deduplicated", tags)
+	}
+
+	bad := []map[string]any{
+		{"quantity": 1},                                // 缺少必填字段
+		{"item": "笔", "quantity": 0},                   // 小于最小值
+		{"item": "笔", "quantity": 1, "category": "食品"}, // 不在选项中
+		{"item": "笔", "quantity": 1, "color": "红"},     // 未定义的字段
+		{"item": "笔", "quantity": 1, "invoice": "是"},   // 类型错误
+		{"item": "笔", "quantity": "NaN"},               // 非数字
+		{"item": "笔", "quantity": "Inf"},               // 无穷大
+		{"item": "笔", "quantity": "-Infinity"},         // 无穷小
+		{"item": "笔", "quantity": math.NaN()},          // 非数字
+		{"item": "笔", "quantity": math.Inf(1)},         // 无穷大
+	}
+	for i, values := range bad {
+		if _, err := Validate(purchaseFields, values); err == nil {
+			t.Errorf("case %d: Validate(%v) expected error", i, values)
+		}
+	}
+}
+
+// Test_Render 测试按模板生成摘要
+func Test_Render(t *testing.T) {
+	loc := time.FixedZone("CST", 8*3600)
+	values := map[string]any{"item": "显示器", "quantity": float64(2), "deliverAt": int64(1792108800), "invoice": true}
+
+	if got, want := Render("{item} x {quantity}，{deliverAt} 前交付", purchaseFields, values, loc), "显示器 x 2，2026-10-16 前交付"; got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+	if got, want := Render("", purchaseFields, values, loc), "【物品：显示器】【数量：2】【交付日期：2026-10-16】"; got != want {
+		t.Errorf("Render() default = %q, want %q", got, want)
+	}
+}
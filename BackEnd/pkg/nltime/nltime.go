@@ -0,0 +1,326 @@
+// Package nltime 自然语言时间解析
+//
+// 支持中英文的相对日期（明天、下周五、next Friday）、明确日期（3月15日、2026-11-02）、
+// 相对偏移（三天后、in 2 hours）、月底年底、时间段（本周、下个月、Q3、节前）以及区间（下午2点到4点），
+// 解析结果为时刻或区间，并给出置信度。参考时间的时区即用户时区。
+package nltime
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrNoTime 表达式中没有可识别的时间
+var ErrNoTime = errors.New("no time expression found")
+
+// ErrInvalidDate 表达式中的日期不存在
+var ErrInvalidDate = errors.New("invalid date")
+
+// Grain 解析结果的粒度
+type Grain int
+
+const (
+	GrainMinute  Grain = iota // 精确时刻
+	GrainDay                  // 某一天或若干天
+	GrainWeek                 // 某一周
+	GrainMonth                // 某个月
+	GrainQuarter              // 某个季度
+	GrainYear                 // 某一年
+)
+
+// String 返回粒度名称
+func (g Grain) String() string {
+	switch g {
+	case GrainMinute:
+		return "minute"
+	case GrainDay:
+		return "day"
+	case GrainWeek:
+		return "week"
+	case GrainMonth:
+		return "month"
+	case GrainQuarter:
+		return "quarter"
+	case GrainYear:
+		return "year"
+	}
+	return "unknown"
+}
+
+// Result 解析结果
+// 区间为左闭右开 [Start, End)；精确时刻的 End 与 Start 相同
+type Result struct {
+	Start      time.Time
+	End        time.Time
+	Grain      Grain
+	Confidence float64 // 置信度 0-1，推断越多、无法识别的内容越多则越低
+}
+
+// IsRange 是否为区间（包括“明天”这类整天的表达式）
+func (r *Result) IsRange() bool {
+	return r.End.After(r.Start)
+}
+
+// Last 区间内的最后一秒，精确时刻返回其本身，常用于把“明天”解释为截止时间
+func (r *Result) Last() time.Time {
+	if !r.IsRange() {
+		return r.Start
+	}
+	return r.End.Add(-time.Second)
+}
+
+// Parser 自然语言时间解析器
+type Parser struct {
+	Now time.Time // 参考时间，其时区即用户时区
+	// PreferFuture 未写明年份、月份或日期时优先解析为将来的时间，
+	// 适用于截止时间、提醒等场景；查询历史数据时应关闭
+	PreferFuture bool
+	Holidays     []Holiday // 节假日安排，用于解析“节前”“国庆”等表达式
+}
+
+// NewParser 创建解析器，默认优先解析为将来的时间
+func NewParser(now time.Time) *Parser {
+	return &Parser{Now: now, PreferFuture: true}
+}
+
+// Parse 以 now 为参考时间解析表达式
+func Parse(expr string, now time.Time) (*Result, error) {
+	return NewParser(now).Parse(expr)
+}
+
+var (
+	// 区间分隔符
+	rangeSepRe = regexp.MustCompile(`\s*(?:到|至|~|～|\s[-–—]\s|\bto\b|\buntil\b|\btill\b|\bthrough\b|\bthru\b|\band\b)\s*`)
+	// 区间前缀
+	rangePrefixRe = regexp.MustCompile(`^(?:从|自|由|from|between)\s*`)
+	// 不影响语义的虚词，计算置信度时忽略
+	fillerRe = regexp.MustCompile(`的|在|于|之前|以前|之后|以后|前|后|截止|截至|为止|最晚|最迟|左右|大概|大约|钟|开始|起|\b(?:by|on|at|the|of|before|after|around|about|due|from|starting)\b|[\s,.;!?]`)
+)
+
+// Parse 解析表达式，返回时刻或区间
+func (p *Parser) Parse(expr string) (*Result, error) {
+	text := normalize(expr)
+	if text == "" {
+		return nil, ErrNoTime
+	}
+
+	if res, ok := p.parseRange(text); ok {
+		return res, nil
+	}
+
+	s, err := p.parseSide(text, nil)
+	if err != nil {
+		return nil, err
+	}
+	return p.resolve(s, nil)
+}
+
+// parseRange 尝试按区间解析，两侧都能解析时才视为区间
+func (p *Parser) parseRange(text string) (*Result, bool) {
+	loc := rangeSepRe.FindStringIndex(text)
+	if loc == nil {
+		return nil, false
+	}
+	left := rangePrefixRe.ReplaceAllString(strings.TrimSpace(text[:loc[0]]), "")
+	right := strings.TrimSpace(text[loc[1]:])
+	if left == "" || right == "" {
+		return nil, false
+	}
+
+	ls, err := p.parseSide(left, nil)
+	if err != nil {
+		return nil, false
+	}
+	start, err := p.resolve(ls, nil)
+	if err != nil {
+		return nil, false
+	}
+	rs, err := p.parseSide(right, ls)
+	if err != nil {
+		return nil, false
+	}
+	end, err := p.resolve(rs, ls)
+	if err != nil {
+		return nil, false
+	}
+
+	res := &Result{
+		Start:      start.Start,
+		End:        end.End,
+		Grain:      start.Grain,
+		Confidence: min(start.Confidence, end.Confidence),
+	}
+	if end.Grain > res.Grain {
+		res.Grain = end.Grain
+	}
+	if !res.End.After(res.Start) {
+		return nil, false
+	}
+	return res, true
+}
+
+// state 单侧表达式的解析状态
+type state struct {
+	anchor time.Time // 推断年份、月份、星期时的参考时间，区间右侧以左侧开始时间为准
+
+	// 日期部分
+	hasDate    bool
+	start, end time.Time // 日期范围，零点对齐
+	grain      Grain
+	dayOnly    int // 只写了“几号”，年月在解析时推断
+
+	// 时刻部分
+	hasClock     bool
+	hour, minute int
+	defaultClock bool // 只写了“下午”等时段，时刻为默认值
+	part         dayPart
+
+	// 由小时、分钟偏移得到的精确时刻
+	hasInstant bool
+	instant    time.Time
+
+	invalid  bool // 明确写出的日期不合法
+	conf     float64
+	consumed int // 已识别的字符数
+}
+
+// parseSide 解析区间的一侧；base 为区间左侧的解析状态
+func (p *Parser) parseSide(text string, base *state) (*state, error) {
+	s := &state{anchor: p.Now, conf: 1}
+	if base != nil {
+		if r, err := p.resolve(base, nil); err == nil {
+			s.anchor = r.Start
+		}
+	}
+
+	text = p.apply(text, dateRules, s)
+	text = p.apply(text, dayPartRules, s)
+	text = p.apply(text, clockRules, s)
+
+	if s.consumed == 0 {
+		return nil, ErrNoTime
+	}
+	if s.invalid {
+		return nil, ErrInvalidDate
+	}
+
+	// 无法识别的内容越多，置信度越低
+	if leftover := utf8.RuneCountInString(fillerRe.ReplaceAllString(text, "")); leftover > 0 {
+		s.conf *= 0.5 + 0.5*float64(s.consumed)/float64(s.consumed+leftover)
+	}
+	return s, nil
+}
+
+// apply 依次尝试规则，每组规则最多命中一条，命中的文本从表达式中移除
+func (p *Parser) apply(text string, rules []rule, s *state) string {
+	for _, r := range rules {
+		idx := r.re.FindStringSubmatchIndex(text)
+		if idx == nil {
+			continue
+		}
+		m := make([]string, len(idx)/2)
+		for i := range m {
+			if idx[2*i] >= 0 {
+				m[i] = text[idx[2*i]:idx[2*i+1]]
+			}
+		}
+		if !r.apply(p, s, m, text[idx[1]:]) {
+			continue
+		}
+		s.consumed += utf8.RuneCountInString(m[0])
+		return text[:idx[0]] + " " + text[idx[1]:]
+	}
+	return text
+}
+
+// resolve 将解析状态转换为结果；base 为区间左侧的解析状态
+func (p *Parser) resolve(s *state, base *state) (*Result, error) {
+	if s.hasInstant {
+		conf := s.conf
+		if s.hasClock || s.hasDate {
+			conf *= 0.7
+		}
+		return &Result{Start: s.instant, End: s.instant, Grain: GrainMinute, Confidence: conf}, nil
+	}
+	if !s.hasDate && !s.hasClock {
+		return nil, ErrNoTime
+	}
+
+	conf := s.conf
+	start, end, grain := s.start, s.end, s.grain
+	clockOnly := false
+	switch {
+	case s.dayOnly > 0:
+		start = p.inferDay(s.anchor, s.dayOnly, base != nil)
+		if start.IsZero() {
+			return nil, fmt.Errorf("invalid day: %d", s.dayOnly)
+		}
+		end, grain = start.AddDate(0, 0, 1), GrainDay
+	case !s.hasDate:
+		start, grain = dayOf(s.anchor), GrainDay
+		end = start.AddDate(0, 0, 1)
+		clockOnly = true
+	}
+
+	if !s.hasClock {
+		return &Result{Start: start, End: end, Grain: grain, Confidence: conf}, nil
+	}
+
+	// 区间右侧没有写时段时沿用左侧的时段，如“下午2点到4点”
+	kind := s.part.kind
+	if kind == partNone && base != nil {
+		kind = base.part.kind
+	}
+	hour := adjustHour(s.hour, kind)
+	if s.defaultClock {
+		conf *= 0.9
+	}
+	if grain > GrainDay {
+		// “下周 3点”这类粒度不匹配的组合只能取范围的第一天
+		conf *= 0.6
+	}
+
+	t := time.Date(start.Year(), start.Month(), start.Day(), hour, s.minute, 0, 0, start.Location())
+	if clockOnly && t.Before(s.anchor) && (p.PreferFuture || base != nil) {
+		// 只写了时刻且已经过去，视为次日；区间右侧早于左侧时视为跨天
+		t = t.AddDate(0, 0, 1)
+		if base == nil {
+			conf *= 0.9
+		}
+	}
+	return &Result{Start: t, End: t, Grain: GrainMinute, Confidence: conf}, nil
+}
+
+// inferDay 只写了“几号”时推断年月：取参考时间所在月，已过去时顺延到下个月
+func (p *Parser) inferDay(anchor time.Time, day int, inRange bool) time.Time {
+	if day < 1 || day > 31 {
+		return time.Time{}
+	}
+	// 向将来推断时顺延到最近一个有该日期且不早于今天的月份，如 1 月 31 日说“30号”为 3 月 30 日
+	future := p.PreferFuture || inRange
+	for i := 0; i < 12; i++ {
+		t := time.Date(anchor.Year(), anchor.Month()+time.Month(i), day, 0, 0, 0, 0, anchor.Location())
+		if t.Day() == day && !(future && t.Before(dayOf(anchor))) {
+			return t
+		}
+		if !future {
+			break
+		}
+	}
+	return time.Time{}
+}
+
+// dayOf 返回当天零点
+func dayOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// weekStart 返回所在周的周一零点
+func weekStart(t time.Time) time.Time {
+	d := dayOf(t)
+	return d.AddDate(0, 0, -((int(d.Weekday()) + 6) % 7))
+}
@@ -0,0 +1,282 @@
+package nltime
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var (
+	cst = time.FixedZone("CST", 8*3600)
+	// 参考时间：2026-10-14 周三 10:30
+	refNow = time.Date(2026, 10, 14, 10, 30, 0, 0, cst)
+)
+
+func at(year int, month time.Month, day, hour, minute int) time.Time {
+	return time.Date(year, month, day, hour, minute, 0, 0, cst)
+}
+
+func day(year int, month time.Month, d int) time.Time {
+	return at(year, month, d, 0, 0)
+}
+
+// Test_Parse 测试各类中英文表达式的解析结果
+func Test_Parse(t *testing.T) {
+	tests := []struct {
+		expr  string
+		start time.Time
+		end   time.Time // 精确时刻时与 start 相同
+		grain Grain
+	}{
+		// 相对日期 + 时刻
+		{"明天下午3点", at(2026, 10, 15, 15, 0), at(2026, 10, 15, 15, 0), GrainMinute},
+		{"后天上午十点半", at(2026, 10, 16, 10, 30), at(2026, 10, 16, 10, 30), GrainMinute},
+		{"今晚", at(2026, 10, 14, 19, 0), at(2026, 10, 14, 19, 0), GrainMinute},
+		{"明天中午", at(2026, 10, 15, 12, 0), at(2026, 10, 15, 12, 0), GrainMinute},
+		{"中午1点", at(2026, 10, 14, 13, 0), at(2026, 10, 14, 13, 0), GrainMinute},
+		{"下午三点一刻", at(2026, 10, 14, 15, 15), at(2026, 10, 14, 15, 15), GrainMinute},
+		{"8点", at(2026, 10, 15, 8, 0), at(2026, 10, 15, 8, 0), GrainMinute},
+		{"tomorrow 9:30am", at(2026, 10, 15, 9, 30), at(2026, 10, 15, 9, 30), GrainMinute},
+		{"next Friday 3pm", at(2026, 10, 23, 15, 0), at(2026, 10, 23, 15, 0), GrainMinute},
+		{"tonight", at(2026, 10, 14, 19, 0), at(2026, 10, 14, 19, 0), GrainMinute},
+
+		// 整天
+		{"今天", day(2026, 10, 14), day(2026, 10, 15), GrainDay},
+		{"大后天", day(2026, 10, 17), day(2026, 10, 18), GrainDay},
+		{"下周五", day(2026, 10, 23), day(2026, 10, 24), GrainDay},
+		{"周五下午3点", at(2026, 10, 16, 15, 0), at(2026, 10, 16, 15, 0), GrainMinute},
+		{"周一", day(2026, 10, 19), day(2026, 10, 20), GrainDay},
+		{"这周一", day(2026, 10, 12), day(2026, 10, 13), GrainDay},
+		{"星期天", day(2026, 10, 18), day(2026, 10, 19), GrainDay},
+		{"下下周二", day(2026, 10, 27), day(2026, 10, 28), GrainDay},
+
+		// 明确日期
+		{"2026-11-02", day(2026, 11, 2), day(2026, 11, 3), GrainDay},
+		{"2026年11月2日 14:30", at(2026, 11, 2, 14, 30), at(2026, 11, 2, 14, 30), GrainMinute},
+		{"二〇二六年十二月二十五日", day(2026, 12, 25), day(2026, 12, 26), GrainDay},
+		{"3月15日", day(2027, 3, 15), day(2027, 3, 16), GrainDay},
+		{"12月1号", day(2026, 12, 1), day(2026, 12, 2), GrainDay},
+		{"March 15, 2027", day(2027, 3, 15), day(2027, 3, 16), GrainDay},
+		{"15th of November", day(2026, 11, 15), day(2026, 11, 16), GrainDay},
+		{"11/2", day(2026, 11, 2), day(2026, 11, 3), GrainDay},
+		{"15号", day(2026, 10, 15), day(2026, 10, 16), GrainDay},
+		{"1号", day(2026, 11, 1), day(2026, 11, 2), GrainDay},
+
+		// 相对偏移
+		{"三天后", day(2026, 10, 17), day(2026, 10, 18), GrainDay},
+		{"两周后", day(2026, 10, 28), day(2026, 10, 29), GrainDay},
+		{"一个月后", day(2026, 11, 14), day(2026, 11, 15), GrainDay},
+		{"in 2 hours", at(2026, 10, 14, 12, 30), at(2026, 10, 14, 12, 30), GrainMinute},
+		{"两个小时后", at(2026, 10, 14, 12, 30), at(2026, 10, 14, 12, 30), GrainMinute},
+		{"半小时后", at(2026, 10, 14, 11, 0), at(2026, 10, 14, 11, 0), GrainMinute},
+		{"10 minutes ago", at(2026, 10, 14, 10, 20), at(2026, 10, 14, 10, 20), GrainMinute},
+		{"3 days later", day(2026, 10, 17), day(2026, 10, 18), GrainDay},
+
+		// 月底、年底
+		{"月底", day(2026, 10, 31), day(2026, 11, 1), GrainDay},
+		{"下个月底", day(2026, 11, 30), day(2026, 12, 1), GrainDay},
+		{"下个月初", day(2026, 11, 1), day(2026, 11, 2), GrainDay},
+		{"年底", day(2026, 12, 31), day(2027, 1, 1), GrainDay},
+		{"end of next month", day(2026, 11, 30), day(2026, 12, 1), GrainDay},
+
+		// 时间段
+		{"本周", day(2026, 10, 12), day(2026, 10, 19), GrainWeek},
+		{"下个月", day(2026, 11, 1), day(2026, 12, 1), GrainMonth},
+		{"last month", day(2026, 9, 1), day(2026, 10, 1), GrainMonth},
+		{"明年", day(2027, 1, 1), day(2028, 1, 1), GrainYear},
+		{"this weekend", day(2026, 10, 17), day(2026, 10, 19), GrainDay},
+
+		// 区间
+		{"下午2点到4点", at(2026, 10, 14, 14, 0), at(2026, 10, 14, 16, 0), GrainMinute},
+		{"明天上午9点到下午5点", at(2026, 10, 15, 9, 0), at(2026, 10, 15, 17, 0), GrainMinute},
+		{"晚上10点到凌晨2点", at(2026, 10, 14, 22, 0), at(2026, 10, 15, 2, 0), GrainMinute},
+		{"9:00-11:30", at(2026, 10, 15, 9, 0), at(2026, 10, 15, 11, 30), GrainMinute},
+		{"3月15日到20日", day(2027, 3, 15), day(2027, 3, 21), GrainDay},
+		{"from monday to friday", day(2026, 10, 19), day(2026, 10, 24), GrainDay},
+		{"between 2pm and 4pm", at(2026, 10, 14, 14, 0), at(2026, 10, 14, 16, 0), GrainMinute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			res, err := Parse(tt.expr, refNow)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.expr, err)
+			}
+			if !res.Start.Equal(tt.start) || !res.End.Equal(tt.end) || res.Grain != tt.grain {
+				t.Fatalf("Parse(%q) = [%v, %v) %v, want [%v, %v) %v",
+					tt.expr, res.Start, res.End, res.Grain, tt.start, tt.end, tt.grain)
+			}
+			if res.Confidence <= 0 || res.Confidence > 1 {
+				t.Fatalf("Parse(%q) confidence = %v", tt.expr, res.Confidence)
+			}
+		})
+	}
+}
+
+// Test_Parse_Confidence 测试推断和无法识别的内容会降低置信度
+func Test_Parse_Confidence(t *testing.T) {
+	tests := []struct {
+		expr string
+		min  float64
+		max  float64
+	}{
+		{"明天下午3点", 1, 1},
+		{"2026-11-02", 1, 1},
+		{"8点", 0.8, 0.95},   // 已过去，顺延到次日
+		{"11/2", 0.6, 0.75}, // 月/日 与 日/月 有歧义
+		{"明天下午3点开会讨论预算", 0.5, 0.9},
+	}
+
+	for _, tt := range tests {
+		res, err := Parse(tt.expr, refNow)
+		if err != nil {
+			t.Fatalf("Parse(%q) error: %v", tt.expr, err)
+		}
+		if res.Confidence < tt.min || res.Confidence > tt.max {
+			t.Fatalf("Parse(%q) confidence = %v, want [%v, %v]", tt.expr, res.Confidence, tt.min, tt.max)
+		}
+	}
+}
+
+// Test_Parse_PreferPast 测试查询场景下不向将来推断
+func Test_Parse_PreferPast(t *testing.T) {
+	p := &Parser{Now: refNow}
+	tests := []struct {
+		expr  string
+		start time.Time
+	}{
+		{"周一", day(2026, 10, 12)},
+		{"3月15日", day(2026, 3, 15)},
+		{"8点", at(2026, 10, 14, 8, 0)},
+	}
+
+	for _, tt := range tests {
+		res, err := p.Parse(tt.expr)
+		if err != nil {
+			t.Fatalf("Parse(%q) error: %v", tt.expr, err)
+		}
+		if !res.Start.Equal(tt.start) {
+			t.Fatalf("Parse(%q) = %v, want %v", tt.expr, res.Start, tt.start)
+		}
+	}
+}
+
+// Test_Parse_MonthEnd 测试只说日期时顺延到有该日期的月份
+func Test_Parse_MonthEnd(t *testing.T) {
+	tests := []struct {
+		expr  string
+		now   time.Time
+		start time.Time
+	}{
+		{"30号", at(2026, 1, 31, 10, 0), day(2026, 3, 30)},
+		{"31号", at(2026, 1, 31, 10, 0), day(2026, 1, 31)},
+		{"31号", at(2026, 4, 10, 10, 0), day(2026, 5, 31)},
+		{"29号", at(2026, 1, 30, 10, 0), day(2026, 3, 29)},
+		{"15号", at(2026, 12, 20, 10, 0), day(2027, 1, 15)},
+	}
+
+	for _, tt := range tests {
+		res, err := Parse(tt.expr, tt.now)
+		if err != nil {
+			t.Fatalf("Parse(%q, %v) error: %v", tt.expr, tt.now, err)
+		}
+		if !res.Start.Equal(tt.start) || !res.End.Equal(tt.start.AddDate(0, 0, 1)) {
+			t.Fatalf("Parse(%q, %v) = [%v, %v), want %v", tt.expr, tt.now, res.Start, res.End, tt.start)
+		}
+	}
+}
+
+// Test_Parse_Invalid 测试无法识别的表达式
+func Test_Parse_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "随便什么时候", "hello world", "2月30日"} {
+		if res, err := Parse(expr, refNow); err == nil {
+			t.Fatalf("Parse(%q) = %v, want error", expr, res)
+		} else if !errors.Is(err, ErrNoTime) && !errors.Is(err, ErrInvalidDate) {
+			t.Fatalf("Parse(%q) error = %v, want ErrNoTime or ErrInvalidDate", expr, err)
+		}
+	}
+}
+
+// Test_ParseRange 测试查询场景的时间范围
+func Test_ParseRange(t *testing.T) {
+	p := NewParser(refNow)
+	p.Holidays = []Holiday{
+		{Name: "国庆节", Start: day(2026, 10, 1), End: day(2026, 10, 7)},
+		{Name: "元旦", Start: day(2027, 1, 1), End: day(2027, 1, 3)},
+		{Name: "春节", Start: day(2027, 2, 5), End: day(2027, 2, 12)},
+		{Name: "劳动节", Start: day(2027, 5, 1), End: day(2027, 5, 5)},
+	}
+
+	tests := []struct {
+		expr  string
+		start time.Time
+		end   time.Time
+		grain Grain
+	}{
+		{"本周", day(2026, 10, 12), day(2026, 10, 19), GrainWeek},
+		{"this week", day(2026, 10, 12), day(2026, 10, 19), GrainWeek},
+		{"上个月", day(2026, 9, 1), day(2026, 10, 1), GrainMonth},
+		{"Q3", day(2026, 7, 1), day(2026, 10, 1), GrainQuarter},
+		{"2025 Q4", day(2025, 10, 1), day(2026, 1, 1), GrainQuarter},
+		{"去年第三季度", day(2025, 7, 1), day(2025, 10, 1), GrainQuarter},
+		{"上季度", day(2026, 7, 1), day(2026, 10, 1), GrainQuarter},
+		{"next quarter", day(2027, 1, 1), day(2027, 4, 1), GrainQuarter},
+		{"上半年", day(2026, 1, 1), day(2026, 7, 1), GrainMonth},
+		{"最近7天", day(2026, 10, 8), day(2026, 10, 15), GrainDay},
+		{"近一个月", day(2026, 9, 15), day(2026, 10, 15), GrainDay},
+		{"past 2 weeks", day(2026, 10, 1), day(2026, 10, 15), GrainDay},
+		{"3天内", day(2026, 10, 14), day(2026, 10, 17), GrainDay},
+		{"节前", day(2026, 10, 14), day(2027, 1, 1), GrainDay},
+		{"before the holiday", day(2026, 10, 14), day(2027, 1, 1), GrainDay},
+		{"春节前", day(2026, 10, 14), day(2027, 2, 5), GrainDay},
+		{"五一前", day(2026, 10, 14), day(2027, 5, 1), GrainDay},
+		{"春节期间", day(2027, 2, 5), day(2027, 2, 13), GrainDay},
+		{"周一", day(2026, 10, 12), day(2026, 10, 13), GrainDay},
+		{"明天下午3点", day(2026, 10, 15), day(2026, 10, 16), GrainDay},
+		{"10月1日到10月10日", day(2026, 10, 1), day(2026, 10, 11), GrainDay},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			res, err := p.ParseRange(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseRange(%q) error: %v", tt.expr, err)
+			}
+			if !res.Start.Equal(tt.start) || !res.End.Equal(tt.end) || res.Grain != tt.grain {
+				t.Fatalf("ParseRange(%q) = [%v, %v) %v, want [%v, %v) %v",
+					tt.expr, res.Start, res.End, res.Grain, tt.start, tt.end, tt.grain)
+			}
+		})
+	}
+
+	// 没有配置节假日时无法解析“节前”
+	if _, err := NewParser(refNow).ParseRange("节前"); err == nil {
+		t.Fatalf("ParseRange(节前) without holidays should fail")
+	}
+
+	// 查询已经过去的节假日
+	past := NewParser(refNow)
+	past.Holidays = []Holiday{
+		{Name: "劳动节", Start: day(2026, 5, 1), End: day(2026, 5, 5)},
+		{Name: "国庆节", Start: day(2026, 10, 1), End: day(2026, 10, 7)},
+	}
+	pastTests := []struct {
+		expr  string
+		start time.Time
+		end   time.Time
+	}{
+		{"国庆期间", day(2026, 10, 1), day(2026, 10, 8)},
+		{"五一假期", day(2026, 5, 1), day(2026, 5, 6)},
+		{"节前", day(2026, 9, 24), day(2026, 10, 1)},
+		{"劳动节前", day(2026, 4, 24), day(2026, 5, 1)},
+	}
+	for _, tt := range pastTests {
+		res, err := past.ParseRange(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseRange(%q) error: %v", tt.expr, err)
+		}
+		if !res.Start.Equal(tt.start) || !res.End.Equal(tt.end) {
+			t.Fatalf("ParseRange(%q) = [%v, %v), want [%v, %v)", tt.expr, res.Start, res.End, tt.start, tt.end)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package nltime
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// 连续的中文数字
+	cnNumberRe = regexp.MustCompile(`[零〇一二两三四五六七八九十百]+`)
+	// 时刻之间的连字符视为区间，如 9:00-11:00、3pm-5pm、2点-4点
+	clockDashRe = regexp.MustCompile(`(:\d{2}|am|pm|点|时)\s*[-–—~～]\s*`)
+	spacesRe    = regexp.MustCompile(`\s+`)
+	// “五一”“十一”作为节日名称时不能转换为数字
+	laborDayRe    = regexp.MustCompile(`五一节?(假期|长假|黄金周|期间|前|之前|以前|$)`)
+	nationalDayRe = regexp.MustCompile(`十一(假期|长假|黄金周|期间|前|之前|以前|$)`)
+)
+
+var cnDigits = map[rune]int{
+	'零': 0, '〇': 0, '一': 1, '二': 2, '两': 2, '三': 3, '四': 4,
+	'五': 5, '六': 6, '七': 7, '八': 8, '九': 9,
+}
+
+// normalize 统一表达式的写法：全角转半角、转小写、中文数字转阿拉伯数字
+func normalize(expr string) string {
+	var sb strings.Builder
+	for _, r := range expr {
+		switch {
+		case r >= '０' && r <= '９':
+			r = r - '０' + '0'
+		case r == '：':
+			r = ':'
+		case r == '，' || r == '。' || r == '、':
+			r = ' '
+		case r == '　':
+			r = ' '
+		}
+		sb.WriteRune(r)
+	}
+
+	s := strings.ToLower(sb.String())
+	s = laborDayRe.ReplaceAllString(s, "劳动节$1")
+	s = nationalDayRe.ReplaceAllString(s, "国庆节$1")
+	s = cnNumberRe.ReplaceAllStringFunc(s, func(m string) string {
+		if n, ok := cnNumber(m); ok {
+			return strconv.Itoa(n)
+		}
+		return m
+	})
+	s = clockDashRe.ReplaceAllString(s, "${1}到")
+	return strings.TrimSpace(spacesRe.ReplaceAllString(s, " "))
+}
+
+// cnNumber 将中文数字转换为整数
+// 支持“二十三”“十五”“一百”这类计数写法，以及“二〇二六”这类逐位写法
+func cnNumber(s string) (int, bool) {
+	runes := []rune(s)
+	if !strings.ContainsAny(s, "十百") {
+		n := 0
+		for _, r := range runes {
+			d, ok := cnDigits[r]
+			if !ok {
+				return 0, false
+			}
+			n = n*10 + d
+		}
+		return n, true
+	}
+
+	total, cur := 0, 0
+	for i, r := range runes {
+		switch r {
+		case '百':
+			if cur == 0 {
+				cur = 1
+			}
+			total += cur * 100
+			cur = 0
+		case '十':
+			// “十五”省略了前面的“一”
+			if cur == 0 && (i == 0 || runes[i-1] != '零') {
+				cur = 1
+			}
+			total += cur * 10
+			cur = 0
+		default:
+			d, ok := cnDigits[r]
+			if !ok {
+				return 0, false
+			}
+			cur = d
+		}
+	}
+	return total + cur, true
+}
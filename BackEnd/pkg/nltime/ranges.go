@@ -0,0 +1,225 @@
+package nltime
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Holiday 法定节假日，Start、End 均为放假日期（含）
+type Holiday struct {
+	Name  string
+	Start time.Time
+	End   time.Time
+}
+
+// ParseRange 按查询场景解析时间范围：不向将来推断，精确时刻扩展为所在的一天
+// 适用于“本周”“上个月”“Q3”“节前”这类查询条件
+func (p *Parser) ParseRange(expr string) (*Result, error) {
+	q := *p
+	q.PreferFuture = false
+	res, err := q.Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	if !res.IsRange() {
+		start := dayOf(res.Start)
+		res = &Result{Start: start, End: start.AddDate(0, 0, 1), Grain: GrainDay, Confidence: res.Confidence * 0.8}
+	}
+	return res, nil
+}
+
+// rangeRules 查询场景常用的时间范围规则
+var rangeRules = []rule{
+	// Q3、2026 Q3、第三季度、今年三季度
+	{regexp.MustCompile(`(?:(\d{4})年?|(今|明|去)年)?\s*第?([1-4])季度`), applyCnQuarter},
+	{regexp.MustCompile(`\b(\d{4})\s*q([1-4])\b`), applyYearQuarter},
+	{regexp.MustCompile(`\bq([1-4])\b(?:\s*(\d{4})\b)?`), applyQuarter},
+	// 本季度、上个季度、this quarter
+	{regexp.MustCompile(`(下|上|这|本|当)个?季度`), applyRelQuarter},
+	{regexp.MustCompile(`\b(this|next|last)\s+quarter\b`), applyRelQuarter},
+	// 上半年、去年下半年
+	{regexp.MustCompile(`(?:(\d{4})年|(今|明|去)年)?(上|下)半年`), applyHalfYear},
+	// 最近7天、近一个月、过去两周、last 7 days
+	{regexp.MustCompile(`(?:最近|近|过去)\s*(\d+)?\s*(?:个)?\s*(天|日|周|星期|月|年)`), applyRecent},
+	{regexp.MustCompile(`\b(?:last|past)\s+(\d+)\s+(day|week|month|year)s?\b`), applyRecent},
+	// 3天内、一周之内、within 3 days、next 7 days
+	{regexp.MustCompile(`(\d+)\s*(?:个)?\s*(天|日|周|星期|月|年)\s*(?:内|以内|之内)`), applyWithin},
+	{regexp.MustCompile(`\b(?:within|next)\s+(\d+|an?)\s+(day|week|month|year)s?\b`), applyWithin},
+	// 节前、国庆前、春节、before the holiday、before national day
+	{regexp.MustCompile(`(元旦|春节|清明节?|劳动节|端午节?|中秋节?|国庆节?)\s*(?:假期|长假|黄金周)?\s*(?:前|之前|以前)`), applyHolidayBefore},
+	{regexp.MustCompile(`(节|假|假期|放假|节假日)(?:前|之前|以前)`), applyHolidayBefore},
+	{regexp.MustCompile(`(元旦|春节|清明节?|劳动节|端午节?|中秋节?|国庆节?)(?:假期|长假|黄金周|期间)?`), applyHoliday},
+	{regexp.MustCompile(`\bbefore\s+(?:the\s+)?(?:next\s+)?(holidays?|new year|spring festival|chinese new year|qingming|labou?r day|dragon boat festival|mid-autumn festival|national day)\b`), applyHolidayBefore},
+	{regexp.MustCompile(`\b(new year|spring festival|chinese new year|qingming|labou?r day|dragon boat festival|mid-autumn festival|national day)(?:\s+holidays?)?\b`), applyHoliday},
+}
+
+// holidayAliases 节假日别名，统一为不带“节”字的中文名称
+var holidayAliases = map[string]string{
+	"元旦": "元旦", "new year": "元旦",
+	"春节": "春节", "spring festival": "春节", "chinese new year": "春节",
+	"清明": "清明", "qingming": "清明",
+	"劳动": "劳动", "labor day": "劳动", "labour day": "劳动",
+	"端午": "端午", "dragon boat festival": "端午",
+	"中秋": "中秋", "mid-autumn festival": "中秋",
+	"国庆": "国庆", "national day": "国庆",
+}
+
+// quarterSpan 设置为整个季度
+func quarterSpan(s *state, year, quarter int, loc *time.Location) {
+	start := time.Date(year, time.Month((quarter-1)*3+1), 1, 0, 0, 0, 0, loc)
+	setSpan(s, start, start.AddDate(0, 3, 0), GrainQuarter)
+}
+
+func applyCnQuarter(p *Parser, s *state, m []string, _ string) bool {
+	year := p.Now.Year() + yearOffset(m[2])
+	if m[1] != "" {
+		year = atoi(m[1])
+	}
+	quarterSpan(s, year, atoi(m[3]), p.Now.Location())
+	return true
+}
+
+func applyQuarter(p *Parser, s *state, m []string, _ string) bool {
+	year := p.Now.Year()
+	if m[2] != "" {
+		year = atoi(m[2])
+	}
+	quarterSpan(s, year, atoi(m[1]), p.Now.Location())
+	return true
+}
+
+func applyYearQuarter(p *Parser, s *state, m []string, _ string) bool {
+	quarterSpan(s, atoi(m[1]), atoi(m[2]), p.Now.Location())
+	return true
+}
+
+func applyRelQuarter(p *Parser, s *state, m []string, _ string) bool {
+	now := p.Now
+	q := (int(now.Month())-1)/3 + 1 + relOffset(m[1])
+	year := now.Year()
+	for q < 1 {
+		q += 4
+		year--
+	}
+	for q > 4 {
+		q -= 4
+		year++
+	}
+	quarterSpan(s, year, q, now.Location())
+	return true
+}
+
+func applyHalfYear(p *Parser, s *state, m []string, _ string) bool {
+	year := p.Now.Year() + yearOffset(m[2])
+	if m[1] != "" {
+		year = atoi(m[1])
+	}
+	month := time.January
+	if m[3] == "下" {
+		month = time.July
+	}
+	start := time.Date(year, month, 1, 0, 0, 0, 0, p.Now.Location())
+	setSpan(s, start, start.AddDate(0, 6, 0), GrainMonth)
+	return true
+}
+
+// unitDate 在 t 上加减 n 个单位
+func unitDate(t time.Time, unit string, n int) time.Time {
+	switch unit {
+	case "周", "星期", "week":
+		return t.AddDate(0, 0, 7*n)
+	case "月", "month":
+		return t.AddDate(0, n, 0)
+	case "年", "year":
+		return t.AddDate(n, 0, 0)
+	}
+	return t.AddDate(0, 0, n)
+}
+
+// applyRecent 最近 N 个单位，包含今天
+func applyRecent(p *Parser, s *state, m []string, _ string) bool {
+	n := atoi(m[1])
+	if n <= 0 {
+		n = 1
+	}
+	end := dayOf(p.Now).AddDate(0, 0, 1)
+	setSpan(s, unitDate(end, m[2], -n), end, GrainDay)
+	return true
+}
+
+// applyWithin N 个单位之内，从今天开始
+func applyWithin(p *Parser, s *state, m []string, _ string) bool {
+	n := 1
+	if m[1] != "a" && m[1] != "an" {
+		n = atoi(m[1])
+	}
+	if n <= 0 {
+		return false
+	}
+	start := dayOf(p.Now)
+	setSpan(s, start, unitDate(start, m[2], n), GrainDay)
+	return true
+}
+
+// preHolidayDays 已经过去的节假日，“节前”指节假日开始前的天数
+const preHolidayDays = 7
+
+// nextHoliday 查找最近的节假日，name 为空时不限名称
+// ongoing 为 true 时包括正在放假的节假日，否则只查找今天之后开始的
+// 查询场景（不优先将来）没有即将到来的节假日时，返回最近一个已经过去的，past 为 true
+func (p *Parser) nextHoliday(name string, ongoing bool) (h Holiday, past, ok bool) {
+	today := dayOf(p.Now)
+	var latest Holiday
+	hasLatest := false
+	for _, c := range p.Holidays {
+		if name != "" && !strings.Contains(c.Name, name) {
+			continue
+		}
+		if ongoing && dayOf(c.End).Before(today) || !ongoing && !dayOf(c.Start).After(today) {
+			if !hasLatest || c.Start.After(latest.Start) {
+				latest, hasLatest = c, true
+			}
+			continue
+		}
+		if !ok || c.Start.Before(h.Start) {
+			h, ok = c, true
+		}
+	}
+	if !ok && hasLatest && !p.PreferFuture {
+		return latest, true, true
+	}
+	return h, false, ok
+}
+
+// holidayName 将匹配到的节假日名称转换为统一名称，泛指节假日时返回空
+func holidayName(w string) string {
+	if name, ok := holidayAliases[w]; ok {
+		return name
+	}
+	return holidayAliases[strings.TrimSuffix(w, "节")]
+}
+
+// applyHolidayBefore 节前：从今天到节假日开始前一天，已经过去的节假日取开始前 preHolidayDays 天
+func applyHolidayBefore(p *Parser, s *state, m []string, _ string) bool {
+	h, past, ok := p.nextHoliday(holidayName(m[1]), false)
+	if !ok {
+		return false
+	}
+	start := dayOf(p.Now)
+	if past {
+		start = dayOf(h.Start).AddDate(0, 0, -preHolidayDays)
+	}
+	setSpan(s, start, dayOf(h.Start), GrainDay)
+	return true
+}
+
+// applyHoliday 节假日期间
+func applyHoliday(p *Parser, s *state, m []string, _ string) bool {
+	h, _, ok := p.nextHoliday(holidayName(m[1]), true)
+	if !ok {
+		return false
+	}
+	setSpan(s, dayOf(h.Start), dayOf(h.End).AddDate(0, 0, 1), GrainDay)
+	return true
+}
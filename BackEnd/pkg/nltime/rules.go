@@ -0,0 +1,717 @@
+package nltime
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rule 解析规则：匹配到正则后由 apply 更新解析状态，返回 false 表示放弃本次匹配
+// rest 为匹配位置之后的文本，用于排除歧义
+type rule struct {
+	re    *regexp.Regexp
+	apply func(p *Parser, s *state, m []string, rest string) bool
+}
+
+const enMonth = `(jan(?:uary)?|feb(?:ruary)?|mar(?:ch)?|apr(?:il)?|may|june?|july?|aug(?:ust)?|sep(?:t(?:ember)?)?|oct(?:ober)?|nov(?:ember)?|dec(?:ember)?)`
+
+const enWeekday = `(monday|mon|tuesday|tues|tue|wednesday|wed|thursday|thurs|thur|thu|friday|fri|saturday|sat|sunday|sun)`
+
+// dateRules 日期规则，按优先级排列，只会命中一条
+var dateRules = append(append([]rule{}, rangeRules...), []rule{
+	// 2026-11-02、2026/11/02、2026年11月2日
+	{regexp.MustCompile(`(\d{4})\s*[-/.年]\s*(\d{1,2})\s*[-/.月]\s*(\d{1,2})\s*[日号]?`), applyYMD},
+	// 3月15日、明年3月15日
+	{regexp.MustCompile(`(?:(今|明|去|后)年)?(\d{1,2})月(\d{1,2})[日号]?`), applyCnMonthDay},
+	// march 15、mar 15th, 2027
+	{regexp.MustCompile(`\b` + enMonth + `\.?\s+(\d{1,2})(?:st|nd|rd|th)?\b(?:,?\s*(\d{4})\b)?`), applyEnMonthDay},
+	// 15 march、15th of march 2027
+	{regexp.MustCompile(`\b(\d{1,2})(?:st|nd|rd|th)?\s+(?:of\s+)?` + enMonth + `\b(?:,?\s*(\d{4})\b)?`), applyEnDayMonth},
+	// 2小时后、1个半小时后、10分钟前、1刻钟后
+	{regexp.MustCompile(`(\d+)?\s*(个)?\s*(半)?\s*(个)?\s*(小时|钟头|分钟|刻钟)\s*(后|之后|以后|前|之前|以前)`), applyCnOffset},
+	// 3天后、2周后、1个月后、半年后
+	{regexp.MustCompile(`(\d+)?\s*(个)?\s*(半)?\s*(个)?\s*(天|日|周|星期|礼拜|月|年)\s*(后|之后|以后|前|之前|以前)`), applyCnOffset},
+	// in 2 hours、in a week
+	{regexp.MustCompile(`\bin\s+(\d+|an?|one|half an?)\s+(minute|min|hour|hr|day|week|month|year)s?\b`), applyEnOffsetIn},
+	// 3 days later、2 hours from now、a week ago
+	{regexp.MustCompile(`\b(\d+|an?|one)\s+(minute|min|hour|hr|day|week|month|year)s?\s+(later|from now|after|ago)\b`), applyEnOffsetAgo},
+	// 月底、下个月初、3月底
+	{regexp.MustCompile(`(?:(下下|下|上|这|本|当)个?|(\d{1,2}))?月(底|末|初)`), applyCnMonthEdge},
+	// 年底、明年初
+	{regexp.MustCompile(`(?:(\d{4})|(今|明|去|本))?年(底|末|初)`), applyCnYearEdge},
+	// end of month、beginning of next year
+	{regexp.MustCompile(`\b(end|beginning|start)\s+of\s+(?:the\s+)?(?:(this|next|last)\s+)?(week|month|year)\b`), applyEnEdge},
+	// 周末、下周末、this weekend
+	{regexp.MustCompile(`(下下|下|上|这|本)?个?周末`), applyCnWeekend},
+	{regexp.MustCompile(`\b(?:(this|next|last|coming)\s+)?weekend\b`), applyEnWeekend},
+	// 周五、下周五、星期天、下下周一
+	{regexp.MustCompile(`(下下|下|上|这|本)?个?(?:周|星期|礼拜)([1-7日天])`), applyCnWeekday},
+	// friday、next friday、this mon
+	{regexp.MustCompile(`\b(?:(next|this|last|coming)\s+)?` + enWeekday + `\b`), applyEnWeekday},
+	// 今天、明天、后天、今晚、tomorrow
+	{regexp.MustCompile(`大后天|大前天|后天|前天|明天|明日|明早|明晚|今天|今日|今早|今晚|今夜|昨天|昨日|昨晚|day after tomorrow|day before yesterday|\btoday\b|\btonight\b|\btomorrow\b|\byesterday\b`), applyRelDay},
+	// 本周、下周、上个星期
+	{regexp.MustCompile(`(下下|下|上|这|本)个?(?:周|星期|礼拜)`), applyCnWeek},
+	// 下个月、本月、下个月15号
+	{regexp.MustCompile(`(下下|下|上|这|本|当)个?月(?:(\d{1,2})[日号])?`), applyCnMonth},
+	// 今年、明年、去年
+	{regexp.MustCompile(`(今|明|去|前|后)年`), applyCnYear},
+	// this week、next month、last year
+	{regexp.MustCompile(`\b(this|next|last)\s+(week|month|year)\b`), applyEnPeriod},
+	// 2027年3月、3月份
+	{regexp.MustCompile(`(?:(\d{4})年|(今|明|去)年)?(\d{1,2})月份?`), applyCnMonthOnly},
+	// march 2027、in march
+	{regexp.MustCompile(`\b(?:in\s+)?(january|february|march|april|june|july|august|september|october|november|december)\b(?:\s+(\d{4})\b)?`), applyEnMonthOnly},
+	// 2027年
+	{regexp.MustCompile(`(\d{4})年`), applyYearOnly},
+	// 11/2（按 月/日 理解）
+	{regexp.MustCompile(`\b(\d{1,2})/(\d{1,2})\b`), applySlashMonthDay},
+	// 15号
+	{regexp.MustCompile(`(\d{1,2})[日号]`), applyDayOnly},
+}...)
+
+// dayPartKind 时段类型，决定 12 小时制的换算方式
+type dayPartKind int
+
+const (
+	partNone    dayPartKind = iota
+	partAM                  // 上午
+	partNoon                // 中午
+	partPM                  // 下午
+	partEvening             // 晚上
+)
+
+// dayPart 时段
+type dayPart struct {
+	kind        dayPartKind
+	defaultHour int // 只写时段没写时刻时使用的默认时刻
+}
+
+var dayParts = map[string]dayPart{
+	"凌晨": {partAM, 0}, "清晨": {partAM, 7}, "早上": {partAM, 9}, "早晨": {partAM, 9}, "上午": {partAM, 9},
+	"上班": {partAM, 9}, "中午": {partNoon, 12}, "正午": {partNoon, 12}, "午后": {partPM, 14}, "下午": {partPM, 14},
+	"傍晚": {partPM, 18}, "下班": {partPM, 18}, "晚上": {partEvening, 19}, "晚间": {partEvening, 19},
+	"夜里": {partEvening, 21}, "夜晚": {partEvening, 21},
+	"morning": {partAM, 9}, "noon": {partNoon, 12}, "midday": {partNoon, 12}, "afternoon": {partPM, 14},
+	"evening": {partEvening, 19}, "night": {partEvening, 21}, "midnight": {partEvening, 24},
+	"eod": {partPM, 18}, "end of day": {partPM, 18},
+}
+
+// dayPartRules 时段规则
+var dayPartRules = []rule{
+	{regexp.MustCompile(`凌晨|清晨|早上|早晨|上午|上班|中午|正午|午后|下午|傍晚|下班|晚上|晚间|夜里|夜晚|\b(?:morning|noon|midday|afternoon|evening|night|midnight|eod|end of day)\b`), applyDayPart},
+}
+
+// clockRules 时刻规则
+var clockRules = []rule{
+	// 14:30、9:05 am
+	{regexp.MustCompile(`(\d{1,2}):(\d{2})(?::\d{2})?\s*(am|pm|a\.m\.|p\.m\.)?`), applyClock},
+	// 3pm、10 am
+	{regexp.MustCompile(`\b(\d{1,2})()\s*(am|pm|a\.m\.|p\.m\.)`), applyClock},
+	// 3点、15时30分、3点半、3点1刻
+	{regexp.MustCompile(`(\d{1,2})\s*[点时](?:(半)|(\d)\s*刻|\s*(\d{1,2})\s*分?)?`), applyCnClock},
+	// at 3
+	{regexp.MustCompile(`(?:\bat|@)\s*(\d{1,2})\b`), applyAtClock},
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// setDay 设置日期为某一天
+func setDay(s *state, t time.Time) {
+	s.hasDate = true
+	s.start = dayOf(t)
+	s.end = s.start.AddDate(0, 0, 1)
+	s.grain = GrainDay
+}
+
+// setSpan 设置日期为一段时间
+func setSpan(s *state, start, end time.Time, grain Grain) {
+	s.hasDate = true
+	s.start, s.end, s.grain = start, end, grain
+}
+
+// lower 降低置信度
+func lower(s *state, conf float64) {
+	if conf < s.conf {
+		s.conf = conf
+	}
+}
+
+// makeDate 构造日期，日期不合法（如 2月30日）时返回 false
+func makeDate(year, month, day int, loc *time.Location) (time.Time, bool) {
+	if month < 1 || month > 12 || day < 1 || day > 31 {
+		return time.Time{}, false
+	}
+	t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, loc)
+	return t, t.Day() == day
+}
+
+// invalidDate 明确写出的日期不合法（如 2月30日），整个表达式视为无效
+func invalidDate(s *state) bool {
+	s.invalid = true
+	return true
+}
+
+// monthDay 构造未写年份的日期，按参考时间推断年份
+func monthDay(p *Parser, s *state, month, day int) bool {
+	anchor := s.anchor
+	t, ok := makeDate(anchor.Year(), month, day, anchor.Location())
+	if !ok {
+		return invalidDate(s)
+	}
+	if p.PreferFuture && t.Before(dayOf(anchor)) {
+		t, ok = makeDate(anchor.Year()+1, month, day, anchor.Location())
+		if !ok {
+			return invalidDate(s)
+		}
+		lower(s, 0.9)
+	}
+	setDay(s, t)
+	return true
+}
+
+// yearOffset 今年、明年、去年等对应的年份偏移
+func yearOffset(w string) int {
+	switch w {
+	case "明":
+		return 1
+	case "去":
+		return -1
+	case "后":
+		return 2
+	case "前":
+		return -2
+	}
+	return 0
+}
+
+// relOffset 下周、上个月等前缀对应的偏移
+func relOffset(w string) int {
+	switch w {
+	case "下", "next", "coming":
+		return 1
+	case "下下":
+		return 2
+	case "上", "last":
+		return -1
+	}
+	return 0
+}
+
+func applyYMD(p *Parser, s *state, m []string, _ string) bool {
+	t, ok := makeDate(atoi(m[1]), atoi(m[2]), atoi(m[3]), p.Now.Location())
+	if !ok {
+		return invalidDate(s)
+	}
+	setDay(s, t)
+	return true
+}
+
+func applyCnMonthDay(p *Parser, s *state, m []string, _ string) bool {
+	if m[1] == "" {
+		return monthDay(p, s, atoi(m[2]), atoi(m[3]))
+	}
+	t, ok := makeDate(p.Now.Year()+yearOffset(m[1]), atoi(m[2]), atoi(m[3]), p.Now.Location())
+	if !ok {
+		return invalidDate(s)
+	}
+	setDay(s, t)
+	return true
+}
+
+func enMonthNumber(name string) int {
+	months := []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+	for i, mo := range months {
+		if strings.HasPrefix(name, mo) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// enMonthDay 英文月日，后面紧跟 am/pm 或冒号时说明数字其实是时刻（如 "mar 3pm"）
+func enMonthDay(p *Parser, s *state, month, day int, year, rest string) bool {
+	rest = strings.TrimSpace(rest)
+	if strings.HasPrefix(rest, "am") || strings.HasPrefix(rest, "pm") || strings.HasPrefix(rest, ":") {
+		return false
+	}
+	if year == "" {
+		return monthDay(p, s, month, day)
+	}
+	t, ok := makeDate(atoi(year), month, day, p.Now.Location())
+	if !ok {
+		return invalidDate(s)
+	}
+	setDay(s, t)
+	return true
+}
+
+func applyEnMonthDay(p *Parser, s *state, m []string, rest string) bool {
+	return enMonthDay(p, s, enMonthNumber(m[1]), atoi(m[2]), m[3], rest)
+}
+
+func applyEnDayMonth(p *Parser, s *state, m []string, rest string) bool {
+	return enMonthDay(p, s, enMonthNumber(m[2]), atoi(m[1]), m[3], rest)
+}
+
+// offset 相对偏移量
+type offset struct {
+	years, months, days int
+	minutes             int
+}
+
+// applyOffset 在参考时间上加减偏移，小时和分钟偏移得到精确时刻，其余得到某一天
+func applyOffset(p *Parser, s *state, o offset, sign int) bool {
+	if o == (offset{}) {
+		return false
+	}
+	if o.minutes != 0 {
+		s.hasInstant = true
+		s.instant = p.Now.Add(time.Duration(sign*o.minutes) * time.Minute).Truncate(time.Minute)
+		return true
+	}
+	setDay(s, p.Now.AddDate(sign*o.years, sign*o.months, sign*o.days))
+	return true
+}
+
+func applyCnOffset(p *Parser, s *state, m []string, _ string) bool {
+	n, half := atoi(m[1]), m[3] != ""
+	if m[1] == "" && !half {
+		return false
+	}
+	// “3月后”是三月之后而不是三个月后，月份偏移必须带“个”
+	if m[5] == "月" && m[2] == "" && m[4] == "" {
+		return false
+	}
+
+	var o offset
+	switch m[5] {
+	case "小时", "钟头":
+		o.minutes = n * 60
+		if half {
+			o.minutes += 30
+		}
+	case "分钟":
+		o.minutes = n
+	case "刻钟":
+		o.minutes = n * 15
+	case "天", "日":
+		o.days = n
+		if half {
+			o.minutes = n*24*60 + 12*60
+		}
+	case "周", "星期", "礼拜":
+		o.days = n * 7
+		if half {
+			o.days += 3
+		}
+	case "月":
+		o.months = n
+		if half {
+			o.days = 15
+		}
+	case "年":
+		o.years = n
+		if half {
+			o.months = 6
+		}
+	}
+
+	sign := 1
+	if strings.HasSuffix(m[6], "前") {
+		sign = -1
+	}
+	return applyOffset(p, s, o, sign)
+}
+
+// enOffset 英文偏移量
+func enOffset(amount, unit string) offset {
+	n := 1
+	half := strings.HasPrefix(amount, "half")
+	if amount != "a" && amount != "an" && amount != "one" && !half {
+		n = atoi(amount)
+	}
+
+	var o offset
+	switch unit {
+	case "minute", "min":
+		o.minutes = n
+	case "hour", "hr":
+		o.minutes = n * 60
+		if half {
+			o.minutes = 30
+		}
+	case "day":
+		o.days = n
+		if half {
+			o = offset{minutes: 12 * 60}
+		}
+	case "week":
+		o.days = n * 7
+	case "month":
+		o.months = n
+		if half {
+			o = offset{days: 15}
+		}
+	case "year":
+		o.years = n
+		if half {
+			o = offset{months: 6}
+		}
+	}
+	return o
+}
+
+func applyEnOffsetIn(p *Parser, s *state, m []string, _ string) bool {
+	return applyOffset(p, s, enOffset(m[1], m[2]), 1)
+}
+
+func applyEnOffsetAgo(p *Parser, s *state, m []string, _ string) bool {
+	sign := 1
+	if m[3] == "ago" {
+		sign = -1
+	}
+	return applyOffset(p, s, enOffset(m[1], m[2]), sign)
+}
+
+// monthEdge 月初为 1 号，月底为最后一天
+func monthEdge(s *state, year int, month time.Month, loc *time.Location, begin bool) {
+	if begin {
+		setDay(s, time.Date(year, month, 1, 0, 0, 0, 0, loc))
+		return
+	}
+	setDay(s, time.Date(year, month+1, 0, 0, 0, 0, 0, loc))
+}
+
+func applyCnMonthEdge(p *Parser, s *state, m []string, _ string) bool {
+	now := p.Now
+	year, month := now.Year(), now.Month()
+	if m[2] != "" {
+		mo := atoi(m[2])
+		if mo < 1 || mo > 12 {
+			return false
+		}
+		month = time.Month(mo)
+		if p.PreferFuture && month < now.Month() {
+			year++
+		}
+	} else {
+		month += time.Month(relOffset(m[1]))
+	}
+	monthEdge(s, year, month, now.Location(), m[3] == "初")
+	return true
+}
+
+func applyCnYearEdge(p *Parser, s *state, m []string, _ string) bool {
+	year := p.Now.Year() + yearOffset(m[2])
+	if m[1] != "" {
+		year = atoi(m[1])
+	}
+	if m[3] == "初" {
+		setDay(s, time.Date(year, 1, 1, 0, 0, 0, 0, p.Now.Location()))
+	} else {
+		setDay(s, time.Date(year, 12, 31, 0, 0, 0, 0, p.Now.Location()))
+	}
+	return true
+}
+
+func applyEnEdge(p *Parser, s *state, m []string, _ string) bool {
+	now, n := p.Now, relOffset(m[2])
+	begin := m[1] != "end"
+	switch m[3] {
+	case "week":
+		// 一周的结束按工作日计为周五
+		start := weekStart(now).AddDate(0, 0, 7*n)
+		if begin {
+			setDay(s, start)
+		} else {
+			setDay(s, start.AddDate(0, 0, 4))
+		}
+	case "month":
+		monthEdge(s, now.Year(), now.Month()+time.Month(n), now.Location(), begin)
+	case "year":
+		if begin {
+			setDay(s, time.Date(now.Year()+n, 1, 1, 0, 0, 0, 0, now.Location()))
+		} else {
+			setDay(s, time.Date(now.Year()+n, 12, 31, 0, 0, 0, 0, now.Location()))
+		}
+	}
+	return true
+}
+
+// weekend 周六零点到下周一零点
+func weekend(s *state, now time.Time, n int) {
+	start := weekStart(now).AddDate(0, 0, 7*n+5)
+	setSpan(s, start, start.AddDate(0, 0, 2), GrainDay)
+}
+
+func applyCnWeekend(p *Parser, s *state, m []string, _ string) bool {
+	weekend(s, p.Now, relOffset(m[1]))
+	return true
+}
+
+func applyEnWeekend(p *Parser, s *state, m []string, _ string) bool {
+	n := relOffset(m[1])
+	if m[1] == "coming" {
+		n = 0
+	}
+	weekend(s, p.Now, n)
+	return true
+}
+
+// weekday 计算星期几：指定了本周、下周等前缀时取对应周，否则取参考时间当天或之后最近的一天
+func weekday(p *Parser, s *state, prefix string, idx int) {
+	if prefix != "" {
+		setDay(s, weekStart(p.Now).AddDate(0, 0, 7*relOffset(prefix)+idx))
+		return
+	}
+
+	anchor := dayOf(s.anchor)
+	diff := (idx - (int(anchor.Weekday())+6)%7 + 7) % 7
+	if !p.PreferFuture && diff > 0 {
+		// 查询场景下取本周
+		diff -= 7
+	}
+	setDay(s, anchor.AddDate(0, 0, diff))
+	lower(s, 0.9)
+}
+
+func applyCnWeekday(p *Parser, s *state, m []string, _ string) bool {
+	idx := 6
+	if m[2] != "日" && m[2] != "天" {
+		idx = atoi(m[2]) - 1
+	}
+	weekday(p, s, m[1], idx)
+	return true
+}
+
+func applyEnWeekday(p *Parser, s *state, m []string, _ string) bool {
+	days := []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"}
+	idx := 0
+	for i, d := range days {
+		if strings.HasPrefix(m[2], d) {
+			idx = i
+		}
+	}
+	prefix := m[1]
+	if prefix == "coming" {
+		prefix = ""
+	}
+	weekday(p, s, prefix, idx)
+	return true
+}
+
+// relDays 相对日期词对应的天数偏移以及隐含的时段
+var relDays = map[string]struct {
+	days int
+	part string
+}{
+	"大后天": {3, ""}, "后天": {2, ""}, "明天": {1, ""}, "明日": {1, ""}, "明早": {1, "早上"}, "明晚": {1, "晚上"},
+	"今天": {0, ""}, "今日": {0, ""}, "今早": {0, "早上"}, "今晚": {0, "晚上"}, "今夜": {0, "夜里"},
+	"昨天": {-1, ""}, "昨日": {-1, ""}, "昨晚": {-1, "晚上"}, "前天": {-2, ""}, "大前天": {-3, ""},
+	"today": {0, ""}, "tonight": {0, "evening"}, "tomorrow": {1, ""}, "yesterday": {-1, ""},
+	"day after tomorrow": {2, ""}, "day before yesterday": {-2, ""},
+}
+
+func applyRelDay(p *Parser, s *state, m []string, _ string) bool {
+	d, ok := relDays[m[0]]
+	if !ok {
+		return false
+	}
+	setDay(s, p.Now.AddDate(0, 0, d.days))
+	if d.part != "" {
+		setDayPart(s, dayParts[d.part])
+	}
+	return true
+}
+
+func applyCnWeek(p *Parser, s *state, m []string, _ string) bool {
+	start := weekStart(p.Now).AddDate(0, 0, 7*relOffset(m[1]))
+	setSpan(s, start, start.AddDate(0, 0, 7), GrainWeek)
+	return true
+}
+
+func applyCnMonth(p *Parser, s *state, m []string, _ string) bool {
+	now := p.Now
+	start := time.Date(now.Year(), now.Month()+time.Month(relOffset(m[1])), 1, 0, 0, 0, 0, now.Location())
+	if m[2] != "" {
+		t, ok := makeDate(start.Year(), int(start.Month()), atoi(m[2]), now.Location())
+		if !ok {
+			return invalidDate(s)
+		}
+		setDay(s, t)
+		return true
+	}
+	setSpan(s, start, start.AddDate(0, 1, 0), GrainMonth)
+	return true
+}
+
+// yearSpan 设置为整年
+func yearSpan(s *state, year int, loc *time.Location) {
+	start := time.Date(year, 1, 1, 0, 0, 0, 0, loc)
+	setSpan(s, start, start.AddDate(1, 0, 0), GrainYear)
+}
+
+func applyCnYear(p *Parser, s *state, m []string, _ string) bool {
+	yearSpan(s, p.Now.Year()+yearOffset(m[1]), p.Now.Location())
+	return true
+}
+
+func applyEnPeriod(p *Parser, s *state, m []string, _ string) bool {
+	now, n := p.Now, relOffset(m[1])
+	switch m[2] {
+	case "week":
+		start := weekStart(now).AddDate(0, 0, 7*n)
+		setSpan(s, start, start.AddDate(0, 0, 7), GrainWeek)
+	case "month":
+		start := time.Date(now.Year(), now.Month()+time.Month(n), 1, 0, 0, 0, 0, now.Location())
+		setSpan(s, start, start.AddDate(0, 1, 0), GrainMonth)
+	case "year":
+		yearSpan(s, now.Year()+n, now.Location())
+	}
+	return true
+}
+
+// monthSpan 设置为整月，未写年份时按参考时间推断
+func monthSpan(p *Parser, s *state, year, month int, explicitYear bool) bool {
+	if month < 1 || month > 12 {
+		return false
+	}
+	if !explicitYear && p.PreferFuture && time.Month(month) < s.anchor.Month() {
+		year++
+		lower(s, 0.9)
+	}
+	start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, p.Now.Location())
+	setSpan(s, start, start.AddDate(0, 1, 0), GrainMonth)
+	return true
+}
+
+func applyCnMonthOnly(p *Parser, s *state, m []string, _ string) bool {
+	switch {
+	case m[1] != "":
+		return monthSpan(p, s, atoi(m[1]), atoi(m[3]), true)
+	case m[2] != "":
+		return monthSpan(p, s, p.Now.Year()+yearOffset(m[2]), atoi(m[3]), true)
+	}
+	return monthSpan(p, s, s.anchor.Year(), atoi(m[3]), false)
+}
+
+func applyEnMonthOnly(p *Parser, s *state, m []string, _ string) bool {
+	if m[2] != "" {
+		return monthSpan(p, s, atoi(m[2]), enMonthNumber(m[1]), true)
+	}
+	return monthSpan(p, s, s.anchor.Year(), enMonthNumber(m[1]), false)
+}
+
+func applyYearOnly(p *Parser, s *state, m []string, _ string) bool {
+	yearSpan(s, atoi(m[1]), p.Now.Location())
+	return true
+}
+
+func applySlashMonthDay(p *Parser, s *state, m []string, _ string) bool {
+	// 不是合法日期时可能是分数等其他含义，直接放弃
+	if _, ok := makeDate(s.anchor.Year(), atoi(m[1]), atoi(m[2]), s.anchor.Location()); !ok {
+		return false
+	}
+	monthDay(p, s, atoi(m[1]), atoi(m[2]))
+	// 11/2 也可能是 日/月，置信度较低
+	lower(s, 0.7)
+	return true
+}
+
+func applyDayOnly(p *Parser, s *state, m []string, _ string) bool {
+	day := atoi(m[1])
+	if day < 1 || day > 31 {
+		return false
+	}
+	s.hasDate = true
+	s.dayOnly = day
+	lower(s, 0.85)
+	return true
+}
+
+// setDayPart 设置时段，没有写具体时刻时使用时段的默认时刻
+func setDayPart(s *state, part dayPart) {
+	s.part = part
+	if !s.hasClock {
+		s.hasClock = true
+		s.defaultClock = true
+		s.hour, s.minute = part.defaultHour, 0
+	}
+}
+
+func applyDayPart(p *Parser, s *state, m []string, _ string) bool {
+	part, ok := dayParts[m[0]]
+	if !ok {
+		return false
+	}
+	setDayPart(s, part)
+	return true
+}
+
+// setClock 设置具体时刻，会覆盖时段的默认时刻
+func setClock(s *state, hour, minute int, suffix string) bool {
+	if hour > 24 || minute > 59 {
+		return false
+	}
+	s.hasClock = true
+	s.defaultClock = false
+	s.hour, s.minute = hour, minute
+	switch strings.ReplaceAll(suffix, ".", "") {
+	case "am":
+		s.part = dayPart{kind: partAM}
+	case "pm":
+		s.part = dayPart{kind: partPM}
+	}
+	return true
+}
+
+func applyClock(p *Parser, s *state, m []string, _ string) bool {
+	return setClock(s, atoi(m[1]), atoi(m[2]), m[3])
+}
+
+func applyCnClock(p *Parser, s *state, m []string, _ string) bool {
+	minute := atoi(m[4])
+	switch {
+	case m[2] != "":
+		minute = 30
+	case m[3] != "":
+		minute = atoi(m[3]) * 15
+	}
+	return setClock(s, atoi(m[1]), minute, "")
+}
+
+func applyAtClock(p *Parser, s *state, m []string, _ string) bool {
+	if !setClock(s, atoi(m[1]), 0, "") {
+		return false
+	}
+	lower(s, 0.8)
+	return true
+}
+
+// adjustHour 按时段把 12 小时制换算为 24 小时制
+func adjustHour(hour int, kind dayPartKind) int {
+	switch kind {
+	case partAM:
+		if hour == 12 {
+			return 0
+		}
+	case partNoon:
+		if hour >= 1 && hour <= 5 {
+			return hour + 12
+		}
+	case partPM:
+		if hour >= 1 && hour < 12 {
+			return hour + 12
+		}
+	case partEvening:
+		if hour >= 1 && hour <= 12 {
+			return hour + 12
+		}
+	}
+	return hour
+}
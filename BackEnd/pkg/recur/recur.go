@@ -0,0 +1,291 @@
+// Package recur 重复规则：解析 RRULE（RFC 5545 的子集）并计算下一次发生的时间
+//
+// 支持 FREQ=DAILY/WEEKLY/MONTHLY、INTERVAL、BYDAY（不带序号，如 MO,FR）、BYMONTHDAY（1~31，-1 表示最后一天）、
+// UNTIL 和 COUNT，周从周一开始
+package recur
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Freq 重复频率
+type Freq string
+
+const (
+	Daily   Freq = "DAILY"
+	Weekly  Freq = "WEEKLY"
+	Monthly Freq = "MONTHLY"
+)
+
+// maxMonths 按月查找下一次时最多向后查找的月数，避免 BYMONTHDAY=31 配合 INTERVAL 时无限循环
+const maxMonths = 12 * 8
+
+var weekdayCodes = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+var weekdayNames = []string{"周日", "周一", "周二", "周三", "周四", "周五", "周六"}
+
+// Rule 重复规则
+type Rule struct {
+	Freq       Freq
+	Interval   int            // 间隔，默认 1
+	ByDay      []time.Weekday // 每周的哪几天，为空时与上一次相同
+	ByMonthDay []int          // 每月的哪几天，-1 表示最后一天，为空时与上一次相同
+	Until      time.Time      // 截止时间（包含），零值表示不限
+	Count      int            // 总次数，0 表示不限，由调用方计数
+}
+
+// Parse 解析 RRULE，可以带 RRULE: 前缀
+func Parse(s string) (*Rule, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(strings.ToUpper(s), "RRULE:")
+	if s == "" {
+		return nil, errors.New("empty rrule")
+	}
+
+	r := &Rule{Interval: 1}
+	for _, part := range strings.Split(s, ";") {
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("invalid rrule part %q", part)
+		}
+		switch key {
+		case "FREQ":
+			r.Freq = Freq(value)
+			if r.Freq != Daily && r.Freq != Weekly && r.Freq != Monthly {
+				return nil, fmt.Errorf("unsupported frequency %q", value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > 365 {
+				return nil, fmt.Errorf("invalid interval %q", value)
+			}
+			r.Interval = n
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				i := slices.Index(weekdayCodes, code)
+				if i < 0 {
+					return nil, fmt.Errorf("unsupported weekday %q", code)
+				}
+				if !slices.Contains(r.ByDay, time.Weekday(i)) {
+					r.ByDay = append(r.ByDay, time.Weekday(i))
+				}
+			}
+		case "BYMONTHDAY":
+			for _, d := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(d)
+				if err != nil || n == 0 || n > 31 || n < -1 {
+					return nil, fmt.Errorf("invalid month day %q", d)
+				}
+				if !slices.Contains(r.ByMonthDay, n) {
+					r.ByMonthDay = append(r.ByMonthDay, n)
+				}
+			}
+		case "UNTIL":
+			t, err := parseUntil(value)
+			if err != nil {
+				return nil, err
+			}
+			r.Until = t
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid count %q", value)
+			}
+			r.Count = n
+		case "WKST":
+			if value != "MO" {
+				return nil, errors.New("only WKST=MO is supported")
+			}
+		default:
+			return nil, fmt.Errorf("unsupported rrule part %q", key)
+		}
+	}
+
+	if r.Freq == "" {
+		return nil, errors.New("rrule needs FREQ")
+	}
+	if len(r.ByDay) > 0 && r.Freq != Weekly {
+		return nil, errors.New("BYDAY is only supported with FREQ=WEEKLY")
+	}
+	if len(r.ByMonthDay) > 0 && r.Freq != Monthly {
+		return nil, errors.New("BYMONTHDAY is only supported with FREQ=MONTHLY")
+	}
+	if !r.Until.IsZero() && r.Count > 0 {
+		return nil, errors.New("UNTIL and COUNT cannot be used together")
+	}
+	slices.SortFunc(r.ByDay, func(a, b time.Weekday) int { return weekdayIndex(a) - weekdayIndex(b) })
+	slices.Sort(r.ByMonthDay)
+	return r, nil
+}
+
+func parseUntil(s string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			if layout == "20060102" {
+				// 只有日期时包含当天
+				t = t.Add(24*time.Hour - time.Second)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid until %q", s)
+}
+
+// String 返回规范化的 RRULE，不带前缀
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, 0, len(r.ByDay))
+		for _, d := range r.ByDay {
+			codes = append(codes, weekdayCodes[d])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, 0, len(r.ByMonthDay))
+		for _, d := range r.ByMonthDay {
+			days = append(days, strconv.Itoa(d))
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Describe 返回规则的中文描述，如 每周五、每2个月的15日
+func (r *Rule) Describe() string {
+	var b strings.Builder
+	b.WriteString("每")
+	if r.Interval > 1 {
+		b.WriteString(strconv.Itoa(r.Interval))
+	}
+	switch r.Freq {
+	case Daily:
+		b.WriteString("天")
+	case Weekly:
+		if r.Interval > 1 {
+			b.WriteString("周的")
+		}
+		if len(r.ByDay) == 0 {
+			if r.Interval == 1 {
+				b.WriteString("周")
+			}
+			break
+		}
+		names := make([]string, 0, len(r.ByDay))
+		for _, d := range r.ByDay {
+			names = append(names, weekdayNames[d])
+		}
+		b.WriteString(strings.Join(names, "、"))
+	case Monthly:
+		if r.Interval > 1 {
+			b.WriteString("个")
+		}
+		b.WriteString("月")
+		if len(r.ByMonthDay) > 0 {
+			days := make([]string, 0, len(r.ByMonthDay))
+			for _, d := range r.ByMonthDay {
+				if d == -1 {
+					days = append(days, "最后一天")
+				} else {
+					days = append(days, strconv.Itoa(d)+"日")
+				}
+			}
+			b.WriteString("的" + strings.Join(days, "、"))
+		}
+	}
+	if !r.Until.IsZero() {
+		b.WriteString("，至" + r.Until.Format(time.DateOnly))
+	}
+	if r.Count > 0 {
+		fmt.Fprintf(&b, "，共%d次", r.Count)
+	}
+	return b.String()
+}
+
+// Next 返回 prev 之后的下一次发生时间，时分秒和时区与 prev 相同
+// prev 是上一次发生的时间，INTERVAL 从 prev 所在的周期开始计算；超过 UNTIL 时返回零值
+func (r *Rule) Next(prev time.Time) time.Time {
+	var next time.Time
+	switch r.Freq {
+	case Daily:
+		next = prev.AddDate(0, 0, r.Interval)
+	case Weekly:
+		next = r.nextWeekly(prev)
+	case Monthly:
+		next = r.nextMonthly(prev)
+	}
+	if next.IsZero() || (!r.Until.IsZero() && next.After(r.Until)) {
+		return time.Time{}
+	}
+	return next
+}
+
+func (r *Rule) nextWeekly(prev time.Time) time.Time {
+	if len(r.ByDay) == 0 {
+		return prev.AddDate(0, 0, 7*r.Interval)
+	}
+	// 本周剩余的日子
+	cur := weekdayIndex(prev.Weekday())
+	for _, d := range r.ByDay {
+		if i := weekdayIndex(d); i > cur {
+			return prev.AddDate(0, 0, i-cur)
+		}
+	}
+	// 间隔 Interval 周后的第一天
+	monday := prev.AddDate(0, 0, -cur+7*r.Interval)
+	return monday.AddDate(0, 0, weekdayIndex(r.ByDay[0]))
+}
+
+func (r *Rule) nextMonthly(prev time.Time) time.Time {
+	days := r.ByMonthDay
+	if len(days) == 0 {
+		days = []int{prev.Day()}
+	}
+	hour, minute, sec := prev.Clock()
+	year, month, _ := prev.Date()
+
+	for i := 0; i <= maxMonths; i += r.Interval {
+		first := time.Date(year, month+time.Month(i), 1, hour, minute, sec, 0, prev.Location())
+		last := first.AddDate(0, 1, -1).Day()
+		candidates := make([]int, 0, len(days))
+		for _, d := range days {
+			if d == -1 {
+				d = last
+			}
+			// 当月没有的日期跳过，与 RRULE 一致
+			if d <= last && !slices.Contains(candidates, d) {
+				candidates = append(candidates, d)
+			}
+		}
+		slices.Sort(candidates)
+		for _, d := range candidates {
+			t := first.AddDate(0, 0, d-1)
+			if t.After(prev) {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// weekdayIndex 周一为 0，周日为 6
+func weekdayIndex(d time.Weekday) int {
+	return (int(d) + 6) % 7
+}
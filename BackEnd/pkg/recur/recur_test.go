@@ -0,0 +1,80 @@
+package recur
+
+import (
+	"testing"
+	"time"
+)
+
+// Test_Parse 测试 RRULE 的解析和规范化
+func Test_Parse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		desc string
+	}{
+		{"FREQ=DAILY", "FREQ=DAILY", "每天"},
+		{"RRULE:freq=weekly;byday=FR", "FREQ=WEEKLY;BYDAY=FR", "每周五"},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=FR,MO", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR", "每2周的周一、周五"},
+		{"FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=12", "FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=12", "每月的最后一天，共12次"},
+	}
+	for _, tt := range tests {
+		r, err := Parse(tt.in)
+		if err != nil {
+			t.Fatalf("Parse(%q) error: %v", tt.in, err)
+		}
+		if got := r.String(); got != tt.want {
+			t.Errorf("Parse(%q).String() = %q, want %q", tt.in, got, tt.want)
+		}
+		if got := r.Describe(); got != tt.desc {
+			t.Errorf("Parse(%q).Describe() = %q, want %q", tt.in, got, tt.desc)
+		}
+	}
+
+	for _, in := range []string{"", "FREQ=YEARLY", "FREQ=DAILY;BYDAY=MO", "FREQ=WEEKLY;BYDAY=1MO", "FREQ=MONTHLY;BYMONTHDAY=32", "FREQ=DAILY;BYSETPOS=1"} {
+		if _, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) expected error", in)
+		}
+	}
+}
+
+// Test_Next 测试下一次发生时间的计算
+func Test_Next(t *testing.T) {
+	loc := time.FixedZone("CST", 8*3600)
+	at := func(s string) time.Time {
+		v, _ := time.ParseInLocation("2006-01-02 15:04", s, loc)
+		return v
+	}
+
+	tests := []struct {
+		rule string
+		prev string
+		want string
+	}{
+		{"FREQ=DAILY;INTERVAL=2", "2026-10-30 18:00", "2026-11-01 18:00"},
+		{"FREQ=WEEKLY", "2026-10-16 18:00", "2026-10-23 18:00"},
+		{"FREQ=WEEKLY;BYDAY=FR", "2026-10-14 09:00", "2026-10-16 09:00"},
+		{"FREQ=WEEKLY;BYDAY=MO,FR", "2026-10-16 18:00", "2026-10-19 18:00"},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR", "2026-10-16 18:00", "2026-10-26 18:00"},
+		{"FREQ=MONTHLY", "2026-10-15 10:00", "2026-11-15 10:00"},
+		{"FREQ=MONTHLY;BYMONTHDAY=31", "2026-10-31 10:00", "2026-12-31 10:00"},
+		{"FREQ=MONTHLY;BYMONTHDAY=-1", "2026-01-31 10:00", "2026-02-28 10:00"},
+		{"FREQ=MONTHLY;BYMONTHDAY=1,15", "2026-10-01 10:00", "2026-10-15 10:00"},
+		{"FREQ=WEEKLY;UNTIL=20261020", "2026-10-16 18:00", ""},
+	}
+	for _, tt := range tests {
+		r, err := Parse(tt.rule)
+		if err != nil {
+			t.Fatalf("Parse(%q) error: %v", tt.rule, err)
+		}
+		got := r.Next(at(tt.prev))
+		if tt.want == "" {
+			if !got.IsZero() {
+				t.Errorf("%s Next(%s) = %v, want zero", tt.rule, tt.prev, got)
+			}
+			continue
+		}
+		if !got.Equal(at(tt.want)) {
+			t.Errorf("%s Next(%s) = %v, want %s", tt.rule, tt.prev, got, tt.want)
+		}
+	}
+}
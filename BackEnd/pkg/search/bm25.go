@@ -0,0 +1,176 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+const (
+	bm25K1 = 1.2  // 词频饱和参数
+	bm25B  = 0.75 // 文档长度归一化参数
+)
+
+// Hit 关键词检索命中结果
+type Hit struct {
+	ID    string  // 文档ID
+	Score float64 // BM25 得分
+}
+
+// document 索引中的单个文档
+type document struct {
+	text   string
+	length int
+	terms  map[string]int // 词 -> 词频
+}
+
+// Index 基于 BM25 的内存倒排索引，并发安全
+type Index struct {
+	mu       sync.RWMutex
+	docs     map[string]*document       // 文档ID -> 文档
+	postings map[string]map[string]bool // 词 -> 包含该词的文档ID集合
+	totalLen int                        // 所有文档的词数之和，用于计算平均长度
+}
+
+// NewIndex 创建一个空的关键词索引
+func NewIndex() *Index {
+	return &Index{
+		docs:     make(map[string]*document),
+		postings: make(map[string]map[string]bool),
+	}
+}
+
+// Add 添加或替换一个文档
+func (idx *Index) Add(id, text string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(id)
+
+	tokens := Tokenize(text)
+	doc := &document{
+		text:   text,
+		length: len(tokens),
+		terms:  make(map[string]int, len(tokens)),
+	}
+	for _, t := range tokens {
+		doc.terms[t]++
+	}
+	for t := range doc.terms {
+		if idx.postings[t] == nil {
+			idx.postings[t] = make(map[string]bool)
+		}
+		idx.postings[t][id] = true
+	}
+
+	idx.docs[id] = doc
+	idx.totalLen += doc.length
+}
+
+// Remove 从索引中删除一个文档
+func (idx *Index) Remove(id string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(id)
+}
+
+// remove 删除文档，调用方需持有写锁
+func (idx *Index) remove(id string) {
+	doc, ok := idx.docs[id]
+	if !ok {
+		return
+	}
+	for t := range doc.terms {
+		delete(idx.postings[t], id)
+		if len(idx.postings[t]) == 0 {
+			delete(idx.postings, t)
+		}
+	}
+	idx.totalLen -= doc.length
+	delete(idx.docs, id)
+}
+
+// Len 返回索引中的文档数量
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return len(idx.docs)
+}
+
+// IDs 返回索引中全部文档的ID，按字典序排列
+func (idx *Index) IDs() []string {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	ids := make([]string, 0, len(idx.docs))
+	for id := range idx.docs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Text 返回文档原文
+func (idx *Index) Text(id string) (string, bool) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	doc, ok := idx.docs[id]
+	if !ok {
+		return "", false
+	}
+	return doc.text, true
+}
+
+// Search 使用 BM25 检索与 query 最相关的前 k 个文档
+func (idx *Index) Search(query string, k int) []Hit {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	if len(idx.docs) == 0 || k <= 0 {
+		return nil
+	}
+
+	// 查询词去重，避免重复词放大得分
+	terms := make(map[string]bool)
+	for _, t := range Tokenize(query) {
+		terms[t] = true
+	}
+
+	n := float64(len(idx.docs))
+	avgLen := float64(idx.totalLen) / n
+	scores := make(map[string]float64)
+
+	for t := range terms {
+		posting := idx.postings[t]
+		if len(posting) == 0 {
+			continue
+		}
+		df := float64(len(posting))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for id := range posting {
+			doc := idx.docs[id]
+			tf := float64(doc.terms[t])
+			norm := tf + bm25K1*(1-bm25B+bm25B*float64(doc.length)/avgLen)
+			scores[id] += idf * tf * (bm25K1 + 1) / norm
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, s := range scores {
+		hits = append(hits, Hit{ID: id, Score: s})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score == hits[j].Score {
+			return hits[i].ID < hits[j].ID
+		}
+		return hits[i].Score > hits[j].Score
+	})
+
+	if len(hits) > k {
+		hits = hits[:k]
+	}
+	return hits
+}
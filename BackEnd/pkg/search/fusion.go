@@ -0,0 +1,92 @@
+package search
+
+import (
+	"sort"
+	"strings"
+)
+
+// DefaultRRFK RRF 融合的平滑常数，取论文推荐值 60
+const DefaultRRFK = 60
+
+// Fused 融合排序后的结果
+type Fused struct {
+	ID    string  // 文档ID
+	Score float64 // RRF 得分
+	Ranks []int   // 在每个输入列表中的名次（从1开始，0 表示未出现）
+}
+
+// FuseRRF 使用倒数排名融合（Reciprocal Rank Fusion）合并多路检索结果
+// 每个列表按相关度从高到低排列，得分为 Σ 1/(k+rank)
+func FuseRRF(k int, lists ...[]string) []Fused {
+	if k <= 0 {
+		k = DefaultRRFK
+	}
+
+	fused := make(map[string]*Fused)
+	var order []string
+	for li, list := range lists {
+		for i, id := range list {
+			f, ok := fused[id]
+			if !ok {
+				f = &Fused{ID: id, Ranks: make([]int, len(lists))}
+				fused[id] = f
+				order = append(order, id)
+			}
+			// 同一列表中重复出现时只取最高名次
+			if f.Ranks[li] != 0 {
+				continue
+			}
+			f.Ranks[li] = i + 1
+			f.Score += 1 / float64(k+i+1)
+		}
+	}
+
+	res := make([]Fused, 0, len(order))
+	for _, id := range order {
+		res = append(res, *fused[id])
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Score > res[j].Score
+	})
+	return res
+}
+
+// Snippet 截取文本中与查询最相关的片段，width 为最大字符数
+// 优先以第一个命中的查询词为中心截取，没有命中时返回开头部分
+func Snippet(text, query string, width int) string {
+	runes := []rune(strings.TrimSpace(text))
+	if width <= 0 || len(runes) <= width {
+		return string(runes)
+	}
+
+	pos := -1
+	lower := strings.ToLower(string(runes))
+	for _, t := range Tokenize(query) {
+		if i := strings.Index(lower, t); i >= 0 {
+			pos = len([]rune(lower[:i]))
+			break
+		}
+	}
+
+	start := 0
+	if pos > 0 {
+		start = pos - width/4
+		if start < 0 {
+			start = 0
+		}
+	}
+	end := start + width
+	if end > len(runes) {
+		end = len(runes)
+		start = end - width
+	}
+
+	snippet := string(runes[start:end])
+	if start > 0 {
+		snippet = "..." + snippet
+	}
+	if end < len(runes) {
+		snippet += "..."
+	}
+	return snippet
+}
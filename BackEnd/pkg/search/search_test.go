@@ -0,0 +1,60 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Test_Tokenize 测试中英文混合分词
+func Test_Tokenize(t *testing.T) {
+	got := Tokenize("陪产假申请表 Form-12，请假")
+	want := []string{"陪产", "产假", "假申", "申请", "请表", "form", "12", "请假"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Tokenize() = %v, want %v", got, want)
+	}
+}
+
+// Test_Index_Search 测试 BM25 检索能命中精确词汇
+func Test_Index_Search(t *testing.T) {
+	idx := NewIndex()
+	idx.Add("1", "员工请假需提前三天在系统中提交申请")
+	idx.Add("2", "陪产假为十五天，需提供出生证明")
+	idx.Add("3", "年假按工龄计算，满一年享受五天")
+
+	hits := idx.Search("陪产假有几天", 2)
+	if len(hits) == 0 || hits[0].ID != "2" {
+		t.Fatalf("Search() = %v, want first hit 2", hits)
+	}
+
+	idx.Remove("2")
+	for _, h := range idx.Search("陪产假", 3) {
+		if h.ID == "2" {
+			t.Fatalf("removed document still returned: %v", h)
+		}
+	}
+}
+
+// Test_Index_IDs 测试列出索引中的文档ID
+func Test_Index_IDs(t *testing.T) {
+	idx := NewIndex()
+	idx.Add("2", "陪产假为十五天")
+	idx.Add("10", "年假按工龄计算")
+	idx.Add("1", "员工请假需提前三天")
+	idx.Add("2", "陪产假为十五天，需提供出生证明")
+	idx.Remove("1")
+
+	if got, want := idx.IDs(), []string{"10", "2"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("IDs() = %v, want %v", got, want)
+	}
+}
+
+// Test_FuseRRF 测试倒数排名融合
+func Test_FuseRRF(t *testing.T) {
+	res := FuseRRF(60, []string{"a", "b", "c"}, []string{"c", "a"})
+	if res[0].ID != "a" || res[1].ID != "c" || res[2].ID != "b" {
+		t.Fatalf("FuseRRF() order = %v", res)
+	}
+	if !reflect.DeepEqual(res[1].Ranks, []int{3, 1}) {
+		t.Fatalf("FuseRRF() ranks = %v", res[1].Ranks)
+	}
+}
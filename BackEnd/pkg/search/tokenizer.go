@@ -0,0 +1,55 @@
+// Package search 提供轻量的关键词检索能力（中文分词、BM25 倒排索引、RRF 融合排序）
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Tokenize 对文本进行分词，兼顾中文与英文
+// 英文与数字按连续字符切分并转小写；中文按连续汉字切分为二元组（bigram），
+// 单个汉字的片段保留为单字。二元组可以覆盖"陪产假"这类专有词汇，而无需引入词典
+func Tokenize(text string) []string {
+	var (
+		tokens []string
+		word   []rune // 当前英文/数字片段
+		han    []rune // 当前汉字片段
+	)
+
+	flushWord := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+	flushHan := func() {
+		switch len(han) {
+		case 0:
+		case 1:
+			tokens = append(tokens, string(han))
+		default:
+			for i := 0; i+1 < len(han); i++ {
+				tokens = append(tokens, string(han[i:i+2]))
+			}
+		}
+		han = han[:0]
+	}
+
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flushWord()
+			han = append(han, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushHan()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushHan()
+		}
+	}
+	flushWord()
+	flushHan()
+
+	return tokens
+}
@@ -0,0 +1,70 @@
+// Package seqno 按格式生成带日期和流水号的单号，如 QJ-20261017-0001
+//
+// 格式中支持的占位符：
+//
+//	{year}   年，2006
+//	{month}  年月，200601
+//	{date}   年月日，20060102
+//	{seq}    流水号，{seq:4} 表示不足 4 位时前面补 0
+//
+// 流水号按 Key 计数，Key 为去掉流水号后的单号，格式中包含日期时流水号每天从 1 开始。
+package seqno
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var seqPattern = regexp.MustCompile(`\{seq(?::(\d+))?\}`)
+
+// Format 单号格式
+type Format struct {
+	pattern string
+	width   int
+}
+
+// Parse 解析单号格式，格式中必须有且只有一个流水号占位符
+func Parse(pattern string) (*Format, error) {
+	matches := seqPattern.FindAllStringSubmatch(pattern, -1)
+	if len(matches) != 1 {
+		return nil, fmt.Errorf("format %q must contain exactly one {seq} placeholder", pattern)
+	}
+	f := &Format{pattern: pattern}
+	if matches[0][1] != "" {
+		width, err := strconv.Atoi(matches[0][1])
+		if err != nil || width > 18 {
+			return nil, fmt.Errorf("invalid sequence width in format %q", pattern)
+		}
+		f.width = width
+	}
+	if rest := seqPattern.ReplaceAllString(pattern, ""); strings.ContainsAny(expandDate(rest, time.Time{}), "{}") {
+		return nil, fmt.Errorf("unknown placeholder in format %q", pattern)
+	}
+	return f, nil
+}
+
+// Key 返回 t 时刻的流水号计数键
+func (f *Format) Key(t time.Time) string {
+	return expandDate(seqPattern.ReplaceAllString(f.pattern, ""), t)
+}
+
+// Render 生成 t 时刻、流水号为 seq 的单号
+func (f *Format) Render(t time.Time, seq int64) (string, error) {
+	if seq <= 0 {
+		return "", errors.New("sequence must be positive")
+	}
+	s := fmt.Sprintf("%0*d", f.width, seq)
+	return expandDate(seqPattern.ReplaceAllLiteralString(f.pattern, s), t), nil
+}
+
+func expandDate(s string, t time.Time) string {
+	return strings.NewReplacer(
+		"{year}", t.Format("2006"),
+		"{month}", t.Format("200601"),
+		"{date}", t.Format("20060102"),
+	).Replace(s)
+}
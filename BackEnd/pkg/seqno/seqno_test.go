@@ -0,0 +1,41 @@
+package seqno
+
+import (
+	"testing"
+	"time"
+)
+
+// Test_Render 测试按格式生成单号和流水号计数键
+func Test_Render(t *testing.T) {
+	at := time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)
+	tests := []struct {
+		pattern string
+		seq     int64
+		key     string
+		want    string
+	}{
+		{"QJ-{date}-{seq:4}", 1, "QJ-20261017-", "QJ-20261017-0001"},
+		{"WC{month}{seq:3}", 12, "WC202610", "WC202610012"},
+		{"BK-{year}-{seq}", 12345, "BK-2026-", "BK-2026-12345"},
+		{"SP-{date}-{seq:2}", 123, "SP-20261017-", "SP-20261017-123"},
+	}
+	for _, tt := range tests {
+		f, err := Parse(tt.pattern)
+		if err != nil {
+			t.Fatalf("Parse(%q) error: %v", tt.pattern, err)
+		}
+		if got := f.Key(at); got != tt.key {
+			t.Errorf("Key(%q) = %q, want %q", tt.pattern, got, tt.key)
+		}
+		got, err := f.Render(at, tt.seq)
+		if err != nil || got != tt.want {
+			t.Errorf("Render(%q, %d) = %q, %v, want %q", tt.pattern, tt.seq, got, err, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"QJ-{date}", "{seq}-{seq}", "QJ-{day}-{seq}", "{seq:x}"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("Parse(%q) expected error", bad)
+		}
+	}
+}
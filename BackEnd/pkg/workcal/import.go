@@ -0,0 +1,140 @@
+package workcal
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// kindNames 导入文件中日期类型的写法
+var kindNames = map[string]DayKind{
+	"holiday": Holiday, "休": Holiday, "放假": Holiday, "1": Holiday,
+	"workday": Workday, "班": Workday, "上班": Workday, "调休上班": Workday, "2": Workday,
+}
+
+// ParseKind 解析日期类型
+func ParseKind(s string) (DayKind, error) {
+	if k, ok := kindNames[strings.ToLower(strings.TrimSpace(s))]; ok {
+		return k, nil
+	}
+	return 0, fmt.Errorf("unknown day kind %q", s)
+}
+
+// jsonDay JSON 导入格式，kind 可以是数字或 holiday、workday 等名称
+type jsonDay struct {
+	Date string          `json:"date"`
+	Kind json.RawMessage `json:"kind"`
+	Name string          `json:"name"`
+}
+
+// ParseDays 解析节假日文件，format 为 csv 或 json
+//
+// CSV 每行为 日期,类型,名称，日期可以是 2026-10-01 或 2026-10-01~2026-10-07，# 开头的行为注释：
+//
+//	2026-10-01~2026-10-07,holiday,国庆节
+//	2026-10-10,workday,国庆节调休
+//
+// JSON 为数组：[{"date":"2026-10-01~2026-10-07","kind":"holiday","name":"国庆节"}]
+func ParseDays(r io.Reader, format string) ([]Day, error) {
+	switch strings.ToLower(strings.TrimPrefix(format, ".")) {
+	case "csv", "txt":
+		return parseCSV(r)
+	case "json":
+		return parseJSON(r)
+	}
+	return nil, fmt.Errorf("unsupported calendar format %q", format)
+}
+
+func parseCSV(r io.Reader) ([]Day, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var days []Day
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) < 2 {
+			return nil, fmt.Errorf("line %d: expected date,kind[,name]", line)
+		}
+		kind, err := ParseKind(record[1])
+		if err != nil {
+			// 跳过表头
+			if line == 1 {
+				continue
+			}
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		name := ""
+		if len(record) > 2 {
+			name = strings.TrimSpace(record[2])
+		}
+		expanded, err := expandDates(record[0], kind, name)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		days = append(days, expanded...)
+	}
+	return days, nil
+}
+
+func parseJSON(r io.Reader) ([]Day, error) {
+	var items []jsonDay
+	if err := json.NewDecoder(r).Decode(&items); err != nil {
+		return nil, err
+	}
+	var days []Day
+	for i, item := range items {
+		var (
+			kind DayKind
+			name string
+			num  int
+		)
+		if err := json.Unmarshal(item.Kind, &num); err == nil {
+			kind = DayKind(num)
+		} else if err := json.Unmarshal(item.Kind, &name); err == nil {
+			if kind, err = ParseKind(name); err != nil {
+				return nil, fmt.Errorf("item %d: %w", i, err)
+			}
+		}
+		if kind != Holiday && kind != Workday {
+			return nil, fmt.Errorf("item %d: invalid day kind", i)
+		}
+		expanded, err := expandDates(item.Date, kind, item.Name)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+		days = append(days, expanded...)
+	}
+	return days, nil
+}
+
+// expandDates 将 2026-10-01~2026-10-07 展开为每一天
+func expandDates(spec string, kind DayKind, name string) ([]Day, error) {
+	from, to, isRange := strings.Cut(strings.TrimSpace(spec), "~")
+	start, err := time.Parse(time.DateOnly, strings.TrimSpace(from))
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q", spec)
+	}
+	end := start
+	if isRange {
+		if end, err = time.Parse(time.DateOnly, strings.TrimSpace(to)); err != nil || end.Before(start) {
+			return nil, fmt.Errorf("invalid date range %q", spec)
+		}
+	}
+	var days []Day
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		days = append(days, Day{Date: d.Format(time.DateOnly), Kind: kind, Name: name})
+	}
+	return days, nil
+}
@@ -0,0 +1,247 @@
+// Package workcal 工作日历：工作时段、周末、法定节假日和调休上班，用于计算请假、外出的实际工作时长
+package workcal
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DayKind 日历中特殊日期的类型
+type DayKind int
+
+const (
+	Holiday DayKind = 1 // 放假，包括落在工作日的法定节假日
+	Workday DayKind = 2 // 调休上班，包括落在周末的补班日
+)
+
+// Day 特殊日期，Date 格式为 2006-01-02
+type Day struct {
+	Date string  `json:"date"`
+	Kind DayKind `json:"kind"`
+	Name string  `json:"name,omitempty"`
+}
+
+// Period 每天的工作时段，Start、End 为距离零点的时长
+type Period struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// DefaultPeriods 默认工作时段 09:00-12:00、13:00-18:00
+var DefaultPeriods = []Period{
+	{Start: 9 * time.Hour, End: 12 * time.Hour},
+	{Start: 13 * time.Hour, End: 18 * time.Hour},
+}
+
+// DefaultWeekend 默认周末
+var DefaultWeekend = []time.Weekday{time.Saturday, time.Sunday}
+
+// Calendar 工作日历
+type Calendar struct {
+	loc     *time.Location
+	periods []Period
+	weekend map[time.Weekday]bool
+	days    map[string]Day
+}
+
+// New 创建工作日历，periods、weekend 为空时使用默认值
+func New(loc *time.Location, periods []Period, weekend []time.Weekday, days []Day) *Calendar {
+	if loc == nil {
+		loc = time.Local
+	}
+	if len(periods) == 0 {
+		periods = DefaultPeriods
+	}
+	if weekend == nil {
+		weekend = DefaultWeekend
+	}
+	c := &Calendar{
+		loc:     loc,
+		periods: append([]Period(nil), periods...),
+		weekend: make(map[time.Weekday]bool, len(weekend)),
+		days:    make(map[string]Day, len(days)),
+	}
+	sort.Slice(c.periods, func(i, j int) bool { return c.periods[i].Start < c.periods[j].Start })
+	for _, w := range weekend {
+		c.weekend[w] = true
+	}
+	for _, d := range days {
+		c.days[d.Date] = d
+	}
+	return c
+}
+
+// Location 日历使用的时区
+func (c *Calendar) Location() *time.Location {
+	return c.loc
+}
+
+// ParsePeriods 解析 09:00-12:00 格式的工作时段
+func ParsePeriods(specs []string) ([]Period, error) {
+	periods := make([]Period, 0, len(specs))
+	for _, spec := range specs {
+		from, to, ok := strings.Cut(spec, "-")
+		if !ok {
+			return nil, fmt.Errorf("invalid work period %q", spec)
+		}
+		start, err1 := parseClock(from)
+		end, err2 := parseClock(to)
+		if err1 != nil || err2 != nil || end <= start {
+			return nil, fmt.Errorf("invalid work period %q", spec)
+		}
+		periods = append(periods, Period{Start: start, End: end})
+	}
+	return periods, nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// HoursPerDay 每个工作日的工作小时数
+func (c *Calendar) HoursPerDay() float64 {
+	var d time.Duration
+	for _, p := range c.periods {
+		d += p.End - p.Start
+	}
+	return d.Hours()
+}
+
+// IsWorkday 判断 t 所在的日期是否需要上班
+func (c *Calendar) IsWorkday(t time.Time) bool {
+	t = t.In(c.loc)
+	if d, ok := c.days[t.Format(time.DateOnly)]; ok {
+		return d.Kind == Workday
+	}
+	return !c.weekend[t.Weekday()]
+}
+
+// dayStart t 所在日期的零点
+func (c *Calendar) dayStart(t time.Time) time.Time {
+	t = t.In(c.loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, c.loc)
+}
+
+// overlap 返回 [start, end) 与某天工作时段重叠的时长
+func (c *Calendar) overlap(day, start, end time.Time, periods []Period) time.Duration {
+	var total time.Duration
+	for _, p := range periods {
+		s, e := day.Add(p.Start), day.Add(p.End)
+		if s.Before(start) {
+			s = start
+		}
+		if e.After(end) {
+			e = end
+		}
+		if e.After(s) {
+			total += e.Sub(s)
+		}
+	}
+	return total
+}
+
+// eachWorkday 依次处理 [start, end) 覆盖的工作日
+func (c *Calendar) eachWorkday(start, end time.Time, fn func(day time.Time)) {
+	for day := c.dayStart(start); day.Before(end); day = day.AddDate(0, 0, 1) {
+		if c.IsWorkday(day) {
+			fn(day)
+		}
+	}
+}
+
+// WorkHours 计算 [start, end) 内的工作小时数，不足半小时按半小时计
+func (c *Calendar) WorkHours(start, end time.Time) float64 {
+	if !end.After(start) {
+		return 0
+	}
+	var total time.Duration
+	c.eachWorkday(start, end, func(day time.Time) {
+		total += c.overlap(day, start, end, c.periods)
+	})
+	return roundUpHalf(total.Hours())
+}
+
+// WorkDays 计算 [start, end) 内的工作天数，按上、下半天计算，请假时间涉及某个半天即计为半天
+func (c *Calendar) WorkDays(start, end time.Time) float64 {
+	if !end.After(start) {
+		return 0
+	}
+	morning, afternoon := c.halves()
+	var total float64
+	c.eachWorkday(start, end, func(day time.Time) {
+		if c.overlap(day, start, end, morning) > 0 {
+			total += 0.5
+		}
+		if c.overlap(day, start, end, afternoon) > 0 {
+			total += 0.5
+		}
+	})
+	return total
+}
+
+// halves 将工作时段拆分为上半天和下半天
+// 有多个工作时段时第一个时段为上半天，其余为下半天；只有一个时段时从中间拆分
+func (c *Calendar) halves() (morning, afternoon []Period) {
+	if len(c.periods) > 1 {
+		return c.periods[:1], c.periods[1:]
+	}
+	p := c.periods[0]
+	mid := (p.Start + p.End) / 2
+	return []Period{{Start: p.Start, End: mid}}, []Period{{Start: mid, End: p.End}}
+}
+
+// HalfDay 返回 t 所在日期上半天或下半天的起止时间
+func (c *Calendar) HalfDay(t time.Time, afternoon bool) (start, end time.Time) {
+	day := c.dayStart(t)
+	half, pm := c.halves()
+	if afternoon {
+		half = pm
+	}
+	return day.Add(half[0].Start), day.Add(half[len(half)-1].End)
+}
+
+// Range 连续的节假日
+type Range struct {
+	Name  string
+	Start time.Time
+	End   time.Time // 最后一天（含）
+}
+
+// Holidays 将放假日期按名称合并为连续的节假日
+func (c *Calendar) Holidays() []Range {
+	var days []Day
+	for _, d := range c.days {
+		if d.Kind == Holiday {
+			days = append(days, d)
+		}
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Date < days[j].Date })
+
+	var ranges []Range
+	for _, d := range days {
+		t, err := time.ParseInLocation(time.DateOnly, d.Date, c.loc)
+		if err != nil {
+			continue
+		}
+		if n := len(ranges); n > 0 && ranges[n-1].Name == d.Name && ranges[n-1].End.AddDate(0, 0, 1).Equal(t) {
+			ranges[n-1].End = t
+			continue
+		}
+		ranges = append(ranges, Range{Name: d.Name, Start: t, End: t})
+	}
+	return ranges
+}
+
+// roundUpHalf 向上取整到 0.5
+func roundUpHalf(v float64) float64 {
+	// 先保留两位小数，避免浮点误差导致多算半个单位
+	v = math.Round(v*100) / 100
+	return math.Ceil(v*2) / 2
+}
@@ -0,0 +1,124 @@
+package workcal
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+var cst = time.FixedZone("CST", 8*3600)
+
+func at(month time.Month, day, hour, minute int) time.Time {
+	return time.Date(2026, month, day, hour, minute, 0, 0, cst)
+}
+
+// 2026 国庆节 10-01 至 10-07 放假，10-10（周六）调休上班
+func newTestCalendar(t *testing.T) *Calendar {
+	days, err := ParseDays(strings.NewReader("2026-10-01~2026-10-07,holiday,国庆节\n2026-10-10,workday,国庆节调休\n"), "csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(cst, nil, nil, days)
+}
+
+// Test_WorkHours 测试按工作时段计算小时数，跳过午休、夜间、周末和节假日
+func Test_WorkHours(t *testing.T) {
+	c := newTestCalendar(t)
+	tests := []struct {
+		name       string
+		start, end time.Time
+		want       float64
+	}{
+		{"同一时段内", at(10, 9, 9, 0), at(10, 9, 11, 0), 2},
+		{"跨午休", at(10, 9, 10, 0), at(10, 9, 15, 0), 4},
+		{"不足半小时按半小时", at(10, 9, 10, 0), at(10, 9, 11, 20), 1.5},
+		{"跨夜", at(10, 8, 17, 0), at(10, 9, 10, 0), 2},
+		{"包含调休上班的周六", at(10, 9, 9, 0), at(10, 12, 18, 0), 24},
+		{"周末", at(10, 17, 9, 0), at(10, 18, 18, 0), 0},
+		{"节假日", at(10, 1, 0, 0), at(10, 8, 0, 0), 0},
+		{"结束早于开始", at(10, 9, 11, 0), at(10, 9, 9, 0), 0},
+	}
+	for _, tt := range tests {
+		if got := c.WorkHours(tt.start, tt.end); got != tt.want {
+			t.Errorf("%s: WorkHours() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// Test_WorkDays 测试按天计算时长，涉及的每个半天计为 0.5 天
+func Test_WorkDays(t *testing.T) {
+	c := newTestCalendar(t)
+	tests := []struct {
+		name       string
+		start, end time.Time
+		want       float64
+	}{
+		{"上半天", at(10, 9, 9, 0), at(10, 9, 12, 0), 0.5},
+		{"下半天", at(10, 9, 13, 0), at(10, 9, 18, 0), 0.5},
+		{"不足半天按半天", at(10, 9, 10, 0), at(10, 9, 11, 0), 0.5},
+		{"跨午休", at(10, 9, 11, 0), at(10, 9, 14, 0), 1},
+		{"整天", at(10, 9, 0, 0), at(10, 10, 0, 0), 1},
+		{"跨调休周末", at(10, 9, 14, 0), at(10, 12, 12, 0), 2},
+		{"整周含周末", at(10, 12, 0, 0), at(10, 19, 0, 0), 5},
+		{"国庆假期", at(10, 1, 0, 0), at(10, 8, 0, 0), 0},
+	}
+	for _, tt := range tests {
+		if got := c.WorkDays(tt.start, tt.end); got != tt.want {
+			t.Errorf("%s: WorkDays() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// Test_HalfDay 测试上下半天的起止时间
+func Test_HalfDay(t *testing.T) {
+	c := newTestCalendar(t)
+	if s, e := c.HalfDay(at(10, 9, 15, 0), false); !s.Equal(at(10, 9, 9, 0)) || !e.Equal(at(10, 9, 12, 0)) {
+		t.Errorf("morning = %v - %v", s, e)
+	}
+	if s, e := c.HalfDay(at(10, 9, 8, 0), true); !s.Equal(at(10, 9, 13, 0)) || !e.Equal(at(10, 9, 18, 0)) {
+		t.Errorf("afternoon = %v - %v", s, e)
+	}
+
+	// 只有一个时段时从中间拆分
+	periods, err := ParsePeriods([]string{"08:00-16:00"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	single := New(cst, periods, nil, nil)
+	if s, e := single.HalfDay(at(10, 9, 8, 0), true); !s.Equal(at(10, 9, 12, 0)) || !e.Equal(at(10, 9, 16, 0)) {
+		t.Errorf("single period afternoon = %v - %v", s, e)
+	}
+	if got := single.HoursPerDay(); got != 8 {
+		t.Errorf("HoursPerDay() = %v, want 8", got)
+	}
+}
+
+// Test_ParseDays 测试 CSV、JSON 节假日文件的导入
+func Test_ParseDays(t *testing.T) {
+	csvDays, err := ParseDays(strings.NewReader("date,kind,name\n# 2026\n2026-01-01~2026-01-03,放假,元旦\n2026-01-04,班,元旦调休\n"), "csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(csvDays) != 4 || csvDays[2].Date != "2026-01-03" || csvDays[3].Kind != Workday {
+		t.Errorf("csv days = %+v", csvDays)
+	}
+
+	jsonDays, err := ParseDays(strings.NewReader(`[{"date":"2026-10-01~2026-10-07","kind":"holiday","name":"国庆节"},{"date":"2026-10-10","kind":2}]`), ".json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jsonDays) != 8 || jsonDays[7].Kind != Workday {
+		t.Errorf("json days = %+v", jsonDays)
+	}
+
+	for _, bad := range []string{"2026-10-01,unknown\n", "2026-10-07~2026-10-01,holiday\n", "20261001,holiday\n"} {
+		if _, err := ParseDays(strings.NewReader("2026-10-01,holiday\n"+bad), "csv"); err == nil {
+			t.Errorf("ParseDays(%q) expected error", bad)
+		}
+	}
+
+	ranges := New(cst, nil, nil, jsonDays).Holidays()
+	if len(ranges) != 1 || ranges[0].Name != "国庆节" || !ranges[0].End.Equal(time.Date(2026, 10, 7, 0, 0, 0, 0, cst)) {
+		t.Errorf("Holidays() = %+v", ranges)
+	}
+}